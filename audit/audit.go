@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Operation is the kind of write a Record describes
+type Operation string
+
+const (
+	Create  Operation = "create"
+	Update  Operation = "update"
+	Delete  Operation = "delete"
+	Restore Operation = "restore"
+	Purge   Operation = "purge"
+	Revert  Operation = "revert"
+)
+
+// RequestIDHeader is the header carrying the id of a request, it is generated when the client does not send one
+const RequestIDHeader = "X-Request-ID"
+
+// Redacted replaces the values of the fields tagged `sensitive:"true"`
+const Redacted = "[REDACTED]"
+
+// Record is the trail of one write request: who changed what, and how
+type Record struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+	// User is the identifier of the authenticated user, empty for anonymous requests
+	User      string    `json:"user,omitempty"`
+	Operation Operation `json:"operation"`
+	Batch     bool      `json:"batch,omitempty"`
+	Resource  string    `json:"resource"`
+	IDs       []string  `json:"ids"`
+	Changes   []Change  `json:"changes,omitempty"`
+}
+
+// Change is the modification of one field of one entity
+// Before is nil for created entities, After is nil for deleted ones
+type Change struct {
+	ID     string `json:"id"`
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// Sink receives the records of every audited write
+type Sink interface {
+	Write(record Record) error
+}
+
+// SinkFunc is a function implementing Sink
+type SinkFunc func(record Record) error
+
+// Write calls the function.
+func (f SinkFunc) Write(record Record) error {
+	return f(record)
+}
+
+// MultiSink writes every record to all of its sinks
+type MultiSink []Sink
+
+// Write writes the record to every sink, the first error is returned once all of them were tried.
+func (m MultiSink) Write(record Record) error {
+	var first error
+	for _, sink := range m {
+		if err := sink.Write(record); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// NewRequestID returns a random request id.
+func NewRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Diff returns the field level changes between two states of the entity identified by id.
+// before is nil for created entities and after for deleted ones, only their set fields are then listed.
+// Fields are named by their json tag, embedded structs are flattened.
+// Values of the fields tagged `sensitive:"true"`, and of those hidden from the serializer by `json:"-"`, are Redacted.
+func Diff(id string, before, after any) []Change {
+	beforeValue, afterValue := structValue(before), structValue(after)
+	var structType reflect.Type
+	switch {
+	case beforeValue.IsValid():
+		structType = beforeValue.Type()
+	case afterValue.IsValid():
+		structType = afterValue.Type()
+	default:
+		return nil
+	}
+	if beforeValue.IsValid() && afterValue.IsValid() && beforeValue.Type() != afterValue.Type() {
+		return nil
+	}
+
+	var changes []Change
+	walkFields(structType, nil, func(name string, index []int, sensitive bool) {
+		var old, new_ reflect.Value
+		if beforeValue.IsValid() {
+			old = beforeValue.FieldByIndex(index)
+		}
+		if afterValue.IsValid() {
+			new_ = afterValue.FieldByIndex(index)
+		}
+		switch {
+		case old.IsValid() && new_.IsValid():
+			if reflect.DeepEqual(old.Interface(), new_.Interface()) {
+				return
+			}
+		case old.IsValid():
+			if old.IsZero() {
+				return
+			}
+		case new_.IsZero():
+			return
+		}
+		changes = append(changes, Change{ID: id, Field: name, Before: fieldValue(old, sensitive), After: fieldValue(new_, sensitive)})
+	})
+	return changes
+}
+
+// structValue dereferences v down to a struct, the zero Value is returned for nil and non struct values.
+func structValue(v any) reflect.Value {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return value
+}
+
+// walkFields calls fn with the name and index of every exported field of structType, embedded structs included.
+func walkFields(structType reflect.Type, parent []int, fn func(name string, index []int, sensitive bool)) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		index := append(parent[:len(parent):len(parent)], i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			walkFields(field.Type, index, fn)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		//like encoding/json, "-," names a field "-"
+		hidden := field.Tag.Get("json") == "-"
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && !hidden {
+			name = tag
+		}
+		fn(name, index, hidden || field.Tag.Get("sensitive") == "true")
+	}
+}
+
+func fieldValue(value reflect.Value, sensitive bool) any {
+	if !value.IsValid() {
+		return nil
+	}
+	if sensitive {
+		return Redacted
+	}
+	return value.Interface()
+}
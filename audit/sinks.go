@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// SlogSink logs every record as an "audit" message of its Logger
+type SlogSink struct {
+	Logger *slog.Logger
+	Level  slog.Level
+}
+
+// NewSlogSink creates a sink logging records at info level, with slog.Default when logger is nil.
+func NewSlogSink(logger *slog.Logger) *SlogSink {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogSink{Logger: logger, Level: slog.LevelInfo}
+}
+
+// Write logs the record.
+func (s *SlogSink) Write(record Record) error {
+	s.Logger.LogAttrs(context.Background(), s.Level, "audit",
+		slog.Time("time", record.Time),
+		slog.String("request_id", record.RequestID),
+		slog.String("user", record.User),
+		slog.String("operation", string(record.Operation)),
+		slog.Bool("batch", record.Batch),
+		slog.String("resource", record.Resource),
+		slog.Any("ids", record.IDs),
+		slog.Any("changes", record.Changes),
+	)
+	return nil
+}
+
+// FileSink appends every record to a file, one JSON document per line
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens path for appending, creating it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write appends the record.
+func (f *FileSink) Write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = f.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// Entry is a Record stored by a repository, ids are comma separated and changes are JSON encoded
+type Entry struct {
+	Id        entity.ID `json:"id" orm:"primaryKey"`
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id"`
+	User      string    `json:"user"`
+	Operation string    `json:"operation"`
+	Batch     bool      `json:"batch"`
+	Resource  string    `json:"resource"`
+	IDs       string    `json:"ids"`
+	Changes   string    `json:"changes"`
+}
+
+// TableName keeps entries apart from the tables of the application.
+func (e Entry) TableName() string {
+	return "audit_entries"
+}
+
+// GetIdentifier returns the id of the entry.
+func (e Entry) GetIdentifier() entity.Identifier {
+	return e.Id
+}
+
+// SetId sets the id of the entry.
+func (e Entry) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+
+// ToEntity returns the entry, it is its own database model.
+func (e Entry) ToEntity() Entry {
+	return e
+}
+
+// FromEntity returns the entry, it is its own database model.
+func (e Entry) FromEntity(entry Entry) any {
+	return entry
+}
+
+// Record decodes the entry.
+func (e Entry) Record() (Record, error) {
+	record := Record{
+		Time:      e.Time,
+		RequestID: e.RequestID,
+		User:      e.User,
+		Operation: Operation(e.Operation),
+		Batch:     e.Batch,
+		Resource:  e.Resource,
+	}
+	if e.IDs != "" {
+		record.IDs = strings.Split(e.IDs, ",")
+	}
+	if e.Changes != "" {
+		if err := json.Unmarshal([]byte(e.Changes), &record.Changes); err != nil {
+			return record, err
+		}
+	}
+	return record, nil
+}
+
+// ORMSink stores every record as an Entry of a repository, such as a table of the audited database
+type ORMSink struct {
+	ORM *orm.ORM[Entry]
+}
+
+// NewORMSink creates a sink storing records with o.
+//
+// Example:
+//
+//	db.AutoMigrate(&audit.Entry{})
+//	sink := audit.NewORMSink(orm.NewORM(gormrepository.NewRepository[audit.Entry](db)))
+func NewORMSink(o *orm.ORM[Entry]) *ORMSink {
+	return &ORMSink{ORM: o}
+}
+
+// Write stores the record.
+func (s *ORMSink) Write(record Record) error {
+	changes, err := json.Marshal(record.Changes)
+	if err != nil {
+		return err
+	}
+	return s.ORM.Create(&Entry{
+		Time:      record.Time,
+		RequestID: record.RequestID,
+		User:      record.User,
+		Operation: string(record.Operation),
+		Batch:     record.Batch,
+		Resource:  record.Resource,
+		IDs:       strings.Join(record.IDs, ","),
+		Changes:   string(changes),
+	})
+}
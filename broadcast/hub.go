@@ -0,0 +1,128 @@
+package broadcast
+
+import (
+	"sync"
+	"time"
+
+	"github.com/philiphil/restman/audit"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// Message is a change of an entity, numbered in the order of the hub
+type Message[T entity.Entity] struct {
+	ID        uint64
+	Time      time.Time
+	Operation audit.Operation
+	EntityID  string
+	// Tenant is the tenant of the write, empty without tenancy
+	Tenant string
+	// Object is the entity after the write, or before it when it was deleted
+	Object *T
+}
+
+// Hub fans out the changes of a resource to its subscribers, within a single process
+// It keeps the last messages in a bounded buffer, so that subscribers can resume after a disconnection.
+type Hub[T entity.Entity] struct {
+	// SubscriberBuffer is the number of messages a subscriber may lag behind, a slower subscriber is disconnected
+	SubscriberBuffer int
+
+	mu          sync.Mutex
+	size        int
+	buffer      []Message[T]
+	last        uint64
+	subscribers map[*Subscription[T]]struct{}
+}
+
+// Subscription receives the messages published after it was made, until it is closed
+type Subscription[T entity.Entity] struct {
+	hub    *Hub[T]
+	ch     chan Message[T]
+	closed bool
+}
+
+// NewHub creates a hub keeping the last size messages.
+func NewHub[T entity.Entity](size int) *Hub[T] {
+	return &Hub[T]{
+		SubscriberBuffer: 64,
+		size:             size,
+		subscribers:      map[*Subscription[T]]struct{}{},
+	}
+}
+
+// Publish numbers a message for every object and sends them to the subscribers.
+func (h *Hub[T]) Publish(operation audit.Operation, tenant string, objects ...*T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now().UTC()
+	for _, object := range objects {
+		copied := *object
+		h.last++
+		message := Message[T]{
+			ID:        h.last,
+			Time:      now,
+			Operation: operation,
+			EntityID:  (*object).GetIdentifier().String(),
+			Tenant:    tenant,
+			Object:    &copied,
+		}
+		h.buffer = append(h.buffer, message)
+		if len(h.buffer) > h.size {
+			h.buffer = h.buffer[len(h.buffer)-h.size:]
+		}
+		for subscription := range h.subscribers {
+			select {
+			case subscription.ch <- message:
+			default:
+				//it resumes from the buffer once reconnected
+				h.unsubscribe(subscription)
+			}
+		}
+	}
+}
+
+// Subscribe returns a subscription and the buffered messages published after lastID, to replay before the subscription ones.
+// lastID is 0 for a new subscriber. complete is false when some messages after lastID already left the buffer,
+// or when lastID was never published, an id of a previous process for instance.
+func (h *Hub[T]) Subscribe(lastID uint64) (subscription *Subscription[T], replay []Message[T], complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subscription = &Subscription[T]{hub: h, ch: make(chan Message[T], h.SubscriberBuffer)}
+	h.subscribers[subscription] = struct{}{}
+	if lastID > h.last {
+		return subscription, nil, false
+	}
+	complete = true
+	if lastID == 0 {
+		return subscription, nil, complete
+	}
+	if len(h.buffer) > 0 && h.buffer[0].ID > lastID+1 {
+		complete = false
+	}
+	for _, message := range h.buffer {
+		if message.ID > lastID {
+			replay = append(replay, message)
+		}
+	}
+	return subscription, replay, complete
+}
+
+// C returns the channel of the messages, it is closed when the subscription is closed or too slow.
+func (s *Subscription[T]) C() <-chan Message[T] {
+	return s.ch
+}
+
+// Close stops the subscription.
+func (s *Subscription[T]) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.unsubscribe(s)
+}
+
+func (h *Hub[T]) unsubscribe(subscription *Subscription[T]) {
+	if subscription.closed {
+		return
+	}
+	subscription.closed = true
+	delete(h.subscribers, subscription)
+	close(subscription.ch)
+}
@@ -0,0 +1,46 @@
+package cache
+
+// BroadcastResponseCache wraps a node-local ResponseCache so that its evictions are applied by every node.
+// Invalidate evicts locally then publishes on the bus, evictions received from other nodes are applied locally.
+type BroadcastResponseCache struct {
+	Local  ResponseCache
+	Bus    InvalidationBus
+	NodeId string
+}
+
+// NewBroadcastResponseCache creates a ResponseCache sharing its evictions through bus.
+func NewBroadcastResponseCache(local ResponseCache, bus InvalidationBus) (*BroadcastResponseCache, error) {
+	c := &BroadcastResponseCache{
+		Local:  local,
+		Bus:    bus,
+		NodeId: NewNodeId(),
+	}
+	err := bus.Subscribe(func(invalidation Invalidation) {
+		if invalidation.Origin == c.NodeId {
+			return
+		}
+		_ = c.Local.Invalidate(invalidation.Tags...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Get retrieves a response from the local cache.
+func (c *BroadcastResponseCache) Get(key string) ([]byte, bool) {
+	return c.Local.Get(key)
+}
+
+// Set stores a response in the local cache.
+func (c *BroadcastResponseCache) Set(key string, data []byte, tags ...string) error {
+	return c.Local.Set(key, data, tags...)
+}
+
+// Invalidate evicts the tags locally and broadcasts the eviction to the other nodes.
+func (c *BroadcastResponseCache) Invalidate(tags ...string) error {
+	if err := c.Local.Invalidate(tags...); err != nil {
+		return err
+	}
+	return c.Bus.Publish(Invalidation{Origin: c.NodeId, Tags: tags})
+}
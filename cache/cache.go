@@ -45,7 +45,7 @@ func NewRedisCache[E entity.Entity](addr, password string, db int, lifetime int)
 }
 
 func (r *RedisCache[E]) generateCacheKey(ent entity.Entity) string {
-	return fmt.Sprintf("%s:%s", r.entityPrefix, ent.GetId().String())
+	return fmt.Sprintf("%s:%s", r.entityPrefix, ent.GetIdentifier().String())
 }
 
 // Set stores an entity in the Redis cache.
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// Invalidation is a cache eviction broadcast to every node.
+// Origin identifies the node that published it so that it can skip its own evictions.
+type Invalidation struct {
+	Origin string   `json:"origin"`
+	Tags   []string `json:"tags"`
+}
+
+// InvalidationHandler is called for every Invalidation received from an InvalidationBus.
+type InvalidationHandler func(Invalidation)
+
+// InvalidationBus broadcasts cache evictions between the instances of an application,
+// so that in-process caches do not keep serving entries that were written on another node.
+type InvalidationBus interface {
+	Publish(invalidation Invalidation) error
+	Subscribe(handler InvalidationHandler) error
+	Close() error
+}
+
+// NewNodeId returns a random identifier to be used as Invalidation.Origin.
+func NewNodeId() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// InProcessInvalidationBus is an InvalidationBus delivering evictions synchronously to the handlers of the current process.
+// It is meant for tests and for applications running several routers on the same cache within one process.
+type InProcessInvalidationBus struct {
+	mu       sync.RWMutex
+	handlers []InvalidationHandler
+}
+
+// NewInProcessInvalidationBus creates a new in-process invalidation bus.
+func NewInProcessInvalidationBus() *InProcessInvalidationBus {
+	return &InProcessInvalidationBus{}
+}
+
+// Publish delivers the invalidation to every subscribed handler.
+func (b *InProcessInvalidationBus) Publish(invalidation Invalidation) error {
+	b.mu.RLock()
+	handlers := make([]InvalidationHandler, len(b.handlers))
+	copy(handlers, b.handlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(invalidation)
+	}
+	return nil
+}
+
+// Subscribe registers a handler for every future invalidation.
+func (b *InProcessInvalidationBus) Subscribe(handler InvalidationHandler) error {
+	b.mu.Lock()
+	b.handlers = append(b.handlers, handler)
+	b.mu.Unlock()
+	return nil
+}
+
+// Close unregisters every handler.
+func (b *InProcessInvalidationBus) Close() error {
+	b.mu.Lock()
+	b.handlers = nil
+	b.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryResponse struct {
+	data    []byte
+	tags    []string
+	expires time.Time
+}
+
+// MemoryResponseCache is an in-process implementation of the ResponseCache interface.
+// It is meant for single instance deployments and tests.
+type MemoryResponseCache struct {
+	mu        sync.RWMutex
+	responses map[string]memoryResponse
+	tags      map[string]map[string]struct{}
+	lifetime  time.Duration
+}
+
+// NewMemoryResponseCache creates a new in-memory response cache, lifetime is in seconds (0 means no expiration).
+func NewMemoryResponseCache(lifetime int) *MemoryResponseCache {
+	return &MemoryResponseCache{
+		responses: make(map[string]memoryResponse),
+		tags:      make(map[string]map[string]struct{}),
+		lifetime:  time.Duration(lifetime) * time.Second,
+	}
+}
+
+// Get returns the cached response stored under key, if any and not expired.
+func (m *MemoryResponseCache) Get(key string) ([]byte, bool) {
+	m.mu.RLock()
+	response, ok := m.responses[key]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if !response.expires.IsZero() && time.Now().After(response.expires) {
+		m.mu.Lock()
+		m.remove(key)
+		m.mu.Unlock()
+		return nil, false
+	}
+	return response.data, true
+}
+
+// Set stores a response under key and indexes it by tags.
+func (m *MemoryResponseCache) Set(key string, data []byte, tags ...string) error {
+	response := memoryResponse{data: data, tags: tags}
+	if m.lifetime > 0 {
+		response.expires = time.Now().Add(m.lifetime)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(key)
+	m.responses[key] = response
+	for _, tag := range tags {
+		if _, ok := m.tags[tag]; !ok {
+			m.tags[tag] = make(map[string]struct{})
+		}
+		m.tags[tag][key] = struct{}{}
+	}
+	return nil
+}
+
+// Invalidate removes every response indexed by one of the tags.
+func (m *MemoryResponseCache) Invalidate(tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tag := range tags {
+		for key := range m.tags[tag] {
+			m.remove(key)
+		}
+		delete(m.tags, tag)
+	}
+	return nil
+}
+
+// remove must be called with the write lock held
+func (m *MemoryResponseCache) remove(key string) {
+	response, ok := m.responses[key]
+	if !ok {
+		return
+	}
+	delete(m.responses, key)
+	for _, tag := range response.tags {
+		if keys, ok := m.tags[tag]; ok {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(m.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisInvalidationBus is an InvalidationBus based on Redis pub/sub.
+// Every node subscribes to the same channel and receives the evictions published by the others.
+type RedisInvalidationBus struct {
+	Client  *redis.Client
+	channel string
+
+	mu            sync.Mutex
+	subscriptions []*redis.PubSub
+}
+
+// NewRedisInvalidationBus creates a new Redis invalidation bus publishing on the given channel.
+func NewRedisInvalidationBus(addr, password string, db int, channel string) *RedisInvalidationBus {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	return &RedisInvalidationBus{
+		Client:  client,
+		channel: channel,
+	}
+}
+
+// Publish sends the invalidation to every subscribed node.
+func (b *RedisInvalidationBus) Publish(invalidation Invalidation) error {
+	data, err := json.Marshal(invalidation)
+	if err != nil {
+		return err
+	}
+	return b.Client.Publish(context.Background(), b.channel, data).Err()
+}
+
+// Subscribe listens to the channel and calls handler for every invalidation received.
+// It returns once the subscription is confirmed by Redis.
+func (b *RedisInvalidationBus) Subscribe(handler InvalidationHandler) error {
+	ctx := context.Background()
+	pubsub := b.Client.Subscribe(ctx, b.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	b.mu.Lock()
+	b.subscriptions = append(b.subscriptions, pubsub)
+	b.mu.Unlock()
+
+	go func() {
+		for message := range pubsub.Channel() {
+			var invalidation Invalidation
+			if err := json.Unmarshal([]byte(message.Payload), &invalidation); err != nil {
+				continue
+			}
+			handler(invalidation)
+		}
+	}()
+	return nil
+}
+
+// Close ends every subscription.
+func (b *RedisInvalidationBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var err error
+	for _, pubsub := range b.subscriptions {
+		if closeErr := pubsub.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	b.subscriptions = nil
+	return err
+}
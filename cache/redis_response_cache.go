@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisResponseCache is a Redis-based implementation of the ResponseCache interface.
+// Responses are stored as plain keys, every tag is a Redis set listing the keys it covers.
+type RedisResponseCache struct {
+	Client   *redis.Client
+	prefix   string
+	lifetime time.Duration
+}
+
+// NewRedisResponseCache creates a new Redis response cache with the specified connection parameters and lifetime.
+func NewRedisResponseCache(addr, password string, db int, lifetime int) *RedisResponseCache {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	return &RedisResponseCache{
+		Client:   client,
+		prefix:   "restman:response:",
+		lifetime: time.Duration(lifetime) * time.Second,
+	}
+}
+
+func (r *RedisResponseCache) responseKey(key string) string {
+	return r.prefix + key
+}
+
+func (r *RedisResponseCache) tagKey(tag string) string {
+	return r.prefix + "tag:" + tag
+}
+
+// Get retrieves a cached response from Redis.
+func (r *RedisResponseCache) Get(key string) ([]byte, bool) {
+	data, err := r.Client.Get(context.Background(), r.responseKey(key)).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Set stores a response in Redis and adds its key to every tag set.
+func (r *RedisResponseCache) Set(key string, data []byte, tags ...string) error {
+	ctx := context.Background()
+	responseKey := r.responseKey(key)
+	if err := r.Client.Set(ctx, responseKey, data, r.lifetime).Err(); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := r.Client.SAdd(ctx, r.tagKey(tag), responseKey).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalidate deletes every response listed by the tags, then the tags themselves.
+func (r *RedisResponseCache) Invalidate(tags ...string) error {
+	ctx := context.Background()
+	for _, tag := range tags {
+		tagKey := r.tagKey(tag)
+		keys, err := r.Client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return err
+		}
+		if err := r.Client.Del(ctx, append(keys, tagKey)...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
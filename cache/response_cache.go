@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/philiphil/restman/format"
+)
+
+// ResponseCache stores fully serialized responses so that repeated reads skip
+// the database, the group filtering and the encoding altogether.
+// Every entry is tagged, so that a write on a resource can evict all the responses built from it.
+type ResponseCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, data []byte, tags ...string) error
+	Invalidate(tags ...string) error
+}
+
+// ResponseCacheKey builds the key under which a serialized response is stored.
+// target is the resource id or the query, scope identifies who the response was built for
+// ("public" for resources without reading rights, the user otherwise).
+func ResponseCacheKey(resource string, target string, f format.Format, groups []string, scope string) string {
+	sorted := make([]string, len(groups))
+	copy(sorted, groups)
+	sort.Strings(sorted)
+
+	var builder strings.Builder
+	builder.Grow(len(resource) + len(target) + len(f) + len(scope) + 16*len(sorted) + 4)
+	builder.WriteString(resource)
+	builder.WriteRune('|')
+	builder.WriteString(target)
+	builder.WriteRune('|')
+	builder.WriteString(string(f))
+	builder.WriteRune('|')
+	builder.WriteString(strings.Join(sorted, ","))
+	builder.WriteRune('|')
+	builder.WriteString(scope)
+	return builder.String()
+}
+
+// ItemTag returns the tag of every cached response containing the given item.
+func ItemTag(resource string, id string) string {
+	return resource + ":" + id
+}
+
+// CollectionTag returns the tag of every cached collection response of a resource.
+func CollectionTag(resource string) string {
+	return resource + ":*"
+}
@@ -76,6 +76,18 @@ const (
 	OutputSerializationGroupOverwriteClientControlType // Allows clients to overwrite serialization groups
 	OutputSerializationGroupOverwriteParameterNameType // Query parameter name for overwriting serialization groups
 
+	// SecurityType sets the security expression a user must be granted to use a route (default: none)
+	// Attributes are decided by the voters of the router, see security.AccessDecisionManager
+	// Example: "ROLE_ADMIN or (ROLE_EDITOR and BOOK_EDIT)"
+	SecurityType
+
+	// AuthenticationRequiredType rejects anonymous users with 401 before any other check (default: disabled)
+	AuthenticationRequiredType
+
+	// RateLimitType overrides, on a route, the budget the rate limiter of the router gives it (default: none)
+	// Values are the limit and the window in seconds, the route then has its own budget
+	RateLimitType
+
 	// Unimplemented configuration types - reserved for future use
 
 	// Whether write routes default to read output serialization
@@ -292,3 +304,34 @@ func WriteRouteOutputShouldDefaultToReadOutput(enabled bool) Configuration {
 func BatchRouteConfigurationDefaultToSingleRouteConfiguration(enabled bool) Configuration {
 	return Configuration{Type: BatchRouteConfigurationDefaultToSingleRouteConfigurationType, Values: []string{strconv.FormatBool(enabled)}}
 }
+
+// Security sets the security expression a user must be granted to use the routes.
+// Set router wide it applies to every route, set on a route it overrides the router wide expression.
+// Every attribute of the expression is decided by the voters of the router, the entity being the subject of the vote.
+//
+// Example:
+//
+//	route.NewRoute(route.Delete, configuration.Security("ROLE_ADMIN or BOOK_DELETE"))
+func Security(expression string) Configuration {
+	return Configuration{Type: SecurityType, Values: []string{expression}}
+}
+
+// AuthenticationRequired rejects anonymous users with 401 on the router or the route.
+// Default is disabled (false).
+//
+// Example:
+//
+//	router.NewApiRouter(*repo, routes, configuration.AuthenticationRequired(true))
+func AuthenticationRequired(required bool) Configuration {
+	return Configuration{Type: AuthenticationRequiredType, Values: []string{strconv.FormatBool(required)}}
+}
+
+// RateLimit gives a route its own budget of limit cost units per window, in seconds.
+// It is only read on routes, with the algorithm and the store of the rate limiter of the router (see ApiRouter.SetRateLimiter).
+//
+// Example:
+//
+//	route.NewRoute(route.Post, configuration.RateLimit(10, 60)) // 10 creations per minute
+func RateLimit(limit int, window int) Configuration {
+	return Configuration{Type: RateLimitType, Values: []string{strconv.Itoa(limit), strconv.Itoa(window)}}
+}
@@ -26,6 +26,9 @@ func DefaultConfiguration() map[ConfigurationType]Configuration {
 		OutputSerializationGroupOverwriteClientControlType: OutputSerializationGroupOverwriteClientControl(false),
 		OutputSerializationGroupOverwriteParameterNameType: OutputSerializationGroupOverwriteParameterName("groupOverwrite"),
 
+		SecurityType:               Security(""),
+		AuthenticationRequiredType: AuthenticationRequired(false),
+
 		//not implemented yet
 		WriteRouteOutputShouldDefaultToReadOutputType:                WriteRouteOutputShouldDefaultToReadOutput(true),
 		BatchRouteConfigurationDefaultToSingleRouteConfigurationType: BatchRouteConfigurationDefaultToSingleRouteConfiguration(true),
@@ -27,10 +27,15 @@ var (
 	ErrBadFormat     = ApiError{http.StatusBadRequest, "could not parse format", true}
 	ErrDatabaseIssue = ApiError{http.StatusInternalServerError, "database issue", true}
 	ErrUnsupported   = ApiError{http.StatusTeapot, "unsupported", false}
+	ErrNoCredentials = ApiError{http.StatusUnauthorized, "no credentials", false}
 
 	ErrBadMethod  = ApiError{http.StatusMethodNotAllowed, "method not allowed", true}
 	ErrBadRequest = ApiError{http.StatusBadRequest, "bad request", true}
 	ErrForbidden  = ApiError{http.StatusForbidden, "forbidden", true}
 	ErrConflict   = ApiError{http.StatusConflict, "conflict", true}
 	ErrInternal   = ApiError{http.StatusInternalServerError, "internal error", true}
+
+	ErrNotImplemented  = ApiError{http.StatusNotImplemented, "not implemented", true}
+	ErrTooManyRequests = ApiError{http.StatusTooManyRequests, "too many requests", true}
+	ErrTooLarge        = ApiError{http.StatusRequestEntityTooLarge, "request entity too large", true}
 )
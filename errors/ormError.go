@@ -16,6 +16,10 @@ func (f OrmError) Error() string {
 }
 
 var (
-	ItemNotFound    = OrmError{1}
-	NotAllItemFound = OrmError{2}
+	ItemNotFound          = OrmError{1}
+	NotAllItemFound       = OrmError{2}
+	SoftDeleteUnsupported = OrmError{3}
+	InvalidIdentifier     = OrmError{4}
+	ConditionsUnsupported = OrmError{5}
+	OutOfScope            = OrmError{6}
 )
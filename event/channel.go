@@ -0,0 +1,55 @@
+package event
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrBusFull is returned when a ChannelBus has no room left for an event
+var ErrBusFull = errors.New("event bus is full")
+
+// ErrBusClosed is returned when publishing on a closed ChannelBus
+var ErrBusClosed = errors.New("event bus is closed")
+
+// ChannelBus is a Bus delivering events on a buffered channel, to be consumed by other goroutines
+// Publishing never blocks the request, events are dropped with ErrBusFull when the buffer is full.
+type ChannelBus struct {
+	mu     sync.RWMutex
+	events chan Event
+	closed bool
+}
+
+// NewChannelBus creates a ChannelBus buffering up to size events.
+func NewChannelBus(size int) *ChannelBus {
+	return &ChannelBus{events: make(chan Event, size)}
+}
+
+// Events returns the channel of the published events, it is closed by Close.
+func (b *ChannelBus) Events() <-chan Event {
+	return b.events
+}
+
+// Publish sends the event on the channel.
+func (b *ChannelBus) Publish(event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrBusClosed
+	}
+	select {
+	case b.events <- event:
+		return nil
+	default:
+		return ErrBusFull
+	}
+}
+
+// Close closes the channel, consumers ranging over Events still receive the pending events.
+func (b *ChannelBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		close(b.events)
+	}
+}
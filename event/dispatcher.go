@@ -0,0 +1,59 @@
+package event
+
+import (
+	"errors"
+	"sync"
+)
+
+// Handler reacts to an event
+type Handler func(event Event) error
+
+// Dispatcher is an in-process Bus, it calls the matching handlers synchronously in their subscription order
+type Dispatcher struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+type subscription struct {
+	names   map[string]bool
+	handler Handler
+}
+
+// NewDispatcher creates a Dispatcher without handlers.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+// Subscribe calls handler for the events of the given names, such as "Book.create", or resources, such as "Book".
+// handler is called for every event when no name is given.
+func (d *Dispatcher) Subscribe(handler Handler, names ...string) {
+	sub := subscription{handler: handler}
+	if len(names) > 0 {
+		sub.names = make(map[string]bool, len(names))
+		for _, name := range names {
+			sub.names[name] = true
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscriptions = append(d.subscriptions, sub)
+}
+
+// Publish calls every matching handler, a failing handler does not prevent the next ones from being called.
+func (d *Dispatcher) Publish(event Event) error {
+	d.mu.RLock()
+	subscriptions := d.subscriptions
+	d.mu.RUnlock()
+
+	var errs []error
+	name := event.Name()
+	for _, sub := range subscriptions {
+		if sub.names != nil && !sub.names[name] && !sub.names[event.Resource] {
+			continue
+		}
+		if err := sub.handler(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,61 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/philiphil/restman/audit"
+)
+
+// Event describes a write of a resource, it is emitted once the write succeeded
+type Event struct {
+	ID        string          `json:"id"`
+	Time      time.Time       `json:"time"`
+	RequestID string          `json:"request_id,omitempty"`
+	User      string          `json:"user,omitempty"`
+	Resource  string          `json:"resource"`
+	Operation audit.Operation `json:"operation"`
+	Batch     bool            `json:"batch"`
+	IDs       []string        `json:"ids"`
+	// Payload is the JSON array of the written entities, serialized with the groups of the emitting router
+	// Deleted and purged entities are serialized as they were before the write
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Name returns the type of the event, its resource and operation such as "Book.create".
+func (e Event) Name() string {
+	return e.Resource + "." + string(e.Operation)
+}
+
+// Decode unmarshals the payload of the event into the entities of its resource.
+func Decode[T any](e Event) ([]T, error) {
+	var entities []T
+	if len(e.Payload) == 0 {
+		return entities, nil
+	}
+	err := json.Unmarshal(e.Payload, &entities)
+	return entities, err
+}
+
+// Bus delivers events to the parts of an application reacting to them
+type Bus interface {
+	Publish(event Event) error
+}
+
+// BusFunc adapts a function to a Bus
+type BusFunc func(event Event) error
+
+// Publish calls f.
+func (f BusFunc) Publish(event Event) error {
+	return f(event)
+}
+
+// TransactionalBus stores events in the transaction of the write emitting them, such as an outbox
+// They are only delivered once the transaction is committed, and never if it is rolled back.
+type TransactionalBus interface {
+	Bus
+	// Transaction runs write in a transaction and stores the events it returns in it.
+	// write must run its operations with the context it receives.
+	Transaction(ctx context.Context, write func(ctx context.Context) ([]Event, error)) error
+}
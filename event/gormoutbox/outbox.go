@@ -0,0 +1,106 @@
+package gormoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/philiphil/restman/event"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"gorm.io/gorm"
+)
+
+// Message is an event stored in the outbox, it is pending until a Relay publishes it or parks it
+type Message struct {
+	Id          uint   `gorm:"primaryKey"`
+	EventID     string `gorm:"uniqueIndex"`
+	Name        string
+	Event       string // the JSON encoded event
+	CreatedAt   time.Time
+	PublishedAt *time.Time `gorm:"index"`
+	Attempts    int
+	LastError   string
+	// ParkedAt is set once the message failed Relay.MaxAttempts times, clearing it makes the message pending again
+	ParkedAt *time.Time `gorm:"index"`
+}
+
+// TableName keeps the outbox apart from the tables of the application.
+func (m Message) TableName() string {
+	return "outbox_messages"
+}
+
+// Decode returns the event of the message.
+func (m Message) Decode() (event.Event, error) {
+	var e event.Event
+	err := json.Unmarshal([]byte(m.Event), &e)
+	return e, err
+}
+
+// Outbox is a transactional event.Bus storing events in the database written by the routers
+// Events are stored in the transaction of their write, a Relay then publishes them to another bus.
+type Outbox struct {
+	DB *gorm.DB
+}
+
+// NewOutbox creates an outbox in db, which must be the database of the gormrepository.GormRepository of the routers.
+//
+// Example:
+//
+//	db.AutoMigrate(&gormoutbox.Message{})
+//	outbox := gormoutbox.NewOutbox(db)
+//	bookRouter.SetEventBus(outbox, "read")
+//	go gormoutbox.NewRelay(outbox, dispatcher).Run(ctx)
+func NewOutbox(db *gorm.DB) *Outbox {
+	return &Outbox{DB: db}
+}
+
+// Publish stores the event on its own, for the writes not run in a transaction of the outbox.
+func (o *Outbox) Publish(e event.Event) error {
+	return o.store(o.DB, e)
+}
+
+// Transaction runs write in a transaction of the outbox database and stores the events it returns in it.
+// The GormRepositories used with the context received by write join the transaction.
+func (o *Outbox) Transaction(ctx context.Context, write func(ctx context.Context) ([]event.Event, error)) error {
+	return o.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		events, err := write(gormrepository.WithTransaction(ctx, tx))
+		if err != nil {
+			return err
+		}
+		return o.store(tx, events...)
+	})
+}
+
+// Pending returns up to limit messages neither published nor parked, oldest first.
+func (o *Outbox) Pending(ctx context.Context, limit int) ([]Message, error) {
+	return o.pendingAfter(ctx, 0, limit)
+}
+
+// Parked returns up to limit parked messages, oldest first.
+func (o *Outbox) Parked(ctx context.Context, limit int) ([]Message, error) {
+	var messages []Message
+	err := o.DB.WithContext(ctx).Where("parked_at IS NOT NULL").Order("id").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// pendingAfter returns up to limit pending messages stored after the message after, oldest first.
+func (o *Outbox) pendingAfter(ctx context.Context, after uint, limit int) ([]Message, error) {
+	var messages []Message
+	err := o.DB.WithContext(ctx).Where("published_at IS NULL AND parked_at IS NULL AND id > ?", after).Order("id").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+func (o *Outbox) store(db *gorm.DB, events ...event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	messages := make([]Message, len(events))
+	for i, e := range events {
+		encoded, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		messages[i] = Message{EventID: e.ID, Name: e.Name(), Event: string(encoded)}
+	}
+	return db.Create(&messages).Error
+}
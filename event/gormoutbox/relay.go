@@ -0,0 +1,88 @@
+package gormoutbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/philiphil/restman/event"
+)
+
+// Relay publishes the pending messages of an Outbox to a Bus, in the order they were stored, failing messages aside
+// Delivery is at least once: a message published right before a crash is published again, consumers may deduplicate on event.Event.ID.
+// A failing message does not hold the others back: it is retried by the next calls, then parked after MaxAttempts.
+// A single Relay should run per outbox.
+type Relay struct {
+	Outbox    *Outbox
+	Bus       event.Bus
+	BatchSize int
+	Interval  time.Duration
+	// MaxAttempts parks a message after as many failures, messages are retried forever when 0
+	MaxAttempts int
+}
+
+// NewRelay creates a Relay polling outbox every second, up to 100 messages at a time, parking messages after 10 failures.
+func NewRelay(outbox *Outbox, bus event.Bus) *Relay {
+	return &Relay{Outbox: outbox, Bus: bus, BatchSize: 100, Interval: time.Second, MaxAttempts: 10}
+}
+
+// RelayPending publishes the pending messages and returns how many were published, with the failures met.
+// Failed messages record the attempt and are skipped, the next calls retry them until they are parked.
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	published := 0
+	var failures []error
+	var after uint
+	for {
+		messages, err := r.Outbox.pendingAfter(ctx, after, r.BatchSize)
+		if err != nil {
+			return published, errors.Join(append(failures, err)...)
+		}
+		if len(messages) == 0 {
+			return published, errors.Join(failures...)
+		}
+		for _, message := range messages {
+			after = message.Id
+			e, err := message.Decode()
+			if err == nil {
+				err = r.Bus.Publish(e)
+			}
+			if err != nil {
+				failures = append(failures, err)
+				updates := map[string]any{
+					"attempts":   message.Attempts + 1,
+					"last_error": err.Error(),
+				}
+				if r.MaxAttempts > 0 && message.Attempts+1 >= r.MaxAttempts {
+					updates["parked_at"] = time.Now().UTC()
+				}
+				r.Outbox.DB.WithContext(ctx).Model(&message).Updates(updates)
+				continue
+			}
+			now := time.Now().UTC()
+			if err = r.Outbox.DB.WithContext(ctx).Model(&message).Updates(map[string]any{
+				"attempts":     message.Attempts + 1,
+				"published_at": &now,
+			}).Error; err != nil {
+				return published, errors.Join(append(failures, err)...)
+			}
+			published++
+		}
+		if len(messages) < r.BatchSize {
+			return published, errors.Join(failures...)
+		}
+	}
+}
+
+// Run relays the pending messages every Interval until ctx is done, failures are retried at the next tick.
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		_, _ = r.RelayPending(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -25,6 +25,10 @@ func (e Entity) GetId() entity.ID {
 	return entity.CastId(e.ID)
 }
 
+func (e Entity) GetIdentifier() entity.Identifier {
+	return e.GetId()
+}
+
 func (e Entity) SetId(id any) entity.Entity {
 	e.ID = int(entity.CastId(id))
 	return e
@@ -0,0 +1,202 @@
+package jsonschema
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// validationTags are the struct tags holding validation rules, those of go-playground/validator and of gin's binding
+var validationTags = []string{"validate", "binding"}
+
+var formats = map[string]string{
+	"email":            "email",
+	"url":              "uri",
+	"uri":              "uri",
+	"http_url":         "uri",
+	"uuid":             "uuid",
+	"uuid3":            "uuid",
+	"uuid4":            "uuid",
+	"uuid5":            "uuid",
+	"uuid_rfc4122":     "uuid",
+	"ipv4":             "ipv4",
+	"ipv6":             "ipv6",
+	"hostname":         "hostname",
+	"hostname_rfc1123": "hostname",
+	"fqdn":             "hostname",
+}
+
+var enumValue = regexp.MustCompile(`'[^']*'|\S+`)
+
+var patterns = map[string]string{
+	"alpha":    `^[a-zA-Z]+$`,
+	"alphanum": `^[a-zA-Z0-9]+$`,
+	"numeric":  `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"number":   `^[0-9]+$`,
+	"e164":     `^\+[1-9][0-9]{1,14}$`,
+}
+
+// constrain adds the validation rules of a field to its schema, it tells whether the field is required.
+// Rules without a JSON Schema equivalent, and alternatives like "email|url", are ignored.
+func constrain(s *Schema, t reflect.Type, field reflect.StructField) bool {
+	required := false
+	for _, tag := range validationTags {
+		if rules, ok := field.Tag.Lookup(tag); ok {
+			required = applyRules(s, t, strings.Split(rules, ",")) || required
+		}
+	}
+	return required
+}
+
+func applyRules(s *Schema, t reflect.Type, rules []string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	required := false
+	for i := 0; i < len(rules); i++ {
+		rule, param, _ := strings.Cut(rules[i], "=")
+		switch {
+		case rule == "required":
+			required = true
+		case rule == "dive":
+			//the following rules apply to the items
+			if s.Items != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+				applyRules(s.Items, t.Elem(), rules[i+1:])
+			}
+			return required
+		case rule == "keys":
+			for i < len(rules) && rules[i] != "endkeys" {
+				i++
+			}
+		case strings.Contains(rules[i], "|"):
+		case formats[rule] != "" && t.Kind() == reflect.String:
+			s.Format = formats[rule]
+		case patterns[rule] != "" && t.Kind() == reflect.String:
+			addPattern(s, patterns[rule])
+		case rule == "startswith" && t.Kind() == reflect.String:
+			addPattern(s, "^"+regexp.QuoteMeta(param))
+		case rule == "endswith" && t.Kind() == reflect.String:
+			addPattern(s, regexp.QuoteMeta(param)+"$")
+		case rule == "contains" && t.Kind() == reflect.String:
+			addPattern(s, regexp.QuoteMeta(param))
+		case rule == "unique" && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+			s.UniqueItems = true
+		case rule == "oneof":
+			s.Enum = enum(t, param)
+		case param != "":
+			bound(s, t, rule, param)
+		}
+	}
+	return required
+}
+
+// addPattern adds a pattern the value must match, several patterns are combined with lookaheads
+func addPattern(s *Schema, pattern string) {
+	switch {
+	case s.Pattern == "" || s.Pattern == pattern:
+		s.Pattern = pattern
+	case strings.HasPrefix(s.Pattern, "^(?="):
+		s.Pattern += lookahead(pattern)
+	default:
+		s.Pattern = "^" + lookahead(s.Pattern) + lookahead(pattern)
+	}
+}
+
+// lookahead matches pattern anywhere in the value, without consuming it
+func lookahead(pattern string) string {
+	return `(?=[\s\S]*?(?:` + pattern + `))`
+}
+
+// bound applies the length, size and value bounds
+func bound(s *Schema, t reflect.Type, rule string, param string) {
+	switch {
+	case isBytes(t):
+		//the length of the base64 encoding is not the one validated
+	case t.Kind() == reflect.String:
+		length, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		switch rule {
+		case "min", "gte":
+			s.MinLength = &length
+		case "max", "lte":
+			s.MaxLength = &length
+		case "gt":
+			length++
+			s.MinLength = &length
+		case "lt":
+			length--
+			s.MaxLength = &length
+		case "len":
+			s.MinLength, s.MaxLength = &length, &length
+		}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		size, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		switch rule {
+		case "min", "gte":
+			s.MinItems = &size
+		case "max", "lte":
+			s.MaxItems = &size
+		case "gt":
+			size++
+			s.MinItems = &size
+		case "lt":
+			size--
+			s.MaxItems = &size
+		case "len":
+			s.MinItems, s.MaxItems = &size, &size
+		}
+	case isNumber(t):
+		value, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		switch rule {
+		case "min", "gte":
+			s.Minimum = &value
+		case "max", "lte":
+			s.Maximum = &value
+		case "gt":
+			s.ExclusiveMinimum = &value
+		case "lt":
+			s.ExclusiveMaximum = &value
+		case "len", "eq":
+			s.Minimum, s.Maximum = &value, &value
+		}
+	}
+}
+
+// enum returns the values of a oneof rule, space separated and optionally single quoted
+func enum(t reflect.Type, param string) []any {
+	var values []any
+	for _, match := range enumValue.FindAllString(param, -1) {
+		match = strings.Trim(match, "'")
+		if isNumber(t) {
+			if value, err := strconv.ParseFloat(match, 64); err == nil {
+				values = append(values, value)
+			}
+			continue
+		}
+		values = append(values, match)
+	}
+	return values
+}
+
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
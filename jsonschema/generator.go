@@ -0,0 +1,207 @@
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/serializer/filter"
+	"gorm.io/gorm"
+)
+
+// Direction tells whether a schema describes the bodies of requests or those of responses
+type Direction int8
+
+const (
+	// Output schemas describe serialized entities, their fields are required unless they are omitempty and not required by their validation rules
+	Output Direction = iota
+	// Input schemas describe request bodies, which are merged into the entities so no field is required,
+	// whatever their validation rules: they are only checked on the merged entity
+	Input
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	uuidType          = reflect.TypeFor[entity.UUID]()
+	deletedAtType     = reflect.TypeFor[gorm.DeletedAt]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	invalidName       = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// Generator builds the schemas of Go types as they are serialized with serialization groups, following their json tags.
+// Named structs are defined once per direction and group combination, in Definitions, and referenced.
+type Generator struct {
+	// RefPrefix prefixes the references to the definitions, "#/$defs/" for standalone schemas
+	RefPrefix string
+	// Definitions are the schemas of the named structs, by definition name
+	Definitions map[string]*Schema
+
+	types map[string]reflect.Type
+}
+
+// NewGenerator creates a generator referencing its definitions with refPrefix.
+func NewGenerator(refPrefix string) *Generator {
+	return &Generator{
+		RefPrefix:   refPrefix,
+		Definitions: map[string]*Schema{},
+		types:       map[string]reflect.Type{},
+	}
+}
+
+// Schema returns the schema of the values of t serialized with groups, every field is included without groups.
+func (g *Generator) Schema(t reflect.Type, direction Direction, groups ...string) *Schema {
+	switch {
+	case t.Kind() == reflect.Pointer:
+		return Nullable(g.Schema(t.Elem(), direction, groups...))
+	case t == timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case t == deletedAtType:
+		return &Schema{Type: Types{"string", "null"}, Format: "date-time"}
+	case t == uuidType:
+		return &Schema{Type: Types{"string"}, Format: "uuid"}
+	case implements(t, textMarshalerType):
+		return &Schema{Type: Types{"string"}}
+	case implements(t, jsonMarshalerType):
+		//anything, its encoding is its own
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: Types{"integer"}, Format: "int64"}
+	case reflect.Int32:
+		return &Schema{Type: Types{"integer"}, Format: "int32"}
+	case reflect.Int8, reflect.Int16:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: Types{"integer"}, Minimum: number(0)}
+	case reflect.Float32:
+		return &Schema{Type: Types{"number"}, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: Types{"number"}, Format: "double"}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{"string"}, ContentEncoding: "base64"}
+		}
+		return ArrayOf(g.Schema(t.Elem(), direction, groups...))
+	case reflect.Array:
+		return ArrayOf(g.Schema(t.Elem(), direction, groups...))
+	case reflect.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: g.Schema(t.Elem(), direction, groups...)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t, direction, groups)
+		}
+		name := g.definitionName(t, direction, groups)
+		if _, ok := g.Definitions[name]; !ok {
+			//set first, so that recursive types reference it
+			definition := &Schema{}
+			g.Definitions[name] = definition
+			*definition = *g.object(t, direction, groups)
+		}
+		return Ref(g.RefPrefix + name)
+	}
+	return &Schema{}
+}
+
+// Generate returns the standalone schema of the values of t serialized with groups, the named structs are defined in its $defs.
+func Generate(t reflect.Type, direction Direction, groups ...string) *Schema {
+	g := NewGenerator("#/$defs/")
+	s := g.Schema(t, direction, groups...)
+	s.Schema = Draft
+	if len(g.Definitions) > 0 {
+		s.Defs = g.Definitions
+	}
+	return s
+}
+
+// object returns the schema of a struct with its fields
+func (g *Generator) object(t reflect.Type, direction Direction, groups []string) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}}
+	g.addFields(s, t, direction, groups)
+	return s
+}
+
+// addFields adds the fields of a struct to the properties of s, the fields of embedded structs are promoted like encoding/json does
+func (g *Generator) addFields(s *Schema, t reflect.Type, direction Direction, groups []string) {
+	var embedded []reflect.Type
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			if fieldType := filter.DereferenceTypeIfPointer(field.Type); fieldType.Kind() == reflect.Struct {
+				embedded = append(embedded, fieldType)
+				continue
+			}
+		}
+		if !field.IsExported() || !filter.IsFieldIncluded(field, groups) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := s.Properties[name]; ok {
+			continue
+		}
+		property := g.Schema(field.Type, direction, groups...)
+		var required bool
+		if slices.Contains(strings.Split(options, ","), "string") {
+			//the value is encoded in a string, its bounds, formats and patterns do not apply to the string
+			property = &Schema{Type: Types{"string"}}
+			required = constrain(&Schema{}, field.Type, field)
+		} else {
+			required = constrain(property, field.Type, field)
+		}
+		s.Properties[name] = property
+		if direction == Output && (required || !slices.Contains(strings.Split(options, ","), "omitempty")) {
+			s.Required = append(s.Required, name)
+		}
+	}
+	//fields of the struct itself shadow the promoted ones
+	for _, fieldType := range embedded {
+		g.addFields(s, fieldType, direction, groups)
+	}
+}
+
+// definitionName names the definition of a struct: "Book", "Book-read", "BookInput-write"
+// Structs of different packages sharing a name are prefixed with their package.
+func (g *Generator) definitionName(t reflect.Type, direction Direction, groups []string) string {
+	name := DefinitionName(t, direction, groups)
+	if known, ok := g.types[name]; ok && known != t {
+		name = invalidName.ReplaceAllString(path.Base(t.PkgPath()), "_") + "." + name
+	}
+	g.types[name] = t
+	return name
+}
+
+// DefinitionName returns the name of the definition of a struct serialized with groups, in the given direction.
+func DefinitionName(t reflect.Type, direction Direction, groups []string) string {
+	name := invalidName.ReplaceAllString(t.Name(), "_")
+	if direction == Input {
+		name += "Input"
+	}
+	if len(groups) > 0 {
+		sorted := slices.Clone(groups)
+		slices.Sort(sorted)
+		name += "-" + invalidName.ReplaceAllString(strings.Join(sorted, "_"), "_")
+	}
+	return name
+}
+
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
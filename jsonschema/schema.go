@@ -0,0 +1,87 @@
+package jsonschema
+
+import "encoding/json"
+
+// Draft is the JSON Schema dialect of the schemas, also the one of OpenAPI 3.1
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema, restricted to the keywords describing Go types
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	ID          string `json:"$id,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type   Types  `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+	// ContentEncoding is "base64" for byte slices
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+	Enum            []any  `json:"enum,omitempty"`
+	Default         any    `json:"default,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	MinLength        *int     `json:"minLength,omitempty"`
+	MaxLength        *int     `json:"maxLength,omitempty"`
+	Pattern          string   `json:"pattern,omitempty"`
+	MinItems         *int     `json:"minItems,omitempty"`
+	MaxItems         *int     `json:"maxItems,omitempty"`
+	UniqueItems      bool     `json:"uniqueItems,omitempty"`
+
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Types are the types a value may have, it is encoded as a single string when there is only one
+type Types []string
+
+// MarshalJSON implements json.Marshaler.
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// Ref returns a schema referencing another one.
+func Ref(ref string) *Schema {
+	return &Schema{Ref: ref}
+}
+
+// ArrayOf returns the schema of a list of items.
+func ArrayOf(items *Schema) *Schema {
+	return &Schema{Type: Types{"array"}, Items: items}
+}
+
+// Nullable returns the schema accepting the values of s and null.
+func Nullable(s *Schema) *Schema {
+	if s.Ref != "" || len(s.Type) == 0 {
+		return &Schema{OneOf: []*Schema{s, {Type: Types{"null"}}}}
+	}
+	nullable := *s
+	nullable.Type = append(Types{}, s.Type...)
+	nullable.Type = append(nullable.Type, "null")
+	return &nullable
+}
+
+func number(value float64) *float64 {
+	return &value
+}
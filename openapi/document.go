@@ -0,0 +1,203 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/jsonschema"
+)
+
+// Version is the OpenAPI version of the documents
+const Version = "3.1.0"
+
+// SchemaRefPrefix prefixes the references to the schemas of the components
+const SchemaRefPrefix = "#/components/schemas/"
+
+// Document is an OpenAPI document, restricted to what RestMan describes
+// To create a Document, you should use the NewDocument function
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	generator *jsonschema.Generator
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL of the API
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups the operations of a resource
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components are the objects referenced by the operations
+type Components struct {
+	Schemas         map[string]*jsonschema.Schema `json:"schemas,omitempty"`
+	Responses       map[string]*Response          `json:"responses,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme    `json:"securitySchemes,omitempty"`
+}
+
+// PathItem holds the operations of a path, by method
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+}
+
+// Operation describes a route
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	// Security lists the alternative requirements, an empty requirement allows anonymous requests
+	Security []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Parameter describes a path or query parameter
+type Parameter struct {
+	Name        string             `json:"name"`
+	In          string             `json:"in"`
+	Description string             `json:"description,omitempty"`
+	Required    bool               `json:"required,omitempty"`
+	Style       string             `json:"style,omitempty"`
+	Explode     *bool              `json:"explode,omitempty"`
+	Schema      *jsonschema.Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes the body of a request, by media type
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// MediaType holds the schema of a body in a media type
+type MediaType struct {
+	Schema *jsonschema.Schema `json:"schema,omitempty"`
+}
+
+// Response describes a response, or references one of the components
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header describes a response header
+type Header struct {
+	Description string             `json:"description,omitempty"`
+	Schema      *jsonschema.Schema `json:"schema,omitempty"`
+}
+
+// SecurityRequirement lists the security schemes a request must satisfy together, by name
+type SecurityRequirement map[string][]string
+
+// NewDocument creates an empty document.
+func NewDocument(info Info) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Responses:       map[string]*Response{},
+			SecuritySchemes: map[string]*SecurityScheme{},
+		},
+		generator: jsonschema.NewGenerator(SchemaRefPrefix),
+	}
+	d.Components.Schemas = d.generator.Definitions
+	return d
+}
+
+// AddOperation sets the operation of a method on a path, the path uses the {param} syntax.
+func (d *Document) AddOperation(method string, path string, operation *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	switch method {
+	case http.MethodGet:
+		item.Get = operation
+	case http.MethodPut:
+		item.Put = operation
+	case http.MethodPost:
+		item.Post = operation
+	case http.MethodDelete:
+		item.Delete = operation
+	case http.MethodOptions:
+		item.Options = operation
+	case http.MethodHead:
+		item.Head = operation
+	case http.MethodPatch:
+		item.Patch = operation
+	}
+}
+
+// AddTag adds a tag once.
+func (d *Document) AddTag(tag Tag) {
+	if !slices.ContainsFunc(d.Tags, func(t Tag) bool { return t.Name == tag.Name }) {
+		d.Tags = append(d.Tags, tag)
+	}
+}
+
+// Schema returns the schema of the values of t serialized with groups, the structs are added to the schemas of the components.
+func (d *Document) Schema(t reflect.Type, direction jsonschema.Direction, groups ...string) *jsonschema.Schema {
+	return d.generator.Schema(t, direction, groups...)
+}
+
+// ErrorResponse returns a reference to the response of the status of an ApiError, whose body is its JSON encoded message.
+func (d *Document) ErrorResponse(err errors.ApiError) *Response {
+	name := strings.ReplaceAll(http.StatusText(err.Code), " ", "")
+	if _, ok := d.Components.Responses[name]; !ok {
+		d.Components.Responses[name] = &Response{
+			Description: http.StatusText(err.Code),
+			Content: map[string]*MediaType{
+				"application/json": {Schema: &jsonschema.Schema{Type: jsonschema.Types{"string"}}},
+			},
+		}
+	}
+	return &Response{Ref: "#/components/responses/" + name}
+}
+
+// JSON encodes the document.
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// YAML encodes the document.
+func (d *Document) YAML() ([]byte, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(data)
+}
@@ -0,0 +1,93 @@
+package openapi
+
+import (
+	"strings"
+
+	"github.com/philiphil/restman/security"
+)
+
+// SecurityScheme describes how requests are authenticated
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// SecuritySchemeDescriber is implemented by the firewalls describing their own security scheme
+type SecuritySchemeDescriber interface {
+	SecurityScheme() (name string, scheme SecurityScheme)
+}
+
+// FirewallSecurityScheme returns the security scheme of a firewall and its name.
+// The name is suffixed with the header, cookie, realm or issuer of the firewall when it is not the default one,
+// so that firewalls with different settings get their own scheme.
+// It is false for the firewalls of the application not implementing SecuritySchemeDescriber.
+func FirewallSecurityScheme(firewall security.Firewall) (string, SecurityScheme, bool) {
+	switch f := firewall.(type) {
+	case SecuritySchemeDescriber:
+		name, scheme := f.SecurityScheme()
+		return name, scheme, true
+	case *security.JWTFirewall:
+		scheme := SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+		if f.Issuer != "" {
+			scheme.Description = "Tokens issued by " + f.Issuer
+		}
+		return schemeName("bearerAuth", f.Issuer), scheme, true
+	case *security.BasicFirewall:
+		scheme := SecurityScheme{Type: "http", Scheme: "basic"}
+		if f.Realm != "" {
+			scheme.Description = "Realm " + f.Realm
+		}
+		return schemeName("basicAuth", f.Realm), scheme, true
+	case *security.APIKeyFirewall:
+		header := f.Header
+		if header == "" || header == security.DefaultAPIKeyHeader {
+			return "apiKeyAuth", SecurityScheme{Type: "apiKey", In: "header", Name: security.DefaultAPIKeyHeader}, true
+		}
+		return schemeName("apiKeyAuth", header), SecurityScheme{Type: "apiKey", In: "header", Name: header}, true
+	case *security.SessionFirewall:
+		cookie := f.CookieName
+		if cookie == "" {
+			cookie = security.DefaultSessionCookie
+		}
+		csrfHeader := f.CSRFHeader
+		if csrfHeader == "" {
+			csrfHeader = security.DefaultCSRFHeader
+		}
+		suffix := ""
+		if cookie != security.DefaultSessionCookie {
+			suffix = cookie
+		}
+		return schemeName("sessionAuth", suffix), SecurityScheme{
+			Type:        "apiKey",
+			In:          "cookie",
+			Name:        cookie,
+			Description: "Unsafe requests must send the CSRF token of the session in the " + csrfHeader + " header",
+		}, true
+	case *security.SignatureFirewall:
+		return "signatureAuth", SecurityScheme{
+			Type: "apiKey",
+			In:   "header",
+			Name: security.SignatureHeader,
+			Description: "HMAC signature of the request, with the " + security.SignatureKeyHeader + ", " +
+				security.SignatureTimestampHeader + " and " + security.SignatureNonceHeader + " headers, see security.SignRequest",
+		}, true
+	}
+	return "", SecurityScheme{}, false
+}
+
+// schemeName suffixes name with the setting distinguishing a firewall, the characters not allowed in component names are replaced
+func schemeName(name string, suffix string) string {
+	if suffix == "" {
+		return name
+	}
+	return name + "_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, suffix)
+}
@@ -0,0 +1,135 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// Operator is the comparison of a Condition
+type Operator string
+
+const (
+	// OperatorEqual matches entities whose field equals the value
+	OperatorEqual Operator = "="
+	// OperatorNotEqual matches entities whose field differs from the value
+	OperatorNotEqual Operator = "!="
+	// OperatorIn matches entities whose field is one of the values, Value must be a slice
+	OperatorIn Operator = "in"
+)
+
+// Condition restricts the entities an operation applies to, whatever the repository behind it
+// Field is the database field (or column), repositories may also accept the struct field name
+type Condition struct {
+	Field    string
+	Operator Operator
+	Value    any
+}
+
+// Where returns a condition matching entities whose field equals value.
+func Where(field string, value any) Condition {
+	return Condition{Field: field, Operator: OperatorEqual, Value: value}
+}
+
+// WhereIn returns a condition matching entities whose field is one of values.
+func WhereIn[V any](field string, values []V) Condition {
+	return Condition{Field: field, Operator: OperatorIn, Value: values}
+}
+
+// ScopedRepository is implemented by repositories able to restrict every operation with conditions
+// Scoped returns a repository whose reads, counts, lookups and deletions only see the matching entities
+type ScopedRepository[E entity.Entity] interface {
+	Scoped(conditions ...Condition) RestRepository[entity.DatabaseModel[E], E]
+}
+
+// Scoped returns an ORM restricted to the entities matching every condition.
+// Repositories that cannot apply conditions return errors.ConditionsUnsupported, they are never ignored.
+func (r *ORM[T]) Scoped(conditions ...Condition) (*ORM[T], error) {
+	if len(conditions) == 0 {
+		return r, nil
+	}
+	repo, ok := r.Repo.(ScopedRepository[T])
+	if !ok {
+		return nil, errors.ConditionsUnsupported
+	}
+	return NewORM(repo.Scoped(conditions...)), nil
+}
+
+// Matches reports whether an entity, already loaded in memory, satisfies the condition.
+// Field is resolved like the repositories do: the struct field name, its snake case column name or its json or bson key.
+// Values are compared by their text representation, an unknown field never matches.
+func (c Condition) Matches(object any) bool {
+	value, ok := conditionField(reflect.ValueOf(object), c.Field)
+	if !ok {
+		return false
+	}
+	actual := fmt.Sprint(value.Interface())
+	switch c.Operator {
+	case OperatorNotEqual:
+		return actual != fmt.Sprint(c.Value)
+	case OperatorIn:
+		values := reflect.ValueOf(c.Value)
+		if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+			return false
+		}
+		for i := 0; i < values.Len(); i++ {
+			if actual == fmt.Sprint(values.Index(i).Interface()) {
+				return true
+			}
+		}
+		return false
+	}
+	return actual == fmt.Sprint(c.Value)
+}
+
+// conditionField returns the field of a struct a condition refers to, embedded structs included
+func conditionField(value reflect.Value, name string) (reflect.Value, bool) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous {
+			if found, ok := conditionField(value.Field(i), name); ok {
+				return found, true
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		json, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		bson, _, _ := strings.Cut(field.Tag.Get("bson"), ",")
+		if field.Name == name || snakeCase(field.Name) == name || json == name || bson == name {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// snakeCase returns the default column name of a struct field, such as tenant_id for TenantID
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,23 @@
+package orm
+
+import (
+	"context"
+
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// ContextualRepository is implemented by repositories able to run every operation with a context
+// WithContext returns a repository whose operations use ctx, so that database callbacks can read request values
+type ContextualRepository[E entity.Entity] interface {
+	WithContext(ctx context.Context) RestRepository[entity.DatabaseModel[E], E]
+}
+
+// WithContext returns an ORM whose operations run with ctx.
+// Repositories unable to use a context are returned as is, a context only carries request values and deadlines.
+func (r *ORM[T]) WithContext(ctx context.Context) *ORM[T] {
+	repo, ok := r.Repo.(ContextualRepository[T])
+	if !ok {
+		return r
+	}
+	return NewORM(repo.WithContext(ctx))
+}
@@ -21,6 +21,11 @@ func (e BaseEntity) GetId() ID {
 	return e.Id
 }
 
+// GetIdentifier returns the entity's ID as an Identifier.
+func (e BaseEntity) GetIdentifier() Identifier {
+	return e.Id
+}
+
 // SetId sets the entity's ID and returns the entity.
 func (e BaseEntity) SetId(id any) Entity {
 	e.Id = CastId(id)
@@ -0,0 +1,176 @@
+package entity
+
+import (
+	"net/url"
+	"strings"
+)
+
+// CompositeSeparator separates the parts of a composite identifier in its string form
+// parts containing it (or a comma, which separates batch ids) are percent-encoded
+const CompositeSeparator = ":"
+
+var compositeEscaper = strings.NewReplacer("%", "%25", CompositeSeparator, "%3A", ",", "%2C")
+
+// CompositeIdentifier is implemented by identifiers made of several parts, for composite primary keys
+// Keys names every part in order, the names are used as route parameters and as database columns or fields
+type CompositeIdentifier interface {
+	Identifier
+	Keys() []string
+	Parts() []Identifier
+}
+
+// KeyPart describes one part of a composite key, Type is the zero value of the part's identifier type
+type KeyPart struct {
+	Name string
+	Type Identifier
+}
+
+// CompositeKey describes the parts of a CompositeID, it is usually implemented by an empty struct
+type CompositeKey interface {
+	Parts() []KeyPart
+}
+
+// CompositeID is a CompositeIdentifier whose parts are described by K
+// It holds its string form, which keeps it comparable
+type CompositeID[K CompositeKey] struct {
+	value string
+}
+
+// NewCompositeID builds a composite identifier from its parts, in the order described by K.
+// It is meant for GetIdentifier, whose parts are the key fields. With a wrong number of parts the identifier is invalid:
+// its parts are null and its string form fails to parse, so requests using it are rejected as bad requests.
+// Use MakeCompositeID to check the parts.
+func NewCompositeID[K CompositeKey](parts ...Identifier) CompositeID[K] {
+	values := make([]string, len(parts))
+	for i, part := range parts {
+		values[i] = part.String()
+	}
+	return CompositeID[K]{value: JoinCompositeId(values...)}
+}
+
+// MakeCompositeID builds a composite identifier from its parts, in the order described by K.
+// It returns an InvalidIdentifierError when the number of parts does not match K.
+func MakeCompositeID[K CompositeKey](parts ...Identifier) (CompositeID[K], error) {
+	var key K
+	if len(parts) != len(key.Parts()) {
+		return CompositeID[K]{}, InvalidIdentifierError{Value: NewCompositeID[K](parts...).String(), Type: "composite"}
+	}
+	return NewCompositeID[K](parts...), nil
+}
+
+// JoinCompositeId returns the string form of a composite identifier made of the given parts.
+func JoinCompositeId(parts ...string) string {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = compositeEscaper.Replace(part)
+	}
+	return strings.Join(escaped, CompositeSeparator)
+}
+
+// SplitCompositeId returns the parts of the string form of a composite identifier.
+func SplitCompositeId(value string) ([]string, error) {
+	parts := strings.Split(value, CompositeSeparator)
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = unescaped
+	}
+	return parts, nil
+}
+
+// Keys returns the name of every part.
+func (c CompositeID[K]) Keys() []string {
+	var key K
+	definition := key.Parts()
+	keys := make([]string, len(definition))
+	for i, part := range definition {
+		keys[i] = part.Name
+	}
+	return keys
+}
+
+// Parts returns every part as its own identifier type, the zero value returns null parts.
+func (c CompositeID[K]) Parts() []Identifier {
+	var key K
+	definition := key.Parts()
+	parts := make([]Identifier, len(definition))
+	values, err := SplitCompositeId(c.value)
+	if c.value == "" || err != nil || len(values) != len(definition) {
+		for i, part := range definition {
+			parts[i] = part.Type
+		}
+		return parts
+	}
+	for i, part := range definition {
+		parts[i], _ = part.Type.Parse(values[i])
+	}
+	return parts
+}
+
+// Part returns the i-th part.
+func (c CompositeID[K]) Part(i int) Identifier {
+	return c.Parts()[i]
+}
+
+// String returns the parts joined by CompositeSeparator.
+func (c CompositeID[K]) String() string {
+	if c.value == "" {
+		values := make([]string, 0)
+		for _, part := range c.Parts() {
+			values = append(values, part.String())
+		}
+		return JoinCompositeId(values...)
+	}
+	return c.value
+}
+
+// IsNull reports whether every part is null, an invalid identifier is not null.
+func (c CompositeID[K]) IsNull() bool {
+	if _, err := c.Parse(c.value); c.value != "" && err != nil {
+		return false
+	}
+	for _, part := range c.Parts() {
+		if !part.IsNull() {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse parses the string form of the identifier, every part is parsed by its own type, it implements Identifier.
+func (c CompositeID[K]) Parse(value string) (Identifier, error) {
+	var key K
+	definition := key.Parts()
+	values, err := SplitCompositeId(value)
+	if err != nil || len(values) != len(definition) {
+		return CompositeID[K]{}, InvalidIdentifierError{Value: value, Type: "composite"}
+	}
+	parts := make([]Identifier, len(definition))
+	for i, part := range definition {
+		if parts[i], err = part.Type.Parse(values[i]); err != nil {
+			return CompositeID[K]{}, InvalidIdentifierError{Value: value, Type: "composite"}
+		}
+	}
+	return MakeCompositeID[K](parts...)
+}
+
+// MarshalText encodes the identifier in its string form, it is used by JSON and XML.
+func (c CompositeID[K]) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText decodes the identifier from its string form.
+func (c *CompositeID[K]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = CompositeID[K]{}
+		return nil
+	}
+	parsed, err := c.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed.(CompositeID[K])
+	return nil
+}
@@ -1,7 +1,8 @@
 package entity
 
 // Entity is an interface that represents a database entity
+// GetIdentifier returns the primary key, whatever its type (ID, UUID, ULID, StringID or your own Identifier)
 type Entity interface {
 	SetId(any) Entity
-	GetId() ID
+	GetIdentifier() Identifier
 }
@@ -5,6 +5,7 @@ import (
 )
 
 // ID is a type that represents an entity's primary identifier
+// It is the default numeric Identifier, see UUID, ULID and StringID for the others
 type ID uint
 
 // NullId represents a null or unset ID value.
@@ -15,6 +16,25 @@ func (e ID) String() string {
 	return strconv.FormatUint(uint64(e), 10)
 }
 
+// IsNull reports whether the ID is unset.
+func (e ID) IsNull() bool {
+	return e == NullId
+}
+
+// Parse parses a numeric ID, it implements Identifier.
+func (e ID) Parse(value string) (Identifier, error) {
+	return ParseId(value)
+}
+
+// ParseId parses a numeric ID, returning an error instead of NullId if the value is not a positive integer.
+func ParseId(value string) (ID, error) {
+	convertedID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return NullId, InvalidIdentifierError{Value: value, Type: "numeric"}
+	}
+	return ID(convertedID), nil
+}
+
 // CastId converts various types to an ID, returning NullId if conversion fails.
 func CastId(id any) ID {
 	switch v := id.(type) {
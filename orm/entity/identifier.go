@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"fmt"
+)
+
+// Identifier is implemented by every type that can be used as an entity primary key
+// String must return the form used in urls, Parse must accept it back
+// Parse is called on the zero value of the identifier type, identifiers must therefore be value types
+type Identifier interface {
+	String() string
+	IsNull() bool
+	Parse(string) (Identifier, error)
+}
+
+// InvalidIdentifierError is returned when a string cannot be parsed as an identifier
+type InvalidIdentifierError struct {
+	Value string
+	Type  string
+}
+
+func (e InvalidIdentifierError) Error() string {
+	return fmt.Sprintf("invalid %s identifier: %q", e.Type, e.Value)
+}
+
+// CastAs converts id to the identifier type I, returning the null identifier of I if conversion fails
+// It is meant to implement SetId for entities that do not use ID
+func CastAs[I Identifier](id any) I {
+	switch v := id.(type) {
+	case I:
+		return v
+	case string:
+		return parseAs[I](v)
+	case fmt.Stringer:
+		return parseAs[I](v.String())
+	}
+	return parseAs[I](fmt.Sprint(id))
+}
+
+func parseAs[I Identifier](value string) I {
+	var zero I
+	parsed, err := zero.Parse(value)
+	if err != nil {
+		return zero
+	}
+	if casted, ok := parsed.(I); ok {
+		return casted
+	}
+	return zero
+}
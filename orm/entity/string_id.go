@@ -0,0 +1,25 @@
+package entity
+
+// StringID is an identifier for natural string keys (slugs, codes, external references...)
+type StringID string
+
+// NullStringID represents a null or unset StringID value.
+const NullStringID StringID = ""
+
+// String returns the key itself.
+func (s StringID) String() string {
+	return string(s)
+}
+
+// IsNull reports whether the key is empty.
+func (s StringID) IsNull() bool {
+	return s == NullStringID
+}
+
+// Parse accepts any non empty key, it implements Identifier.
+func (s StringID) Parse(value string) (Identifier, error) {
+	if value == "" {
+		return NullStringID, InvalidIdentifierError{Value: value, Type: "string"}
+	}
+	return StringID(value), nil
+}
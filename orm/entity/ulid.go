@@ -0,0 +1,154 @@
+package entity
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ULID is a lexicographically sortable identifier: a millisecond timestamp followed by 80 random bits
+// It is stored and exchanged in its 26 characters Crockford base32 form
+type ULID [16]byte
+
+// NullULID represents a null or unset ULID value.
+var NullULID ULID
+
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var ulidDecoding = func() [256]byte {
+	var table [256]byte
+	for i := range table {
+		table[i] = 0xff
+	}
+	for i, c := range ulidAlphabet {
+		table[c] = byte(i)
+		table[strings.ToLower(string(c))[0]] = byte(i)
+	}
+	return table
+}()
+
+// NewULID generates a ULID for the current time.
+func NewULID() ULID {
+	return NewULIDAt(time.Now())
+}
+
+// NewULIDAt generates a ULID for the given time.
+func NewULIDAt(t time.Time) ULID {
+	var u ULID
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(t.UnixMilli()))
+	copy(u[0:6], timestamp[2:])
+	if _, err := rand.Read(u[6:]); err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// ParseULID parses a ULID from its base32 form, case insensitively.
+func ParseULID(value string) (ULID, error) {
+	var u ULID
+	if len(value) != 26 || ulidDecoding[value[0]] > 7 {
+		return NullULID, InvalidIdentifierError{Value: value, Type: "ulid"}
+	}
+	//26 characters of 5 bits hold 130 bits, the first character only carries 3 of them
+	var acc uint64
+	var bits uint
+	index := 15
+	for i := len(value) - 1; i >= 0; i-- {
+		decoded := ulidDecoding[value[i]]
+		if decoded == 0xff {
+			return NullULID, InvalidIdentifierError{Value: value, Type: "ulid"}
+		}
+		acc |= uint64(decoded) << bits
+		bits += 5
+		for bits >= 8 && index >= 0 {
+			u[index] = byte(acc)
+			acc >>= 8
+			bits -= 8
+			index--
+		}
+	}
+	return u, nil
+}
+
+// String returns the base32 form of the ULID.
+func (u ULID) String() string {
+	var buf [26]byte
+	var acc uint64
+	var bits uint
+	position := 25
+	for i := 15; i >= 0; i-- {
+		acc |= uint64(u[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			buf[position] = ulidAlphabet[acc&0x1f]
+			acc >>= 5
+			bits -= 5
+			position--
+		}
+	}
+	buf[0] = ulidAlphabet[acc&0x1f]
+	return string(buf[:])
+}
+
+// Time returns the timestamp encoded in the ULID.
+func (u ULID) Time() time.Time {
+	var timestamp [8]byte
+	copy(timestamp[2:], u[0:6])
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(timestamp[:])))
+}
+
+// IsNull reports whether the ULID is unset.
+func (u ULID) IsNull() bool {
+	return u == NullULID
+}
+
+// Parse parses a ULID, it implements Identifier.
+func (u ULID) Parse(value string) (Identifier, error) {
+	return ParseULID(value)
+}
+
+// MarshalText encodes the ULID in its base32 form, it is used by JSON and XML.
+func (u ULID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText decodes a ULID from its base32 form, an empty value is the null ULID.
+func (u *ULID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*u = NullULID
+		return nil
+	}
+	parsed, err := ParseULID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// Value stores the ULID as text, which keeps its ordering.
+func (u ULID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// Scan reads a ULID stored as text or as 16 raw bytes.
+func (u *ULID) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*u = NullULID
+		return nil
+	case string:
+		return u.UnmarshalText([]byte(v))
+	case []byte:
+		if len(v) == 16 {
+			copy(u[:], v)
+			return nil
+		}
+		return u.UnmarshalText(v)
+	}
+	return fmt.Errorf("cannot scan %T into ULID", src)
+}
@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+)
+
+// UUID is an RFC 4122 identifier, stored and exchanged in its canonical textual form
+type UUID [16]byte
+
+// NullUUID represents a null or unset UUID value.
+var NullUUID UUID
+
+// NewUUID generates a random (version 4) UUID.
+func NewUUID() UUID {
+	var u UUID
+	if _, err := rand.Read(u[:]); err != nil {
+		panic(err)
+	}
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return u
+}
+
+// ParseUUID parses a UUID in its canonical form (with or without dashes).
+func ParseUUID(value string) (UUID, error) {
+	var u UUID
+	var raw string
+	switch len(value) {
+	case 36:
+		if value[8] != '-' || value[13] != '-' || value[18] != '-' || value[23] != '-' {
+			return NullUUID, InvalidIdentifierError{Value: value, Type: "uuid"}
+		}
+		raw = value[0:8] + value[9:13] + value[14:18] + value[19:23] + value[24:]
+	case 32:
+		raw = value
+	default:
+		return NullUUID, InvalidIdentifierError{Value: value, Type: "uuid"}
+	}
+	if _, err := hex.Decode(u[:], []byte(raw)); err != nil {
+		return NullUUID, InvalidIdentifierError{Value: value, Type: "uuid"}
+	}
+	return u, nil
+}
+
+// String returns the canonical textual form of the UUID.
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// IsNull reports whether the UUID is unset.
+func (u UUID) IsNull() bool {
+	return u == NullUUID
+}
+
+// Parse parses a UUID, it implements Identifier.
+func (u UUID) Parse(value string) (Identifier, error) {
+	return ParseUUID(value)
+}
+
+// MarshalText encodes the UUID in its canonical form, it is used by JSON and XML.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText decodes a UUID from its canonical form, an empty value is the null UUID.
+func (u *UUID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*u = NullUUID
+		return nil
+	}
+	parsed, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// Value stores the UUID as text.
+func (u UUID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// Scan reads a UUID stored as text or as 16 raw bytes.
+func (u *UUID) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*u = NullUUID
+		return nil
+	case string:
+		return u.UnmarshalText([]byte(v))
+	case []byte:
+		if len(v) == 16 {
+			copy(u[:], v)
+			return nil
+		}
+		return u.UnmarshalText(v)
+	}
+	return fmt.Errorf("cannot scan %T into UUID", src)
+}
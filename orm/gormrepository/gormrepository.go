@@ -6,6 +6,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/philiphil/restman/orm"
 	"github.com/philiphil/restman/orm/entity"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
@@ -24,6 +25,8 @@ type GormRepository[M entity.DatabaseModel[E], E entity.Entity] struct {
 	assocationsLoaded  bool
 	preloadAssocations bool
 	associations       []string
+	conditions         []orm.Condition
+	ctx                context.Context
 }
 
 // EnablePreloadAssociations enables automatic preloading of entity associations.
@@ -61,7 +64,7 @@ func (r *GormRepository[M, E]) Insert(ctx context.Context, entity *E) error {
 	var start M
 	model := start.FromEntity(*entity).(M)
 
-	err := r.db.WithContext(ctx).Create(&model).Error
+	err := r.session(ctx).Create(&model).Error
 	if err != nil {
 		return err
 	}
@@ -71,22 +74,19 @@ func (r *GormRepository[M, E]) Insert(ctx context.Context, entity *E) error {
 }
 
 // DeleteByID removes an entity from the database by its ID.
-func (r *GormRepository[M, E]) DeleteByID(ctx context.Context, id entity.ID) error {
-	var start M
-	err := r.db.WithContext(ctx).Delete(&start, &id).Error
-	if err != nil {
-		return err
-	}
-
-	return nil
+func (r *GormRepository[M, E]) DeleteByID(ctx context.Context, id entity.Identifier) error {
+	return r.DeleteByIDs(ctx, []entity.Identifier{id})
 }
 
 // Upsert creates or updates an entity in the database.
 func (r *GormRepository[M, E]) Upsert(ctx context.Context, entity *E) error {
+	if err := r.checkScope(ctx, identifiersOf([]*E{entity})); err != nil {
+		return err
+	}
 	var start M
 	model := start.FromEntity(*entity).(M)
 
-	err := r.db.WithContext(ctx).Save(&model).Error
+	err := r.session(ctx).Save(&model).Error
 	if err != nil {
 		return err
 	}
@@ -96,10 +96,14 @@ func (r *GormRepository[M, E]) Upsert(ctx context.Context, entity *E) error {
 }
 
 // FindByID retrieves an entity by its ID.
-func (r *GormRepository[M, E]) FindByID(ctx context.Context, id entity.ID) (E, error) {
+func (r *GormRepository[M, E]) FindByID(ctx context.Context, id entity.Identifier) (E, error) {
 	var model M
 
-	err := r.getPreWarmDbForSelect(ctx).First(&model, id).Error
+	condition, err := r.idsCondition([]entity.Identifier{id})
+	if err != nil {
+		return *new(E), err
+	}
+	err = r.getPreWarmDbForSelect(ctx).Where(condition).First(&model).Error
 	if err != nil {
 		return *new(E), err
 	}
@@ -113,7 +117,7 @@ func (r *GormRepository[M, E]) Find(ctx context.Context, specifications ...Speci
 }
 
 func (r *GormRepository[M, E]) getPreWarmDbForSelect(ctx context.Context, specification ...Specification) *gorm.DB {
-	dbPrewarm := r.db.WithContext(ctx)
+	dbPrewarm := r.scoped(ctx)
 
 	for _, s := range specification {
 		switch spec := s.(type) {
@@ -162,9 +166,13 @@ func (r *GormRepository[M, E]) FindAll(ctx context.Context, specification ...Spe
 }
 
 // FindByIDs retrieves multiple entities by their IDs.
-func (r *GormRepository[M, E]) FindByIDs(ctx context.Context, ids []entity.ID) ([]*E, error) {
+func (r *GormRepository[M, E]) FindByIDs(ctx context.Context, ids []entity.Identifier) ([]*E, error) {
+	condition, err := r.idsCondition(ids)
+	if err != nil {
+		return nil, err
+	}
 	var models []M
-	err := r.db.WithContext(ctx).Find(&models, ids).Error
+	err = r.scoped(ctx).Where(condition).Find(&models).Error
 	if err != nil {
 		return nil, err
 	}
@@ -178,9 +186,13 @@ func (r *GormRepository[M, E]) FindByIDs(ctx context.Context, ids []entity.ID) (
 }
 
 // DeleteByIDs removes multiple entities by their IDs.
-func (r *GormRepository[M, E]) DeleteByIDs(ctx context.Context, ids []entity.ID) error {
+func (r *GormRepository[M, E]) DeleteByIDs(ctx context.Context, ids []entity.Identifier) error {
+	condition, err := r.idsCondition(ids)
+	if err != nil {
+		return err
+	}
 	var start M
-	err := r.db.WithContext(ctx).Delete(&start, ids).Error
+	err = r.scoped(ctx).Where(condition).Delete(&start).Error
 	if err != nil {
 		return err
 	}
@@ -189,15 +201,14 @@ func (r *GormRepository[M, E]) DeleteByIDs(ctx context.Context, ids []entity.ID)
 
 // BatchDelete removes multiple entities in a single operation.
 func (r *GormRepository[M, E]) BatchDelete(ctx context.Context, entities []*E) error {
-	ids := make([]entity.ID, 0, len(entities))
-	for _, entity := range entities {
-		ids = append(ids, (*entity).GetId())
-	}
-	return r.DeleteByIDs(ctx, ids)
+	return r.DeleteByIDs(ctx, identifiersOf(entities))
 }
 
 // BatchUpdate updates multiple entities in a transaction.
 func (r *GormRepository[M, E]) BatchUpdate(ctx context.Context, entities []*E) error {
+	if err := r.checkScope(ctx, identifiersOf(entities)); err != nil {
+		return err
+	}
 	var models []M
 	for _, entity := range entities {
 		var start M
@@ -205,7 +216,7 @@ func (r *GormRepository[M, E]) BatchUpdate(ctx context.Context, entities []*E) e
 		models = append(models, model)
 	}
 
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.session(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Save(&models).Error; err != nil {
 			return err
 		}
@@ -225,7 +236,7 @@ func (r *GormRepository[M, E]) BatchInsert(ctx context.Context, entities []*E) e
 		models = append(models, model)
 	}
 
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.session(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(&models).Error; err != nil {
 			return err
 		}
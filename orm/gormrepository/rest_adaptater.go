@@ -3,27 +3,43 @@ package gormrepository
 import (
 	"context"
 
+	"github.com/philiphil/restman/orm"
 	"github.com/philiphil/restman/orm/entity"
 )
 
+// WithContext returns a copy of the repository running the RestRepository operations with ctx, it implements orm.ContextualRepository.
+func (r *GormRepository[M, E]) WithContext(ctx context.Context) orm.RestRepository[entity.DatabaseModel[E], E] {
+	contextual := *r
+	contextual.ctx = ctx
+	return &contextual
+}
+
+// context returns the context of the RestRepository operations
+func (r *GormRepository[M, E]) context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
 // Create implements RestRepository.Create by inserting entities.
 func (r *GormRepository[M, E]) Create(entities []*E) error {
-	return r.BatchInsert(context.Background(), entities)
+	return r.BatchInsert(r.context(), entities)
 }
 
 // Update implements RestRepository.Update by updating entities.
 func (r *GormRepository[M, E]) Update(entities []*E) error {
-	return r.BatchUpdate(context.Background(), entities)
+	return r.BatchUpdate(r.context(), entities)
 }
 
 // Read implements RestRepository.Read by finding entities by IDs.
-func (r *GormRepository[M, E]) Read(ids []entity.ID) ([]*E, error) {
-	return r.FindByIDs(context.Background(), ids)
+func (r *GormRepository[M, E]) Read(ids []entity.Identifier) ([]*E, error) {
+	return r.FindByIDs(r.context(), ids)
 }
 
 // Delete implements RestRepository.Delete by deleting entities.
 func (r *GormRepository[M, E]) Delete(entities []*E) error {
-	return r.BatchDelete(context.Background(), entities)
+	return r.BatchDelete(r.context(), entities)
 }
 
 // List implements RestRepository.List by finding entities with pagination and sorting.
@@ -32,7 +48,7 @@ func (r *GormRepository[M, E]) List(limit int, offset int, order map[string]stri
 	for k, v := range order {
 		orderSpecification = append(orderSpecification, OrderBy(k, v))
 	}
-	return r.FindWithLimit(context.Background(), limit, offset, orderSpecification...)
+	return r.FindWithLimit(r.context(), limit, offset, orderSpecification...)
 }
 
 // New implements RestRepository.New by creating a new entity instance.
@@ -43,6 +59,35 @@ func (r *GormRepository[M, E]) New() E {
 // Count implements RestRepository.Count by returning the total number of entities.
 func (r *GormRepository[M, E]) Count() (i int64, err error) {
 	model := new(M)
-	err = r.getPreWarmDbForSelect(context.TODO()).Model(model).Count(&i).Error
+	err = r.getPreWarmDbForSelect(r.context()).Model(model).Count(&i).Error
 	return
 }
+
+// ListDeleted implements SoftDeleteRepository.ListDeleted by finding soft deleted entities with pagination and sorting.
+func (r *GormRepository[M, E]) ListDeleted(limit int, offset int, order map[string]string) ([]E, error) {
+	orderSpecification := make([]Specification, 0, len(order))
+	for k, v := range order {
+		orderSpecification = append(orderSpecification, OrderBy(k, v))
+	}
+	return r.FindDeletedWithLimit(r.context(), limit, offset, orderSpecification...)
+}
+
+// CountDeleted implements SoftDeleteRepository.CountDeleted by returning the number of soft deleted entities.
+func (r *GormRepository[M, E]) CountDeleted() (int64, error) {
+	return r.CountDeletedWithSpecifications(r.context())
+}
+
+// ReadDeleted implements SoftDeleteRepository.ReadDeleted by finding soft deleted entities by IDs.
+func (r *GormRepository[M, E]) ReadDeleted(ids []entity.Identifier) ([]*E, error) {
+	return r.FindDeletedByIDs(r.context(), ids)
+}
+
+// Restore implements SoftDeleteRepository.Restore by restoring soft deleted entities.
+func (r *GormRepository[M, E]) Restore(entities []*E) error {
+	return r.BatchRestore(r.context(), entities)
+}
+
+// Purge implements SoftDeleteRepository.Purge by permanently deleting entities.
+func (r *GormRepository[M, E]) Purge(entities []*E) error {
+	return r.BatchPurge(r.context(), entities)
+}
@@ -0,0 +1,116 @@
+package gormrepository
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sync"
+
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	schemaCache   = &sync.Map{}
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+func (r *GormRepository[M, E]) parseSchema() (*schema.Schema, error) {
+	var model M
+	return schema.Parse(&model, schemaCache, r.db.NamingStrategy)
+}
+
+// softDeleteColumn returns the column of the gorm.DeletedAt field of the model
+// models without such a field are hard deleted by gorm and cannot be restored
+func (r *GormRepository[M, E]) softDeleteColumn() (string, error) {
+	sc, err := r.parseSchema()
+	if err != nil {
+		return "", err
+	}
+	for _, field := range sc.Fields {
+		if field.FieldType == deletedAtType && field.DBName != "" {
+			return field.DBName, nil
+		}
+	}
+	return "", errors.SoftDeleteUnsupported
+}
+
+func (r *GormRepository[M, E]) primaryKeyColumn() (string, error) {
+	sc, err := r.parseSchema()
+	if err != nil {
+		return "", err
+	}
+	if sc.PrioritizedPrimaryField == nil {
+		return "", gorm.ErrPrimaryKeyRequired
+	}
+	return sc.PrioritizedPrimaryField.DBName, nil
+}
+
+// idsCondition matches the primary key of the model against the given identifiers
+// composite identifiers match every key part against the column of the same name
+func (r *GormRepository[M, E]) idsCondition(ids []entity.Identifier) (clause.Expression, error) {
+	if len(ids) > 0 {
+		if _, ok := ids[0].(entity.CompositeIdentifier); ok {
+			return r.compositeIdsCondition(ids)
+		}
+	}
+	primaryKey, err := r.primaryKeyColumn()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, identifierValue(id))
+	}
+	return clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: primaryKey}, Values: values}, nil
+}
+
+func (r *GormRepository[M, E]) compositeIdsCondition(ids []entity.Identifier) (clause.Expression, error) {
+	sc, err := r.parseSchema()
+	if err != nil {
+		return nil, err
+	}
+	conditions := make([]clause.Expression, 0, len(ids))
+	for _, id := range ids {
+		composite, ok := id.(entity.CompositeIdentifier)
+		if !ok {
+			return nil, errors.InvalidIdentifier
+		}
+		keys, parts := composite.Keys(), composite.Parts()
+		equalities := make([]clause.Expression, 0, len(keys))
+		for i, key := range keys {
+			column := key
+			if field := sc.LookUpField(key); field != nil {
+				column = field.DBName
+			}
+			equalities = append(equalities, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: identifierValue(parts[i])})
+		}
+		conditions = append(conditions, clause.And(equalities...))
+	}
+	return clause.Or(conditions...), nil
+}
+
+// identifierValue returns the value the database driver compares the primary key column with
+// identifiers that are not driver.Valuer are compared as their string form
+func identifierValue(id entity.Identifier) any {
+	switch v := id.(type) {
+	case driver.Valuer:
+		return v
+	case entity.ID:
+		return uint(v)
+	case entity.StringID:
+		return string(v)
+	}
+	return id.String()
+}
+
+// identifiersOf returns the identifiers of the entities
+func identifiersOf[E entity.Entity](entities []*E) []entity.Identifier {
+	ids := make([]entity.Identifier, 0, len(entities))
+	for _, e := range entities {
+		ids = append(ids, (*e).GetIdentifier())
+	}
+	return ids
+}
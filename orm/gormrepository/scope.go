@@ -0,0 +1,89 @@
+package gormrepository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Scoped returns a copy of the repository restricted by the conditions, it implements orm.ScopedRepository.
+// Reads, counts, lookups and deletions only see the matching rows, updates of rows outside of the scope fail with errors.OutOfScope.
+func (r *GormRepository[M, E]) Scoped(conditions ...orm.Condition) orm.RestRepository[entity.DatabaseModel[E], E] {
+	scoped := *r
+	scoped.conditions = append(r.conditions[:len(r.conditions):len(r.conditions)], conditions...)
+	return &scoped
+}
+
+// scoped returns the database connection restricted by the conditions of the repository
+func (r *GormRepository[M, E]) scoped(ctx context.Context) *gorm.DB {
+	db := r.session(ctx)
+	if len(r.conditions) == 0 {
+		return db
+	}
+	sc, err := r.parseSchema()
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+	for _, condition := range r.conditions {
+		column := condition.Field
+		if field := sc.LookUpField(condition.Field); field != nil {
+			column = field.DBName
+		}
+		db = db.Where(conditionExpression(clause.Column{Table: clause.CurrentTable, Name: column}, condition))
+	}
+	return db
+}
+
+func conditionExpression(column clause.Column, condition orm.Condition) clause.Expression {
+	switch condition.Operator {
+	case orm.OperatorNotEqual:
+		return clause.Neq{Column: column, Value: condition.Value}
+	case orm.OperatorIn:
+		return clause.IN{Column: column, Values: toValues(condition.Value)}
+	}
+	return clause.Eq{Column: column, Value: condition.Value}
+}
+
+// checkScope fails if one of the ids belongs to a row outside of the repository scope, soft deleted rows included
+func (r *GormRepository[M, E]) checkScope(ctx context.Context, ids []entity.Identifier) error {
+	if len(r.conditions) == 0 || len(ids) == 0 {
+		return nil
+	}
+	condition, err := r.idsCondition(ids)
+	if err != nil {
+		return err
+	}
+	var all, inScope int64
+	if err := r.session(ctx).Unscoped().Model(new(M)).Where(condition).Count(&all).Error; err != nil {
+		return err
+	}
+	if err := r.scoped(ctx).Unscoped().Model(new(M)).Where(condition).Count(&inScope).Error; err != nil {
+		return err
+	}
+	if all != inScope {
+		return errors.OutOfScope
+	}
+	return nil
+}
+
+// toValues converts a slice of any type to the values of an IN clause
+func toValues(value any) []any {
+	if values, ok := value.([]any); ok {
+		return values
+	}
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() != reflect.Slice && reflected.Kind() != reflect.Array {
+		return []any{value}
+	}
+	values := make([]any, reflected.Len())
+	for i := range values {
+		values[i] = reflected.Index(i).Interface()
+	}
+	return values
+}
@@ -0,0 +1,110 @@
+package gormrepository
+
+import (
+	"context"
+
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// FindDeletedWithLimit retrieves soft deleted entities matching the provided specifications with pagination.
+func (r *GormRepository[M, E]) FindDeletedWithLimit(ctx context.Context, limit int, offset int, specifications ...Specification) ([]E, error) {
+	column, err := r.softDeleteColumn()
+	if err != nil {
+		return nil, err
+	}
+	var models []M
+	dbPrewarm := r.getPreWarmDbForSelect(ctx, append(specifications, IsNotNull(column))...).Unscoped()
+	if err := dbPrewarm.Limit(limit).Offset(offset).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]E, 0, len(models))
+	for _, row := range models {
+		result = append(result, row.ToEntity())
+	}
+	return result, nil
+}
+
+// CountDeletedWithSpecifications returns the number of soft deleted entities matching the provided specifications.
+func (r *GormRepository[M, E]) CountDeletedWithSpecifications(ctx context.Context, specifications ...Specification) (i int64, err error) {
+	column, err := r.softDeleteColumn()
+	if err != nil {
+		return 0, err
+	}
+	model := new(M)
+	err = r.getPreWarmDbForSelect(ctx, append(specifications, IsNotNull(column))...).Unscoped().Model(model).Count(&i).Error
+	return
+}
+
+// FindDeletedByIDs retrieves multiple soft deleted entities by their IDs.
+func (r *GormRepository[M, E]) FindDeletedByIDs(ctx context.Context, ids []entity.Identifier) ([]*E, error) {
+	column, err := r.softDeleteColumn()
+	if err != nil {
+		return nil, err
+	}
+	condition, err := r.idsCondition(ids)
+	if err != nil {
+		return nil, err
+	}
+	var models []M
+	if err := r.scoped(ctx).Unscoped().Where(IsNotNull(column).GetQuery()).Where(condition).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*E, 0, len(models))
+	for _, row := range models {
+		entity := row.ToEntity()
+		result = append(result, &entity)
+	}
+	return result, nil
+}
+
+// RestoreByIDs clears the deletion mark of multiple entities by their IDs.
+func (r *GormRepository[M, E]) RestoreByIDs(ctx context.Context, ids []entity.Identifier) error {
+	column, err := r.softDeleteColumn()
+	if err != nil {
+		return err
+	}
+	condition, err := r.idsCondition(ids)
+	if err != nil {
+		return err
+	}
+	return r.scoped(ctx).Unscoped().Model(new(M)).Where(condition).Update(column, nil).Error
+}
+
+// PurgeByIDs permanently removes multiple entities by their IDs, whether they are soft deleted or not.
+func (r *GormRepository[M, E]) PurgeByIDs(ctx context.Context, ids []entity.Identifier) error {
+	condition, err := r.idsCondition(ids)
+	if err != nil {
+		return err
+	}
+	var start M
+	return r.scoped(ctx).Unscoped().Where(condition).Delete(&start).Error
+}
+
+// BatchRestore restores multiple soft deleted entities and reloads them.
+func (r *GormRepository[M, E]) BatchRestore(ctx context.Context, entities []*E) error {
+	ids := identifiersOf(entities)
+	if err := r.RestoreByIDs(ctx, ids); err != nil {
+		return err
+	}
+	restored, err := r.FindByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byId := make(map[string]*E, len(restored))
+	for _, e := range restored {
+		byId[(*e).GetIdentifier().String()] = e
+	}
+	for i := range entities {
+		if e, ok := byId[(*entities[i]).GetIdentifier().String()]; ok {
+			*entities[i] = *e
+		}
+	}
+	return nil
+}
+
+// BatchPurge permanently removes multiple entities in a single operation.
+func (r *GormRepository[M, E]) BatchPurge(ctx context.Context, entities []*E) error {
+	ids := identifiersOf(entities)
+	return r.PurgeByIDs(ctx, ids)
+}
@@ -0,0 +1,23 @@
+package gormrepository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type transactionKey struct{}
+
+// WithTransaction returns a copy of ctx carrying tx, the GormRepositories used with it run their operations in tx.
+// tx must be a transaction of the database of the repositories.
+func WithTransaction(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, transactionKey{}, tx)
+}
+
+// session returns the database connection of the operations run with ctx, the transaction it carries or the repository one
+func (r *GormRepository[M, E]) session(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(transactionKey{}).(*gorm.DB); ok && tx != nil {
+		return tx.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx)
+}
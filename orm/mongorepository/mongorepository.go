@@ -2,7 +2,9 @@ package mongorepository
 
 import (
 	"context"
+	"time"
 
+	"github.com/philiphil/restman/orm"
 	"github.com/philiphil/restman/orm/entity"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -18,7 +20,10 @@ func NewRepository[M entity.DatabaseModel[E], E entity.Entity](collection *mongo
 
 // MongoRepository is a MongoDB-based implementation of the RestRepository interface.
 type MongoRepository[M entity.DatabaseModel[E], E entity.Entity] struct {
-	collection *mongo.Collection
+	collection      *mongo.Collection
+	softDeleteField string
+	conditions      []orm.Condition
+	ctx             context.Context
 }
 
 // Insert creates a new entity in the MongoDB collection.
@@ -37,19 +42,23 @@ func (r *MongoRepository[M, E]) Insert(ctx context.Context, entity *E) error {
 }
 
 // DeleteByID removes an entity from the MongoDB collection by its ID.
-func (r *MongoRepository[M, E]) DeleteByID(ctx context.Context, id entity.ID) error {
-	filter := bson.M{"_id": id}
-	_, err := r.collection.DeleteOne(ctx, filter)
-	return err
+// When soft delete is enabled, the document is only marked as deleted.
+func (r *MongoRepository[M, E]) DeleteByID(ctx context.Context, id entity.Identifier) error {
+	return r.DeleteByIDs(ctx, []entity.Identifier{id})
 }
 
 // Upsert creates or updates an entity in the MongoDB collection.
+// The document is matched by its id alone, the scope being checked beforehand: a soft deleted document is replaced,
+// and so restored, instead of colliding with the insert of the upsert.
 func (r *MongoRepository[M, E]) Upsert(ctx context.Context, entity *E) error {
 	var start M
 	model := start.FromEntity(*entity).(M)
 
-	id := (*entity).GetId()
-	filter := bson.M{"_id": id}
+	id := (*entity).GetIdentifier()
+	if err := r.checkScope(ctx, idsOf(entity)); err != nil {
+		return err
+	}
+	filter := bson.M{"_id": idValue(id)}
 	opts := options.Replace().SetUpsert(true)
 
 	_, err := r.collection.ReplaceOne(ctx, filter, model, opts)
@@ -61,9 +70,9 @@ func (r *MongoRepository[M, E]) Upsert(ctx context.Context, entity *E) error {
 }
 
 // FindByID retrieves an entity by its ID from the MongoDB collection.
-func (r *MongoRepository[M, E]) FindByID(ctx context.Context, id entity.ID) (E, error) {
+func (r *MongoRepository[M, E]) FindByID(ctx context.Context, id entity.Identifier) (E, error) {
 	var model M
-	filter := bson.M{"_id": id}
+	filter := r.buildFilter(r.withNotDeleted([]Specification{Equal("_id", idValue(id))}))
 
 	err := r.collection.FindOne(ctx, filter).Decode(&model)
 	if err != nil {
@@ -78,6 +87,7 @@ func (r *MongoRepository[M, E]) Find(ctx context.Context, specifications ...Spec
 	return r.FindWithLimit(ctx, -1, -1, specifications...)
 }
 
+// buildFilter combines the specifications and the conditions of the repository scope
 func (r *MongoRepository[M, E]) buildFilter(specifications []Specification) bson.M {
 	filters := make([]bson.M, 0)
 
@@ -86,6 +96,9 @@ func (r *MongoRepository[M, E]) buildFilter(specifications []Specification) bson
 			filters = append(filters, filter)
 		}
 	}
+	for _, condition := range r.conditions {
+		filters = append(filters, r.conditionFilter(condition))
+	}
 
 	if len(filters) == 0 {
 		return bson.M{}
@@ -120,6 +133,10 @@ func (r *MongoRepository[M, E]) buildOptions(limit int, offset int, specificatio
 
 // FindWithLimit retrieves entities matching the provided specifications with pagination.
 func (r *MongoRepository[M, E]) FindWithLimit(ctx context.Context, limit int, offset int, specifications ...Specification) ([]E, error) {
+	return r.findWithLimit(ctx, limit, offset, r.withNotDeleted(specifications))
+}
+
+func (r *MongoRepository[M, E]) findWithLimit(ctx context.Context, limit int, offset int, specifications []Specification) ([]E, error) {
 	filter := r.buildFilter(specifications)
 	opts := r.buildOptions(limit, offset, specifications)
 
@@ -148,8 +165,12 @@ func (r *MongoRepository[M, E]) FindAll(ctx context.Context, specification ...Sp
 }
 
 // FindByIDs retrieves multiple entities by their IDs.
-func (r *MongoRepository[M, E]) FindByIDs(ctx context.Context, ids []entity.ID) ([]*E, error) {
-	filter := bson.M{"_id": bson.M{"$in": ids}}
+func (r *MongoRepository[M, E]) FindByIDs(ctx context.Context, ids []entity.Identifier) ([]*E, error) {
+	return r.findByIDs(ctx, r.withNotDeleted([]Specification{In("_id", idValues(ids))}))
+}
+
+func (r *MongoRepository[M, E]) findByIDs(ctx context.Context, specifications []Specification) ([]*E, error) {
+	filter := r.buildFilter(specifications)
 
 	cursor, err := r.collection.Find(ctx, filter)
 	if err != nil {
@@ -172,17 +193,23 @@ func (r *MongoRepository[M, E]) FindByIDs(ctx context.Context, ids []entity.ID)
 }
 
 // DeleteByIDs removes multiple entities by their IDs.
-func (r *MongoRepository[M, E]) DeleteByIDs(ctx context.Context, ids []entity.ID) error {
-	filter := bson.M{"_id": bson.M{"$in": ids}}
+// When soft delete is enabled, the documents are only marked as deleted.
+func (r *MongoRepository[M, E]) DeleteByIDs(ctx context.Context, ids []entity.Identifier) error {
+	if r.softDeleteField != "" {
+		filter := r.buildFilter(r.withNotDeleted([]Specification{In("_id", idValues(ids))}))
+		_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{r.softDeleteField: time.Now()}})
+		return err
+	}
+	filter := r.buildFilter([]Specification{In("_id", idValues(ids))})
 	_, err := r.collection.DeleteMany(ctx, filter)
 	return err
 }
 
 // BatchDelete removes multiple entities in a single operation.
 func (r *MongoRepository[M, E]) BatchDelete(ctx context.Context, entities []*E) error {
-	ids := make([]entity.ID, 0, len(entities))
+	ids := make([]entity.Identifier, 0, len(entities))
 	for _, entity := range entities {
-		ids = append(ids, (*entity).GetId())
+		ids = append(ids, (*entity).GetIdentifier())
 	}
 	return r.DeleteByIDs(ctx, ids)
 }
@@ -235,6 +262,6 @@ func (r *MongoRepository[M, E]) NewEntity() E {
 
 // CountWithSpecifications returns the number of entities matching the provided specifications.
 func (r *MongoRepository[M, E]) CountWithSpecifications(ctx context.Context, specifications ...Specification) (int64, error) {
-	filter := r.buildFilter(specifications)
+	filter := r.buildFilter(r.withNotDeleted(specifications))
 	return r.collection.CountDocuments(ctx, filter)
 }
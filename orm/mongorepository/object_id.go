@@ -0,0 +1,114 @@
+package mongorepository
+
+import (
+	"github.com/philiphil/restman/orm/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MongoIdentifier is implemented by identifiers that are not stored as their string form in _id
+type MongoIdentifier interface {
+	MongoId() any
+}
+
+// ObjectID is an entity.Identifier for documents keyed by a native MongoDB ObjectID
+// It is exchanged as its hexadecimal form and stored as an ObjectID
+type ObjectID primitive.ObjectID
+
+// NewObjectID generates a new ObjectID.
+func NewObjectID() ObjectID {
+	return ObjectID(primitive.NewObjectID())
+}
+
+// ParseObjectID parses an ObjectID from its hexadecimal form.
+func ParseObjectID(value string) (ObjectID, error) {
+	oid, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		return ObjectID(primitive.NilObjectID), entity.InvalidIdentifierError{Value: value, Type: "objectid"}
+	}
+	return ObjectID(oid), nil
+}
+
+// String returns the hexadecimal form of the ObjectID.
+func (o ObjectID) String() string {
+	return primitive.ObjectID(o).Hex()
+}
+
+// IsNull reports whether the ObjectID is unset.
+func (o ObjectID) IsNull() bool {
+	return primitive.ObjectID(o).IsZero()
+}
+
+// Parse parses an ObjectID, it implements entity.Identifier.
+func (o ObjectID) Parse(value string) (entity.Identifier, error) {
+	return ParseObjectID(value)
+}
+
+// MongoId returns the native ObjectID, it implements MongoIdentifier.
+func (o ObjectID) MongoId() any {
+	return primitive.ObjectID(o)
+}
+
+// MarshalText encodes the ObjectID in its hexadecimal form, it is used by JSON and XML.
+func (o ObjectID) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText decodes an ObjectID from its hexadecimal form, an empty value is the null ObjectID.
+func (o *ObjectID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*o = ObjectID(primitive.NilObjectID)
+		return nil
+	}
+	parsed, err := ParseObjectID(string(text))
+	if err != nil {
+		return err
+	}
+	*o = parsed
+	return nil
+}
+
+// MarshalBSONValue stores the ObjectID as a native ObjectID.
+func (o ObjectID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(primitive.ObjectID(o))
+}
+
+// UnmarshalBSONValue reads a native ObjectID.
+func (o *ObjectID) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	var oid primitive.ObjectID
+	if err := (bson.RawValue{Type: t, Value: data}).Unmarshal(&oid); err != nil {
+		return err
+	}
+	*o = ObjectID(oid)
+	return nil
+}
+
+// idValue returns the value stored in _id for the given identifier
+// composite identifiers are stored as a sub-document holding every part in key order
+func idValue(id entity.Identifier) any {
+	switch v := id.(type) {
+	case MongoIdentifier:
+		return v.MongoId()
+	case entity.CompositeIdentifier:
+		keys, parts := v.Keys(), v.Parts()
+		document := make(bson.D, 0, len(keys))
+		for i, key := range keys {
+			document = append(document, bson.E{Key: key, Value: idValue(parts[i])})
+		}
+		return document
+	case entity.ID:
+		return v
+	case entity.StringID:
+		return string(v)
+	}
+	return id.String()
+}
+
+func idValues(ids []entity.Identifier) []any {
+	values := make([]any, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, idValue(id))
+	}
+	return values
+}
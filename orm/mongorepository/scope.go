@@ -0,0 +1,81 @@
+package mongorepository
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Scoped returns a copy of the repository whose every filter also requires the conditions, it implements orm.ScopedRepository.
+// Upserts are filtered too, so a document outside of the scope can not be replaced.
+func (r *MongoRepository[M, E]) Scoped(conditions ...orm.Condition) orm.RestRepository[entity.DatabaseModel[E], E] {
+	scoped := *r
+	scoped.conditions = append(r.conditions[:len(r.conditions):len(r.conditions)], conditions...)
+	return &scoped
+}
+
+func (r *MongoRepository[M, E]) conditionFilter(condition orm.Condition) bson.M {
+	key := conditionKey[M](condition.Field)
+	switch condition.Operator {
+	case orm.OperatorNotEqual:
+		return bson.M{key: bson.M{"$ne": condition.Value}}
+	case orm.OperatorIn:
+		return bson.M{key: bson.M{"$in": condition.Value}}
+	}
+	return bson.M{key: condition.Value}
+}
+
+// conditionKey returns the document key of a condition field, struct field names of the model are converted to their bson key
+func conditionKey[M any](field string) string {
+	modelType := reflect.TypeOf(new(M)).Elem()
+	if modelType.Kind() != reflect.Struct {
+		return field
+	}
+	structField, ok := modelType.FieldByName(field)
+	if !ok {
+		return field
+	}
+	key, _, _ := strings.Cut(structField.Tag.Get("bson"), ",")
+	switch key {
+	case "":
+		return strings.ToLower(structField.Name)
+	case "-":
+		return field
+	}
+	return key
+}
+
+// checkScope fails if one of the ids belongs to a document outside of the repository scope, soft deleted ones included
+func (r *MongoRepository[M, E]) checkScope(ctx context.Context, ids []entity.Identifier) error {
+	if len(r.conditions) == 0 || len(ids) == 0 {
+		return nil
+	}
+	unscoped := *r
+	unscoped.conditions = nil
+	all, err := r.collection.CountDocuments(ctx, unscoped.buildFilter([]Specification{In("_id", idValues(ids))}))
+	if err != nil {
+		return err
+	}
+	inScope, err := r.collection.CountDocuments(ctx, r.buildFilter([]Specification{In("_id", idValues(ids))}))
+	if err != nil {
+		return err
+	}
+	if all != inScope {
+		return errors.OutOfScope
+	}
+	return nil
+}
+
+// idsOf returns the identifiers of the entities
+func idsOf[E entity.Entity](entities ...*E) []entity.Identifier {
+	ids := make([]entity.Identifier, 0, len(entities))
+	for _, e := range entities {
+		ids = append(ids, (*e).GetIdentifier())
+	}
+	return ids
+}
@@ -0,0 +1,105 @@
+package mongorepository
+
+import (
+	"context"
+
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// DefaultSoftDeleteField is the document field marking soft deleted documents.
+const DefaultSoftDeleteField = "deleted_at"
+
+// EnableSoftDelete makes deletions mark documents with the deletion time in field instead of removing them.
+// Marked documents are ignored by every read until they are restored.
+// An empty field uses DefaultSoftDeleteField.
+func (r *MongoRepository[M, E]) EnableSoftDelete(field string) *MongoRepository[M, E] {
+	if field == "" {
+		field = DefaultSoftDeleteField
+	}
+	r.softDeleteField = field
+	return r
+}
+
+// DisableSoftDelete makes deletions remove documents again.
+func (r *MongoRepository[M, E]) DisableSoftDelete() *MongoRepository[M, E] {
+	r.softDeleteField = ""
+	return r
+}
+
+// withNotDeleted appends the specification excluding soft deleted documents, if enabled
+func (r *MongoRepository[M, E]) withNotDeleted(specifications []Specification) []Specification {
+	if r.softDeleteField == "" {
+		return specifications
+	}
+	return append(specifications[:len(specifications):len(specifications)], IsNull(r.softDeleteField))
+}
+
+func (r *MongoRepository[M, E]) withDeleted(specifications []Specification) ([]Specification, error) {
+	if r.softDeleteField == "" {
+		return nil, errors.SoftDeleteUnsupported
+	}
+	return append(specifications[:len(specifications):len(specifications)], IsNotNull(r.softDeleteField)), nil
+}
+
+// FindDeletedWithLimit retrieves soft deleted entities matching the provided specifications with pagination.
+func (r *MongoRepository[M, E]) FindDeletedWithLimit(ctx context.Context, limit int, offset int, specifications ...Specification) ([]E, error) {
+	specifications, err := r.withDeleted(specifications)
+	if err != nil {
+		return nil, err
+	}
+	return r.findWithLimit(ctx, limit, offset, specifications)
+}
+
+// CountDeletedWithSpecifications returns the number of soft deleted entities matching the provided specifications.
+func (r *MongoRepository[M, E]) CountDeletedWithSpecifications(ctx context.Context, specifications ...Specification) (int64, error) {
+	specifications, err := r.withDeleted(specifications)
+	if err != nil {
+		return 0, err
+	}
+	return r.collection.CountDocuments(ctx, r.buildFilter(specifications))
+}
+
+// FindDeletedByIDs retrieves multiple soft deleted entities by their IDs.
+func (r *MongoRepository[M, E]) FindDeletedByIDs(ctx context.Context, ids []entity.Identifier) ([]*E, error) {
+	specifications, err := r.withDeleted([]Specification{In("_id", idValues(ids))})
+	if err != nil {
+		return nil, err
+	}
+	return r.findByIDs(ctx, specifications)
+}
+
+// RestoreByIDs clears the deletion mark of multiple documents by their IDs.
+func (r *MongoRepository[M, E]) RestoreByIDs(ctx context.Context, ids []entity.Identifier) error {
+	specifications, err := r.withDeleted([]Specification{In("_id", idValues(ids))})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.UpdateMany(ctx, r.buildFilter(specifications), bson.M{"$unset": bson.M{r.softDeleteField: ""}})
+	return err
+}
+
+// PurgeByIDs permanently removes multiple documents by their IDs, whether they are soft deleted or not.
+func (r *MongoRepository[M, E]) PurgeByIDs(ctx context.Context, ids []entity.Identifier) error {
+	_, err := r.collection.DeleteMany(ctx, r.buildFilter([]Specification{In("_id", idValues(ids))}))
+	return err
+}
+
+// BatchRestore restores multiple soft deleted entities.
+func (r *MongoRepository[M, E]) BatchRestore(ctx context.Context, entities []*E) error {
+	ids := make([]entity.Identifier, 0, len(entities))
+	for _, entity := range entities {
+		ids = append(ids, (*entity).GetIdentifier())
+	}
+	return r.RestoreByIDs(ctx, ids)
+}
+
+// BatchPurge permanently removes multiple entities in a single operation.
+func (r *MongoRepository[M, E]) BatchPurge(ctx context.Context, entities []*E) error {
+	ids := make([]entity.Identifier, 0, len(entities))
+	for _, entity := range entities {
+		ids = append(ids, (*entity).GetIdentifier())
+	}
+	return r.PurgeByIDs(ctx, ids)
+}
@@ -1,6 +1,8 @@
 package orm
 
 import (
+	"fmt"
+
 	"github.com/philiphil/restman/errors"
 	"github.com/philiphil/restman/orm/entity"
 )
@@ -24,9 +26,43 @@ func (r *ORM[T]) GetAll(sort map[string]string) ([]T, error) {
 	return r.Repo.List(-1, -1, sort)
 }
 
+// ParseId converts id to the identifier type of the entity.
+// Strings (like route parameters) are parsed strictly, an invalid one returns errors.InvalidIdentifier.
+func (r *ORM[T]) ParseId(id any) (entity.Identifier, error) {
+	if identifier, ok := id.(entity.Identifier); ok {
+		return identifier, nil
+	}
+	value, ok := id.(string)
+	if !ok {
+		value = fmt.Sprint(id)
+	}
+	identifier, err := r.NewEntity().GetIdentifier().Parse(value)
+	if err != nil {
+		return nil, errors.InvalidIdentifier
+	}
+	return identifier, nil
+}
+
+// ParseIds converts every id to the identifier type of the entity, see ParseId.
+func (r *ORM[T]) ParseIds(ids []string) ([]entity.Identifier, error) {
+	identifiers := make([]entity.Identifier, len(ids))
+	for i, id := range ids {
+		identifier, err := r.ParseId(id)
+		if err != nil {
+			return nil, err
+		}
+		identifiers[i] = identifier
+	}
+	return identifiers, nil
+}
+
 // GetByID retrieves a single entity by its ID.
 func (r *ORM[T]) GetByID(id any) (*T, error) {
-	elem, err := r.Repo.Read([]entity.ID{entity.CastId(id)})
+	identifier, err := r.ParseId(id)
+	if err != nil {
+		return nil, err
+	}
+	elem, err := r.Repo.Read([]entity.Identifier{identifier})
 
 	if err != nil {
 		return nil, err
@@ -63,7 +99,7 @@ func (r *ORM[T]) Delete(item ...*T) error {
 }
 
 // FindByIDs retrieves multiple entities by their IDs, returning an error if not all are found.
-func (r *ORM[T]) FindByIDs(ids []entity.ID) ([]*T, error) {
+func (r *ORM[T]) FindByIDs(ids []entity.Identifier) ([]*T, error) {
 	list, err := r.Repo.Read(ids)
 	if err != nil {
 		return nil, err
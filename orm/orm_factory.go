@@ -58,3 +58,7 @@ func (p *DefaultRepositoryProvider[T]) Provide() RestRepository[entity.DatabaseM
 	return repo
 }
 
+
+// TenantRepositoryProviders returns the RepositoryProvider of a tenant.
+// It routes every tenant to its own database, schema or collection.
+type TenantRepositoryProviders[T entity.Entity] func(tenant string) (RepositoryProvider[T], error)
@@ -8,7 +8,7 @@ import (
 // RestRepository defines the interface for database operations on entities.
 type RestRepository[M entity.DatabaseModel[E], E entity.Entity] interface {
 	Create(entities []*E) error
-	Read(ids []entity.ID) ([]*E, error)
+	Read(ids []entity.Identifier) ([]*E, error)
 	Update(entities []*E) error
 	Delete(entities []*E) error
 	List(limit int, offset int, order map[string]string) ([]E, error)
@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// SoftDeleteRepository is implemented by repositories that keep deleted entities around
+// it allows RestMan to list, restore and permanently purge them
+type SoftDeleteRepository[E entity.Entity] interface {
+	ListDeleted(limit int, offset int, order map[string]string) ([]E, error)
+	CountDeleted() (int64, error)
+	ReadDeleted(ids []entity.Identifier) ([]*E, error)
+	Restore(entities []*E) error
+	Purge(entities []*E) error
+}
+
+func (r *ORM[T]) softDeleteRepository() (SoftDeleteRepository[T], error) {
+	repo, ok := r.Repo.(SoftDeleteRepository[T])
+	if !ok {
+		return nil, errors.SoftDeleteUnsupported
+	}
+	return repo, nil
+}
+
+// SupportsSoftDelete tells whether the repository implements SoftDeleteRepository.
+func (r *ORM[T]) SupportsSoftDelete() bool {
+	_, err := r.softDeleteRepository()
+	return err == nil
+}
+
+// GetAllDeleted retrieves all soft deleted entities with optional sorting.
+func (r *ORM[T]) GetAllDeleted(sort map[string]string) ([]T, error) {
+	repo, err := r.softDeleteRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListDeleted(-1, -1, sort)
+}
+
+// GetDeletedPaginatedList retrieves a paginated list of soft deleted entities with optional sorting.
+func (r *ORM[T]) GetDeletedPaginatedList(itemPerPage int, page int, sort map[string]string) ([]T, error) {
+	repo, err := r.softDeleteRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListDeleted(itemPerPage, itemPerPage*page, sort)
+}
+
+// CountDeleted returns the number of soft deleted entities.
+func (r *ORM[T]) CountDeleted() (int64, error) {
+	repo, err := r.softDeleteRepository()
+	if err != nil {
+		return 0, err
+	}
+	return repo.CountDeleted()
+}
+
+// GetDeletedByID retrieves a single soft deleted entity by its ID.
+func (r *ORM[T]) GetDeletedByID(id any) (*T, error) {
+	identifier, err := r.ParseId(id)
+	if err != nil {
+		return nil, err
+	}
+	list, err := r.FindDeletedByIDs([]entity.Identifier{identifier})
+	if err != nil {
+		if err == errors.NotAllItemFound {
+			return nil, errors.ItemNotFound
+		}
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// FindDeletedByIDs retrieves multiple soft deleted entities by their IDs, returning an error if not all are found.
+func (r *ORM[T]) FindDeletedByIDs(ids []entity.Identifier) ([]*T, error) {
+	repo, err := r.softDeleteRepository()
+	if err != nil {
+		return nil, err
+	}
+	list, err := repo.ReadDeleted(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) != len(ids) {
+		return nil, errors.NotAllItemFound
+	}
+	return list, nil
+}
+
+// Restore brings one or more soft deleted entities back.
+func (r *ORM[T]) Restore(item ...*T) error {
+	repo, err := r.softDeleteRepository()
+	if err != nil {
+		return err
+	}
+	return repo.Restore(item)
+}
+
+// Purge permanently removes one or more entities from the repository.
+func (r *ORM[T]) Purge(item ...*T) error {
+	repo, err := r.softDeleteRepository()
+	if err != nil {
+		return err
+	}
+	return repo.Purge(item)
+}
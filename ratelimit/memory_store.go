@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryBudget struct {
+	// token bucket
+	tokens float64
+	last   time.Time
+	// sliding window
+	index    int64
+	previous int64
+	current  int64
+	// expires is when the budget is fully recovered and can be forgotten
+	expires time.Time
+}
+
+// MemoryStore is an in-process implementation of the Store interface.
+// It is meant for single instance deployments and tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	budgets map[string]*memoryBudget
+	takes   int
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+}
+
+// NewMemoryStore creates a new in-memory rate limit store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{budgets: make(map[string]*memoryBudget)}
+}
+
+// Take spends cost on the budget of key if policy allows it.
+func (m *MemoryStore) Take(key string, policy Policy, cost int) (Result, error) {
+	now := time.Now()
+	if m.Now != nil {
+		now = m.Now()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.takes++
+	if m.takes%1024 == 0 {
+		for stored, budget := range m.budgets {
+			if now.After(budget.expires) {
+				delete(m.budgets, stored)
+			}
+		}
+	}
+
+	budget, ok := m.budgets[key]
+	if !ok {
+		budget = &memoryBudget{tokens: float64(policy.Limit), last: now}
+		m.budgets[key] = budget
+	}
+	if policy.Algorithm == TokenBucketAlgorithm {
+		return m.tokenBucket(budget, policy, cost, now), nil
+	}
+	return m.slidingWindow(budget, policy, cost, now), nil
+}
+
+func (m *MemoryStore) tokenBucket(budget *memoryBudget, policy Policy, cost int, now time.Time) Result {
+	if elapsed := now.Sub(budget.last); elapsed > 0 {
+		budget.tokens = min(float64(policy.Limit), budget.tokens+float64(elapsed)*float64(policy.Limit)/float64(policy.Window))
+		budget.last = now
+	}
+	allowed := float64(cost) <= budget.tokens
+	if allowed {
+		budget.tokens -= float64(cost)
+	}
+	budget.expires = now.Add(policy.Window)
+	return tokenBucketResult(policy, allowed, budget.tokens, cost)
+}
+
+func (m *MemoryStore) slidingWindow(budget *memoryBudget, policy Policy, cost int, now time.Time) Result {
+	index := now.UnixNano() / int64(policy.Window)
+	if index != budget.index {
+		if index == budget.index+1 {
+			budget.previous = budget.current
+		} else {
+			budget.previous = 0
+		}
+		budget.current = 0
+		budget.index = index
+	}
+	elapsed := time.Duration(now.UnixNano() - index*int64(policy.Window))
+	allowed := slidingWindowEstimate(policy, budget.previous, budget.current, elapsed)+float64(cost) <= float64(policy.Limit)
+	if allowed {
+		budget.current += int64(cost)
+	}
+	budget.expires = now.Add(2*policy.Window - elapsed)
+	return slidingWindowResult(policy, allowed, budget.previous, budget.current, elapsed, cost)
+}
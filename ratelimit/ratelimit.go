@@ -0,0 +1,171 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Algorithm decides how the budget of a Policy is spent and recovered
+type Algorithm int8
+
+const (
+	// SlidingWindowAlgorithm counts the cost spent over the last Window, weighting the previous window by its overlap
+	SlidingWindowAlgorithm Algorithm = iota
+	// TokenBucketAlgorithm refills Limit tokens per Window continuously, up to Limit, which allows bursts of Limit
+	TokenBucketAlgorithm
+)
+
+// Policy is a budget of Limit cost units per Window
+// A Policy without Limit is unlimited
+type Policy struct {
+	Limit     int
+	Window    time.Duration
+	Algorithm Algorithm
+}
+
+// SlidingWindow returns a sliding window policy of limit per window.
+func SlidingWindow(limit int, window time.Duration) Policy {
+	return Policy{Limit: limit, Window: window, Algorithm: SlidingWindowAlgorithm}
+}
+
+// TokenBucket returns a token bucket policy of limit per window.
+func TokenBucket(limit int, window time.Duration) Policy {
+	return Policy{Limit: limit, Window: window, Algorithm: TokenBucketAlgorithm}
+}
+
+// Unlimited checks if the policy never rejects.
+func (p Policy) Unlimited() bool {
+	return p.Limit <= 0 || p.Window <= 0
+}
+
+// Result is the decision of a Store on a request
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the budget is recovered: refilled for token buckets, the end of the current window for sliding windows
+	Reset time.Duration
+	// RetryAfter is the time until the rejected request could be allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps the spent budgets, Take spends cost on the budget of key if policy allows it.
+// Stores are shared by every ApiRouter, keys are namespaced by the callers.
+type Store interface {
+	Take(key string, policy Policy, cost int) (Result, error)
+}
+
+// Budget is the class of operations a Policy applies to
+type Budget int8
+
+const (
+	ReadBudget Budget = iota
+	WriteBudget
+	// BatchBudget is spent by batch operations, which cost their number of items
+	BatchBudget
+)
+
+// String returns the name of the budget, used in store keys.
+func (b Budget) String() string {
+	switch b {
+	case WriteBudget:
+		return "write"
+	case BatchBudget:
+		return "batch"
+	}
+	return "read"
+}
+
+// Limiter holds the policies of every budget and the store spending them
+// Zero policies are unlimited
+type Limiter struct {
+	Store Store
+	Read  Policy
+	Write Policy
+	Batch Policy
+}
+
+// NewLimiter creates a limiter spending its budgets in store.
+func NewLimiter(store Store, read, write, batch Policy) *Limiter {
+	return &Limiter{Store: store, Read: read, Write: write, Batch: batch}
+}
+
+// Policy returns the policy of budget.
+func (l *Limiter) Policy(budget Budget) Policy {
+	switch budget {
+	case WriteBudget:
+		return l.Write
+	case BatchBudget:
+		return l.Batch
+	}
+	return l.Read
+}
+
+// Take spends cost on the budget of key, unlimited policies always allow.
+func (l *Limiter) Take(key string, policy Policy, cost int) (Result, error) {
+	if policy.Unlimited() || l.Store == nil {
+		return Result{Allowed: true}, nil
+	}
+	if cost < 1 {
+		cost = 1
+	}
+	return l.Store.Take(key, policy, cost)
+}
+
+// tokenBucketResult describes a token bucket left with tokens after deciding on a request of cost.
+func tokenBucketResult(p Policy, allowed bool, tokens float64, cost int) Result {
+	// refillTime returns the time needed to refill amount tokens
+	refillTime := func(amount float64) time.Duration {
+		return time.Duration(math.Ceil(amount * float64(p.Window) / float64(p.Limit)))
+	}
+	result := Result{
+		Allowed:   allowed,
+		Limit:     p.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     refillTime(float64(p.Limit) - tokens),
+	}
+	if !allowed {
+		if cost > p.Limit {
+			//never allowed, the client should not retry before a full window
+			result.RetryAfter = p.Window
+		} else {
+			result.RetryAfter = refillTime(float64(cost) - tokens)
+		}
+	}
+	return result
+}
+
+// slidingWindowEstimate returns the cost spent over the last window, elapsed into the current one.
+func slidingWindowEstimate(p Policy, previous, current int64, elapsed time.Duration) float64 {
+	return float64(previous)*float64(p.Window-elapsed)/float64(p.Window) + float64(current)
+}
+
+// slidingWindowResult describes a sliding window with the given counts after deciding on a request of cost.
+func slidingWindowResult(p Policy, allowed bool, previous, current int64, elapsed time.Duration, cost int) Result {
+	estimate := slidingWindowEstimate(p, previous, current, elapsed)
+	result := Result{
+		Allowed:   allowed,
+		Limit:     p.Limit,
+		Remaining: max(0, int(math.Floor(float64(p.Limit)-estimate))),
+		Reset:     p.Window - elapsed,
+	}
+	if allowed {
+		return result
+	}
+	if cost > p.Limit {
+		result.RetryAfter = p.Window
+		return result
+	}
+	//the previous window keeps fading until the end of the current one
+	excess := estimate + float64(cost) - float64(p.Limit)
+	if previous > 0 && float64(current)+float64(cost) <= float64(p.Limit) {
+		result.RetryAfter = time.Duration(math.Ceil(excess * float64(p.Window) / float64(previous)))
+		return result
+	}
+	//then the current window becomes the previous one
+	result.RetryAfter = p.Window - elapsed
+	if overflow := float64(current) + float64(cost) - float64(p.Limit); overflow > 0 && current > 0 {
+		result.RetryAfter += time.Duration(math.Ceil(overflow * float64(p.Window) / float64(current)))
+	}
+	return result
+}
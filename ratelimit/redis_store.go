@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and spends a token bucket atomically, it returns {allowed, tokens}
+const tokenBucketScript = `
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(state[1]) or limit
+local last = tonumber(state[2]) or now
+if now > last then
+	tokens = math.min(limit, tokens + (now - last) * limit / window)
+	last = now
+end
+local allowed = 0
+if cost <= tokens then
+	tokens = tokens - cost
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', last)
+redis.call('PEXPIRE', KEYS[1], window)
+return {allowed, tostring(tokens)}
+`
+
+// slidingWindowScript spends a sliding window atomically, it returns {allowed, previous, current}
+const slidingWindowScript = `
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local allowed = 0
+if previous * (window - elapsed) / window + current + cost <= limit then
+	current = redis.call('INCRBY', KEYS[1], cost)
+	redis.call('PEXPIRE', KEYS[1], 2 * window)
+	allowed = 1
+end
+return {allowed, previous, current}
+`
+
+var errUnexpectedReply = errors.New("ratelimit: unexpected reply from redis")
+
+// RedisStore is a Redis-based implementation of the Store interface, budgets are shared by every instance.
+// Times are given by the instances, their clocks should be synchronized.
+type RedisStore struct {
+	Client *redis.Client
+	prefix string
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+}
+
+// NewRedisStore creates a new Redis rate limit store with the specified connection parameters.
+func NewRedisStore(addr, password string, db int) *RedisStore {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	return &RedisStore{
+		Client: client,
+		prefix: "restman:ratelimit:",
+	}
+}
+
+// Take spends cost on the budget of key if policy allows it.
+func (r *RedisStore) Take(key string, policy Policy, cost int) (Result, error) {
+	now := time.Now()
+	if r.Now != nil {
+		now = r.Now()
+	}
+	window := policy.Window.Milliseconds()
+	if window < 1 {
+		window = 1
+	}
+
+	if policy.Algorithm == TokenBucketAlgorithm {
+		values, err := r.Client.Eval(context.Background(), tokenBucketScript, []string{r.prefix + key},
+			policy.Limit, window, now.UnixMilli(), cost).Slice()
+		if err != nil {
+			return Result{}, err
+		}
+		allowed, ok := replyInt(values, 0)
+		text, isText := replyValue(values, 1).(string)
+		tokens, parseErr := strconv.ParseFloat(text, 64)
+		if !ok || !isText || parseErr != nil {
+			return Result{}, errUnexpectedReply
+		}
+		return tokenBucketResult(policy, allowed == 1, tokens, cost), nil
+	}
+
+	index := now.UnixMilli() / window
+	elapsed := now.UnixMilli() - index*window
+	values, err := r.Client.Eval(context.Background(), slidingWindowScript,
+		//the hash tag keeps both windows on the same cluster node
+		[]string{r.prefix + "{" + key + "}:" + strconv.FormatInt(index, 10), r.prefix + "{" + key + "}:" + strconv.FormatInt(index-1, 10)},
+		policy.Limit, window, elapsed, cost).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	allowed, okAllowed := replyInt(values, 0)
+	previous, okPrevious := replyInt(values, 1)
+	current, okCurrent := replyInt(values, 2)
+	if !okAllowed || !okPrevious || !okCurrent {
+		return Result{}, errUnexpectedReply
+	}
+	return slidingWindowResult(policy, allowed == 1, previous, current, time.Duration(elapsed)*time.Millisecond, cost), nil
+}
+
+func replyValue(values []any, i int) any {
+	if i >= len(values) {
+		return nil
+	}
+	return values[i]
+}
+
+func replyInt(values []any, i int) (int64, bool) {
+	value, ok := replyValue(values, i).(int64)
+	return value, ok
+}
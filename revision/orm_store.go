@@ -0,0 +1,79 @@
+package revision
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/philiphil/restman/orm"
+)
+
+// ORMStore stores revisions with a repository, such as a table of the versioned database
+// Revisions are numbered under a lock, processes sharing a store rely on the unique index of the number:
+// a revision whose number was taken meanwhile is numbered again.
+type ORMStore struct {
+	ORM *orm.ORM[Revision]
+	mu  sync.Mutex
+}
+
+// NewORMStore creates a store keeping revisions with o.
+//
+// Example:
+//
+//	db.AutoMigrate(&revision.Revision{})
+//	store := revision.NewORMStore(orm.NewORM(gormrepository.NewRepository[revision.Revision](db)))
+func NewORMStore(o *orm.ORM[Revision]) *ORMStore {
+	return &ORMStore{ORM: o}
+}
+
+// saveAttempts is how many times a revision is numbered again when another process took its number
+const saveAttempts = 5
+
+// Save numbers the revision after the last one of its entity and stores it.
+func (s *ORMStore) Save(revision *Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	number, err := s.next(revision)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		revision.Number = number
+		err = s.ORM.Create(revision)
+		if err == nil || attempt == saveAttempts {
+			return err
+		}
+		//the number may have been taken by another process, otherwise the failure is not a conflict
+		if number, _ = s.next(revision); number <= revision.Number {
+			return err
+		}
+		revision.Id = 0
+	}
+}
+
+// next returns the number following the last revision of the entity of revision
+func (s *ORMStore) next(revision *Revision) (int, error) {
+	revisions, err := s.List(revision.Resource, revision.EntityID)
+	if err != nil {
+		return 0, err
+	}
+	if len(revisions) == 0 {
+		return 1, nil
+	}
+	return revisions[len(revisions)-1].Number + 1, nil
+}
+
+// List returns the revisions of an entity, oldest first.
+func (s *ORMStore) List(resource string, entityID string) ([]Revision, error) {
+	scoped, err := s.ORM.Scoped(orm.Where("Resource", resource), orm.Where("EntityID", entityID))
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := scoped.GetAll(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Number < revisions[j].Number
+	})
+	return revisions, nil
+}
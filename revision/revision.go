@@ -0,0 +1,70 @@
+package revision
+
+import (
+	"time"
+
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// Revision is the full state of an entity after one of its writes
+// Revisions are numbered from 1 for every entity of a resource, the number is unique per entity
+type Revision struct {
+	Id        entity.ID `json:"-" orm:"primaryKey"`
+	Resource  string    `json:"-" gorm:"uniqueIndex:idx_revisions_number"`
+	EntityID  string    `json:"-" gorm:"uniqueIndex:idx_revisions_number"`
+	Number    int       `json:"revision" gorm:"uniqueIndex:idx_revisions_number"`
+	Operation string    `json:"operation"`
+	User      string    `json:"user,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Time      time.Time `json:"time"`
+	// Snapshot is the JSON encoded entity
+	Snapshot string `json:"-"`
+}
+
+// Store keeps the revisions of the entities
+type Store interface {
+	// Save numbers the revision after the last one of its entity and stores it.
+	Save(revision *Revision) error
+	// List returns the revisions of an entity, oldest first.
+	List(resource string, entityID string) ([]Revision, error)
+}
+
+// Find returns the revision numbered number of an entity, or nil when there is none.
+func Find(store Store, resource string, entityID string, number int) (*Revision, error) {
+	revisions, err := store.List(resource, entityID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		if revisions[i].Number == number {
+			return &revisions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// TableName keeps revisions apart from the tables of the application.
+func (r Revision) TableName() string {
+	return "revisions"
+}
+
+// GetIdentifier returns the id of the revision.
+func (r Revision) GetIdentifier() entity.Identifier {
+	return r.Id
+}
+
+// SetId sets the id of the revision.
+func (r Revision) SetId(id any) entity.Entity {
+	r.Id = entity.CastId(id)
+	return r
+}
+
+// ToEntity returns the revision, it is its own database model.
+func (r Revision) ToEntity() Revision {
+	return r
+}
+
+// FromEntity returns the revision, it is its own database model.
+func (r Revision) FromEntity(revision Revision) any {
+	return revision
+}
@@ -10,6 +10,7 @@ import (
 func NewRoute(routeType RouteType, configurations ...configuration.Configuration) Route {
 	c := Route{}
 	c.RouteType = routeType
+	c.Configuration = make(map[configuration.ConfigurationType]configuration.Configuration, len(configurations))
 	for _, configuration := range configurations {
 		c.Configuration[configuration.Type] = configuration
 	}
@@ -37,6 +38,37 @@ func AllApiRoutes() map[RouteType]Route {
 	return mergedRoutes
 }
 
+// SoftDeleteOperations returns a map of soft delete lifecycle routes (Trash, Restore, BatchRestore, Purge, BatchPurge).
+// They require a repository implementing orm.SoftDeleteRepository.
+func SoftDeleteOperations() map[RouteType]Route {
+	return map[RouteType]Route{
+		Trash:        NewRoute(Trash),
+		Restore:      NewRoute(Restore),
+		BatchRestore: NewRoute(BatchRestore),
+		Purge:        NewRoute(Purge),
+		BatchPurge:   NewRoute(BatchPurge),
+	}
+}
+
+// RevisionOperations returns a map of revision history routes (Revisions, Revision, Revert).
+// They require a revision store, see ApiRouter.SetRevisionStore.
+func RevisionOperations() map[RouteType]Route {
+	return map[RouteType]Route{
+		Revisions: NewRoute(Revisions),
+		Revision:  NewRoute(Revision),
+		Revert:    NewRoute(Revert),
+	}
+}
+
+// StreamOperations returns a map of Server-Sent Events routes (Stream, ItemStream).
+// They require a hub, see ApiRouter.EnableStreaming.
+func StreamOperations() map[RouteType]Route {
+	return map[RouteType]Route{
+		Stream:     NewRoute(Stream),
+		ItemStream: NewRoute(ItemStream),
+	}
+}
+
 // BatchOperations returns a map of batch operation routes (BatchDelete, BatchPut, BatchPatch, BatchPost, BatchGet).
 func BatchOperations() map[RouteType]Route {
 	return map[RouteType]Route{
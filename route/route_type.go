@@ -27,6 +27,22 @@ const (
 	BatchPut
 	BatchPatch
 	BatchDelete
+
+	//soft delete lifecycle
+	Trash
+	Restore
+	BatchRestore
+	Purge
+	BatchPurge
+
+	//revision history
+	Revisions
+	Revision
+	Revert
+
+	//real-time change streams
+	Stream
+	ItemStream
 )
 
 // String returns the HTTP method name for the RouteType.
@@ -54,3 +70,12 @@ func (e RouteType) String() string {
 		return fmt.Sprintf("%d", int(e))
 	}
 }
+
+// IsBatch reports whether the RouteType operates on several entities at once.
+func (e RouteType) IsBatch() bool {
+	switch e {
+	case BatchGet, BatchPost, BatchPut, BatchPatch, BatchDelete, BatchRestore, BatchPurge:
+		return true
+	}
+	return false
+}
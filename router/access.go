@@ -0,0 +1,141 @@
+package router
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/route"
+	"github.com/philiphil/restman/security"
+)
+
+// SetAccessDecisionManager sets the manager deciding on the security expressions of this ApiRouter.
+// Without one, security.DefaultAccessDecisionManager is used.
+func (r *ApiRouter[T]) SetAccessDecisionManager(manager *security.AccessDecisionManager) {
+	r.AccessDecisionManager = manager
+}
+
+// AddVoter adds voters to the access decision manager of this ApiRouter, starting from the default one if none is set.
+func (r *ApiRouter[T]) AddVoter(voters ...security.Voter) {
+	if r.AccessDecisionManager == nil {
+		r.AccessDecisionManager = security.DefaultAccessDecisionManager()
+	}
+	r.AccessDecisionManager.AddVoter(voters...)
+}
+
+func (r *ApiRouter[T]) accessDecisionManager() *security.AccessDecisionManager {
+	if r.AccessDecisionManager == nil {
+		return security.DefaultAccessDecisionManager()
+	}
+	return r.AccessDecisionManager
+}
+
+// IsGranted evaluates a security expression for the authenticated user on the subject.
+// It is meant for custom operations, routes declare their expression with configuration.Security.
+func (r *ApiRouter[T]) IsGranted(c *gin.Context, expression string, subject any) (bool, error) {
+	user, err := r.FirewallCheck(c)
+	if err != nil {
+		return false, err
+	}
+	granted, err := r.accessDecisionManager().IsGranted(user, expression, subject)
+	if err != nil {
+		return false, errors.ErrInternal
+	}
+	return granted, nil
+}
+
+// AccessCheck verifies that the authenticated user is granted the security expression of the route on every object.
+// Without objects, as for collection routes, the expression is decided on a nil subject.
+// Anonymous users are answered with ErrUnauthorized, authenticated ones with ErrForbidden.
+// The CSRF protections of the firewalls are checked first, then configuration.AuthenticationRequired.
+func (r *ApiRouter[T]) AccessCheck(c *gin.Context, routeType route.RouteType, objects ...*T) error {
+	if err := r.authenticationCheck(c, routeType); err != nil {
+		return err
+	}
+	expression := r.routeSecurity(routeType)
+	if expression == "" {
+		return nil
+	}
+	user, err := r.FirewallCheck(c)
+	if err != nil {
+		return err
+	}
+
+	subjects := make([]any, 0, len(objects))
+	for _, object := range objects {
+		subjects = append(subjects, *object)
+	}
+	if len(subjects) == 0 {
+		subjects = append(subjects, nil)
+	}
+	manager := r.accessDecisionManager()
+	for _, subject := range subjects {
+		granted, err := manager.IsGranted(user, expression, subject)
+		if err != nil {
+			return errors.ErrInternal
+		}
+		if !granted {
+			if user == nil {
+				return errors.ErrUnauthorized
+			}
+			return errors.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// authenticationCheck runs the CSRF protections of the firewalls, then rejects anonymous users when the route requires authentication.
+// It needs no object, item routes run it before their response cache is looked up.
+func (r *ApiRouter[T]) authenticationCheck(c *gin.Context, routeType route.RouteType) error {
+	if err := r.CSRFCheck(c); err != nil {
+		return err
+	}
+	if !r.authenticationRequired(routeType) {
+		return nil
+	}
+	user, err := r.FirewallCheck(c)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.ErrUnauthorized
+	}
+	return nil
+}
+
+// authenticationRequired tells whether the route rejects anonymous users, see configuration.AuthenticationRequired
+func (r *ApiRouter[T]) authenticationRequired(routeType route.RouteType) bool {
+	required, err := r.GetConfiguration(configuration.AuthenticationRequiredType, routeType)
+	if err != nil || len(required.Values) == 0 {
+		return false
+	}
+	value, err := strconv.ParseBool(required.Values[0])
+	return err == nil && value
+}
+
+// checkSecurityExpressions parses the security expressions of the router and of its routes,
+// so that a malformed expression fails at startup instead of answering every request with 500.
+func (r *ApiRouter[T]) checkSecurityExpressions() {
+	expressions := []string{}
+	if expression, ok := r.Configuration[configuration.SecurityType]; ok {
+		expressions = append(expressions, expression.Values...)
+	}
+	for _, route_ := range r.Routes {
+		if expression, ok := route_.Configuration[configuration.SecurityType]; ok {
+			expressions = append(expressions, expression.Values...)
+		}
+	}
+	for _, expression := range expressions {
+		security.MustParseExpression(expression)
+	}
+}
+
+// routeSecurity returns the security expression of the route, empty when it has none
+func (r *ApiRouter[T]) routeSecurity(routeType route.RouteType) string {
+	expression, err := r.GetConfiguration(configuration.SecurityType, routeType)
+	if err != nil || len(expression.Values) == 0 {
+		return ""
+	}
+	return expression.Values[0]
+}
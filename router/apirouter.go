@@ -6,9 +6,15 @@ import (
 	"unicode"
 
 	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/audit"
+	"github.com/philiphil/restman/broadcast"
+	"github.com/philiphil/restman/cache"
 	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/event"
 	"github.com/philiphil/restman/orm"
 	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/ratelimit"
+	"github.com/philiphil/restman/revision"
 	"github.com/philiphil/restman/route"
 	"github.com/philiphil/restman/security"
 )
@@ -16,12 +22,19 @@ import (
 // SubresourceRegistrar is an interface that any ApiRouter must implement
 // to allow registration of its routes with a parent router
 type SubresourceRegistrar interface {
-	// RegisterSubroutes registers all routes for this subresource under the given parent route
+	// RegisterSubroutes registers all routes for this subresource under the given parent route, with /:id/ prefix
 	RegisterSubroutes(router *gin.Engine, parentRoute string)
 	// GetSubresourceName returns the name of this subresource (used in URL path)
 	GetSubresourceName() string
 }
 
+// ItemSubresourceRegistrar is implemented by the subresources registering their routes under the item route of their parent,
+// which has one parameter per part of composite identifiers. Parents prefer it over RegisterSubroutes.
+type ItemSubresourceRegistrar interface {
+	// RegisterItemSubroutes registers all routes for this subresource under the given parent item route (e.g. /api/parent/:id)
+	RegisterItemSubroutes(router *gin.Engine, parentItemRoute string)
+}
+
 // An ApiRouter is the main object to create a REST API
 // It is composed of an ORM, a list of Allow methods, a list of firewalls and a route
 // To create an ApiRouter, you should use the NewApiRouter function
@@ -33,47 +46,107 @@ type ApiRouter[T entity.Entity] struct {
 	Configuration map[configuration.ConfigurationType]configuration.Configuration
 
 	Subresources []SubresourceRegistrar
+
+	// ResponseCache is optional, when set read routes serve serialized responses from it
+	ResponseCache cache.ResponseCache
+
+	// TenantResolver is optional, when set every request is isolated to its tenant, see SetTenancy
+	TenantResolver     security.TenantResolver
+	TenantField        string
+	TenantRepositories orm.TenantRepositoryProviders[T]
+
+	// Ownership is optional, when set every entity is restricted to its owner, see RequireOwnership
+	Ownership *security.Ownership
+
+	// AccessDecisionManager decides on the security expressions of the routes, see configuration.Security
+	AccessDecisionManager *security.AccessDecisionManager
+
+	// ReadPolicy is optional, when set it restricts the queries of every request, see SetReadPolicy
+	ReadPolicy security.ReadPolicyFunction
+
+	// GroupResolver is optional, when set it computes the serialization groups of every request, see SetGroupResolver
+	GroupResolver GroupResolver
+
+	// RateLimiter is optional, when set every route spends the budgets of the subject of the request, see SetRateLimiter
+	RateLimiter *ratelimit.Limiter
+
+	// AuditSink is optional, when set every write is recorded in it, see SetAuditSink
+	AuditSink audit.Sink
+
+	// RevisionStore is optional, when set the state of the entities is kept after every write, see SetRevisionStore
+	RevisionStore revision.Store
+
+	// EventBus is optional, when set an event is published after every write, see SetEventBus
+	EventBus    event.Bus
+	EventGroups []string
+
+	// Broadcast is optional, when set the changes of the entities are streamed to the subscribers, see EnableStreaming
+	Broadcast *broadcast.Hub[T]
 }
 
 // AllowRoutes is a function that adds the route to the gin router
 func (r *ApiRouter[T]) AllowRoutes(router *gin.Engine) {
+	//routes and configurations may have changed since NewApiRouter
+	r.checkSecurityExpressions()
 
 	//Batch Get and Bast Post shares the same route as GetList and Post
 	//we dont want to register the route twice
 	getList, post := false, false
 
+	itemPath := r.ItemPath()
 	for _, route_ := range r.Routes {
 		routeName := r.Route(route_.RouteType)
+		limited := r.rateLimited(route_.RouteType)
 		switch route_.RouteType {
 		case route.Get:
-			router.GET(routeName+"/:id", r.Get)
+			router.GET(routeName+itemPath, limited, r.Get)
 		case route.BatchGet, route.GetList:
 			if !getList {
-				router.GET(routeName, r.GetListOrBatchGet)
+				router.GET(routeName, limited, r.GetListOrBatchGet)
 				getList = true
 			}
 		case route.BatchPost, route.Post:
 			if !post {
-				router.POST(routeName, r.Post)
+				router.POST(routeName, limited, r.Post)
 				post = true
 			}
 		case route.Put:
-			router.PUT(routeName+"/:id", r.Put)
+			router.PUT(routeName+itemPath, limited, r.Put)
 		case route.Patch:
-			router.PATCH(routeName+"/:id", r.Patch)
+			router.PATCH(routeName+itemPath, limited, r.Patch)
 		case route.Delete:
-			router.DELETE(routeName+"/:id", r.Delete)
+			router.DELETE(routeName+itemPath, limited, r.Delete)
 		case route.Head:
-			router.HEAD(routeName+"/:id", r.Head)
+			router.HEAD(routeName+itemPath, limited, r.Head)
 		case route.Options:
-			router.OPTIONS(routeName+"/:id", r.Options)
-			router.OPTIONS(routeName, r.Options)
+			router.OPTIONS(routeName+itemPath, limited, r.Options)
+			router.OPTIONS(routeName, limited, r.Options)
 		case route.BatchDelete:
-			router.DELETE(routeName, r.batchDelete)
+			router.DELETE(routeName, limited, r.batchDelete)
 		case route.BatchPatch:
-			router.PATCH(routeName, r.BatchPatch)
+			router.PATCH(routeName, limited, r.BatchPatch)
 		case route.BatchPut:
-			router.PUT(routeName, r.BatchPut)
+			router.PUT(routeName, limited, r.BatchPut)
+		case route.Trash:
+			router.GET(routeName+"/trash", limited, r.Trash)
+		case route.Restore:
+			router.POST(routeName+"/trash"+itemPath+"/restore", limited, r.Restore)
+		case route.BatchRestore:
+			router.POST(routeName+"/trash/restore", limited, r.BatchRestore)
+		case route.Purge:
+			router.DELETE(routeName+"/trash"+itemPath, limited, r.Purge)
+		case route.BatchPurge:
+			router.DELETE(routeName+"/trash", limited, r.BatchPurge)
+		case route.Revisions:
+			router.GET(routeName+itemPath+"/revisions", limited, r.Revisions)
+		case route.Revision:
+			router.GET(routeName+itemPath+"/revisions/:rev", limited, r.Revision)
+		case route.Revert:
+			router.POST(routeName+itemPath+"/revisions/:rev/revert", limited, r.Revert)
+		case route.Stream:
+			router.GET(routeName+"/stream", limited, r.Stream)
+		case route.ItemStream:
+			router.GET(routeName+itemPath+"/stream", limited, r.ItemStream)
 		case route.Connect:
 		case route.Trace:
 		case route.Undefined:
@@ -81,9 +154,17 @@ func (r *ApiRouter[T]) AllowRoutes(router *gin.Engine) {
 	}
 
 	// Register all subresources
-	baseroute := r.Route()
+	r.registerSubresources(router, r.Route())
+}
+
+// registerSubresources registers the subresources under route, the collection route of this ApiRouter
+func (r *ApiRouter[T]) registerSubresources(router *gin.Engine, route string) {
 	for _, subresource := range r.Subresources {
-		subresource.RegisterSubroutes(router, baseroute)
+		if registrar, ok := subresource.(ItemSubresourceRegistrar); ok {
+			registrar.RegisterItemSubroutes(router, route+r.ItemPath())
+			continue
+		}
+		subresource.RegisterSubroutes(router, route)
 	}
 }
 
@@ -113,6 +194,7 @@ func ConvertToSnakeCase(input string) string {
 
 // NewApiRouter is a function that creates a new ApiRouter
 // it should be the default way of creating an ApiRouter because it sets the default configuration
+// it panics if a security expression of the configuration or of the routes is malformed
 func NewApiRouter[T entity.Entity](orm orm.ORM[T], routes map[route.RouteType]route.Route, conf ...configuration.Configuration) *ApiRouter[T] {
 	router := &ApiRouter[T]{
 		Orm:    orm,
@@ -133,6 +215,7 @@ func NewApiRouter[T entity.Entity](orm orm.ORM[T], routes map[route.RouteType]ro
 	if !routeNameSet {
 		router.Configuration[configuration.RouteNameType] = configuration.RouteName(ConvertToSnakeCase(reflect.TypeOf(orm.NewEntity()).Name()))
 	}
+	router.checkSecurityExpressions()
 	return router
 }
 
@@ -169,8 +252,14 @@ func (r *ApiRouter[T]) AddFirewall(firewall ...security.Firewall) {
 	r.Firewalls = append(r.Firewalls, firewall...)
 }
 
+// SetResponseCache enables the serialized-response cache for the read routes of this ApiRouter.
+// Responses are keyed by target, format, effective groups and user scope, and evicted on writes.
+func (r *ApiRouter[T]) SetResponseCache(responseCache cache.ResponseCache) {
+	r.ResponseCache = responseCache
+}
+
 // AddSubresource adds a subresource to this ApiRouter
-// The subresource routes will be registered under the parent route with /:id/ prefix
+// The subresource routes will be registered under the parent item route (/:id/ prefix, one parameter per part for composite identifiers)
 func (r *ApiRouter[T]) AddSubresource(subresource SubresourceRegistrar) {
 	r.Subresources = append(r.Subresources, subresource)
 }
@@ -182,50 +271,77 @@ func (r *ApiRouter[T]) GetSubresourceName() string {
 }
 
 // RegisterSubroutes registers all routes for this ApiRouter as a subresource under the given parent route
+// parentRoute is the collection route of the parent, whose identifier is the "id" parameter.
 func (r *ApiRouter[T]) RegisterSubroutes(router *gin.Engine, parentRoute string) {
+	r.RegisterItemSubroutes(router, parentRoute+"/:id")
+}
+
+// RegisterItemSubroutes registers all routes for this ApiRouter as a subresource under the given parent item route
+// parentItemRoute has the parameters of the parent ("/api/parent/:id", or one parameter per key part).
+func (r *ApiRouter[T]) RegisterItemSubroutes(router *gin.Engine, parentItemRoute string) {
 	//Batch Get and Bast Post shares the same route as GetList and Post
 	//we dont want to register the route twice
 	getList, post := false, false
 
 	subresourceName := r.GetSubresourceName()
 
-	// Always use "id" for all parameters to avoid gin routing conflicts
-	paramName := "id"
-	itemParamName := "id"
+	// Item routes use the same parameters as the resource itself ("id" for simple identifiers) to avoid gin routing conflicts
+	itemPath := r.ItemPath()
 
-	baseRoute := parentRoute + "/:" + paramName + "/" + subresourceName
+	baseRoute := parentItemRoute + "/" + subresourceName
 
 	for _, route_ := range r.Routes {
+		limited := r.rateLimited(route_.RouteType)
 		switch route_.RouteType {
 		case route.Get:
-			router.GET(baseRoute+"/:"+itemParamName, r.Get)
+			router.GET(baseRoute+itemPath, limited, r.Get)
 		case route.BatchGet, route.GetList:
 			if !getList {
-				router.GET(baseRoute, r.GetListOrBatchGet)
+				router.GET(baseRoute, limited, r.GetListOrBatchGet)
 				getList = true
 			}
 		case route.BatchPost, route.Post:
 			if !post {
-				router.POST(baseRoute, r.Post)
+				router.POST(baseRoute, limited, r.Post)
 				post = true
 			}
 		case route.Put:
-			router.PUT(baseRoute+"/:"+itemParamName, r.Put)
+			router.PUT(baseRoute+itemPath, limited, r.Put)
 		case route.Patch:
-			router.PATCH(baseRoute+"/:"+itemParamName, r.Patch)
+			router.PATCH(baseRoute+itemPath, limited, r.Patch)
 		case route.Delete:
-			router.DELETE(baseRoute+"/:"+itemParamName, r.Delete)
+			router.DELETE(baseRoute+itemPath, limited, r.Delete)
 		case route.Head:
-			router.HEAD(baseRoute+"/:"+itemParamName, r.Head)
+			router.HEAD(baseRoute+itemPath, limited, r.Head)
 		case route.Options:
-			router.OPTIONS(baseRoute+"/:"+itemParamName, r.Options)
-			router.OPTIONS(baseRoute, r.Options)
+			router.OPTIONS(baseRoute+itemPath, limited, r.Options)
+			router.OPTIONS(baseRoute, limited, r.Options)
 		case route.BatchDelete:
-			router.DELETE(baseRoute, r.batchDelete)
+			router.DELETE(baseRoute, limited, r.batchDelete)
 		case route.BatchPatch:
-			router.PATCH(baseRoute, r.BatchPatch)
+			router.PATCH(baseRoute, limited, r.BatchPatch)
 		case route.BatchPut:
-			router.PUT(baseRoute, r.BatchPut)
+			router.PUT(baseRoute, limited, r.BatchPut)
+		case route.Trash:
+			router.GET(baseRoute+"/trash", limited, r.Trash)
+		case route.Restore:
+			router.POST(baseRoute+"/trash"+itemPath+"/restore", limited, r.Restore)
+		case route.BatchRestore:
+			router.POST(baseRoute+"/trash/restore", limited, r.BatchRestore)
+		case route.Purge:
+			router.DELETE(baseRoute+"/trash"+itemPath, limited, r.Purge)
+		case route.BatchPurge:
+			router.DELETE(baseRoute+"/trash", limited, r.BatchPurge)
+		case route.Revisions:
+			router.GET(baseRoute+itemPath+"/revisions", limited, r.Revisions)
+		case route.Revision:
+			router.GET(baseRoute+itemPath+"/revisions/:rev", limited, r.Revision)
+		case route.Revert:
+			router.POST(baseRoute+itemPath+"/revisions/:rev/revert", limited, r.Revert)
+		case route.Stream:
+			router.GET(baseRoute+"/stream", limited, r.Stream)
+		case route.ItemStream:
+			router.GET(baseRoute+itemPath+"/stream", limited, r.ItemStream)
 		case route.Connect:
 		case route.Trace:
 		case route.Undefined:
@@ -233,7 +349,5 @@ func (r *ApiRouter[T]) RegisterSubroutes(router *gin.Engine, parentRoute string)
 	}
 
 	// Recursively register nested subresources
-	for _, sub := range r.Subresources {
-		sub.RegisterSubroutes(router, baseRoute)
-	}
+	r.registerSubresources(router, baseRoute)
 }
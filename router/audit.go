@@ -0,0 +1,115 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/audit"
+	"github.com/philiphil/restman/route"
+)
+
+const requestIDContextKey = "restman.requestId"
+
+// SetAuditSink records every write of this ApiRouter, with the field level changes of its entities, in sink.
+func (r *ApiRouter[T]) SetAuditSink(sink audit.Sink) {
+	r.AuditSink = sink
+}
+
+// RequestID returns the id of the request, taken from its X-Request-ID header or generated and set on the response.
+func RequestID(c *gin.Context) string {
+	if id := c.GetString(requestIDContextKey); id != "" {
+		return id
+	}
+	id := c.GetHeader(audit.RequestIDHeader)
+	if id == "" || len(id) > 128 {
+		id = audit.NewRequestID()
+	}
+	c.Set(requestIDContextKey, id)
+	c.Header(audit.RequestIDHeader, id)
+	return id
+}
+
+// snapshot copies the objects about to be modified, so that their previous state can be audited
+func snapshot[T any](objects ...*T) []*T {
+	copies := make([]*T, len(objects))
+	for i, object := range objects {
+		copied := *object
+		copies[i] = &copied
+	}
+	return copies
+}
+
+func auditOperation(routeType route.RouteType) audit.Operation {
+	switch routeType {
+	case route.Post, route.BatchPost:
+		return audit.Create
+	case route.Delete, route.BatchDelete:
+		return audit.Delete
+	case route.Restore, route.BatchRestore:
+		return audit.Restore
+	case route.Purge, route.BatchPurge:
+		return audit.Purge
+	case route.Revert:
+		return audit.Revert
+	}
+	return audit.Update
+}
+
+// writeOperation returns the operation of a write, a Put without previous state creates its entity
+func writeOperation[T any](routeType route.RouteType, before []*T) audit.Operation {
+	if routeType == route.Put && len(before) == 0 {
+		return audit.Create
+	}
+	return auditOperation(routeType)
+}
+
+// recordedUser returns the identifier of the user of the request, empty for anonymous requests
+func (r *ApiRouter[T]) recordedUser(c *gin.Context) string {
+	if user, err := r.FirewallCheck(c); err == nil && user != nil {
+		return user.GetIdentifier().String()
+	}
+	return ""
+}
+
+// audit records a successful write, the states before and after it are matched by identifier.
+// The write is already done, a failing sink is reported on the gin context without changing the response.
+func (r *ApiRouter[T]) audit(c *gin.Context, routeType route.RouteType, before []*T, after []*T) {
+	if r.AuditSink == nil {
+		return
+	}
+	record := audit.Record{
+		Time:      time.Now().UTC(),
+		RequestID: RequestID(c),
+		User:      r.recordedUser(c),
+		Operation: writeOperation(routeType, before),
+		Batch:     routeType.IsBatch(),
+		Resource:  r.ResourceName(),
+	}
+
+	previous := make(map[string]*T, len(before))
+	for _, object := range before {
+		previous[(*object).GetIdentifier().String()] = object
+	}
+	for _, object := range after {
+		id := (*object).GetIdentifier().String()
+		record.IDs = append(record.IDs, id)
+		var old any
+		if found, ok := previous[id]; ok {
+			old = found
+			delete(previous, id)
+		}
+		record.Changes = append(record.Changes, audit.Diff(id, old, object)...)
+	}
+	//the remaining objects were deleted
+	for _, object := range before {
+		id := (*object).GetIdentifier().String()
+		if _, ok := previous[id]; ok {
+			record.IDs = append(record.IDs, id)
+			record.Changes = append(record.Changes, audit.Diff(id, object, nil)...)
+		}
+	}
+
+	if err := r.AuditSink.Write(record); err != nil {
+		_ = c.Error(err)
+	}
+}
@@ -3,16 +3,22 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/philiphil/restman/errors"
-	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/route"
 )
 
 func (r *ApiRouter[T]) batchDelete(c *gin.Context) {
-	ids := r.GetIds(c)
-	formatedId := make([]entity.ID, len(ids))
-	for i, v := range ids {
-		formatedId[i] = entity.CastId(v)
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
 	}
-	objects, err := r.Orm.FindByIDs(formatedId)
+	ids, err := r.GetIdentifiers(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	objects, err := requestOrm.FindByIDs(ids)
 	if err != nil {
 		c.AbortWithStatusJSON(errors.ErrNotFound.Code, errors.ErrNotFound.Message)
 		return
@@ -23,11 +29,19 @@ func (r *ApiRouter[T]) batchDelete(c *gin.Context) {
 			return
 		}
 	}
-	err = r.Orm.Delete(objects...)
+	if err = r.AccessCheck(c, route.BatchDelete, objects...); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	err = r.write(c, requestOrm, route.BatchDelete, objects, nil, func(o *orm.ORM[T]) error {
+		return o.Delete(objects...)
+	})
 	if err != nil {
 		c.AbortWithStatusJSON(500, "Database issue")
 		return
 	}
+	r.invalidateResponseCache(objects...)
+	r.recordWrite(c, route.BatchDelete, objects, nil)
 
 	c.JSON(204, nil)
 }
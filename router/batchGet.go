@@ -3,19 +3,29 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/philiphil/restman/errors"
-	"github.com/philiphil/restman/orm/entity"
 	"github.com/philiphil/restman/route"
 )
 
 // BatchGet handles GET requests for multiple entities by their IDs.
 func (r *ApiRouter[T]) BatchGet(c *gin.Context) {
-	idsValues := r.GetIds(c)
-
-	formatedId := make([]entity.ID, len(idsValues))
-	for i, v := range idsValues {
-		formatedId[i] = entity.CastId(v)
+	if err := r.authenticationCheck(c, route.BatchGet); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	if r.serveFromResponseCache(c, route.BatchGet) {
+		return
+	}
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	ids, err := r.GetIdentifiers(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
 	}
-	objects, err := r.Orm.FindByIDs(formatedId)
+	objects, err := requestOrm.FindByIDs(ids)
 	if err != nil {
 		c.AbortWithStatusJSON(errors.ErrNotFound.Code, errors.ErrNotFound.Message)
 		return
@@ -27,6 +37,10 @@ func (r *ApiRouter[T]) BatchGet(c *gin.Context) {
 			return
 		}
 	}
+	if err = r.AccessCheck(c, route.BatchGet, objects...); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
 
 	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
 	if err != nil {
@@ -40,9 +54,9 @@ func (r *ApiRouter[T]) BatchGet(c *gin.Context) {
 		return
 	}
 
-	c.Render(200, SerializerRenderer{
+	c.Render(200, r.withResponseCache(c, SerializerRenderer{
 		Data:   objects,
 		Format: responseFormat,
 		Groups: groups,
-	})
+	}, r.itemTags(objects...)...))
 }
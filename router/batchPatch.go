@@ -4,12 +4,18 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/philiphil/restman/configuration"
 	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
 	"github.com/philiphil/restman/orm/entity"
 	"github.com/philiphil/restman/route"
 )
 
 // BatchPatch handles PATCH requests for multiple entities, partially updating existing entities.
 func (r *ApiRouter[T]) BatchPatch(c *gin.Context) {
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
 	var entities []*T
 	if err := UnserializeBodyAndMerge_A(c, &entities); err != nil {
 		//unserializable
@@ -20,11 +26,15 @@ func (r *ApiRouter[T]) BatchPatch(c *gin.Context) {
 		c.AbortWithStatusJSON(errors.ErrBadFormat.Code, errors.ErrBadFormat.Message)
 		return
 	}
-	var ids []entity.ID
+	if err := r.RateLimitCheck(c, route.BatchPatch, len(entities)); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	var ids []entity.Identifier
 	var preexistingEntities []*T
 	for _, e := range entities {
-		if (*e).GetId() != entity.NullId {
-			ids = append(ids, (*e).GetId())
+		if id := (*e).GetIdentifier(); !id.IsNull() {
+			ids = append(ids, id)
 		} else {
 			//null id is not
 			c.AbortWithStatusJSON(errors.ErrBadFormat.Code, errors.ErrBadFormat.Message)
@@ -32,7 +42,7 @@ func (r *ApiRouter[T]) BatchPatch(c *gin.Context) {
 		}
 	}
 	//try a batch get
-	preexistingEntities, err := r.Orm.FindByIDs(ids)
+	preexistingEntities, err = requestOrm.FindByIDs(ids)
 	if err != nil {
 		//check only for database issue, non existing entities are not a problem
 		if err != errors.NotAllItemFound {
@@ -57,16 +67,32 @@ func (r *ApiRouter[T]) BatchPatch(c *gin.Context) {
 		return
 	}
 
-	if err := UnserializeBodyAndMerge_A(c, &preexistingEntities); err != nil {
+	if err := r.AccessCheck(c, route.BatchPatch, preexistingEntities...); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	inputGroups, err := r.GetEffectiveInputSerializationGroups(c, route.BatchPatch)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrInternal.Code, errors.ErrInternal.Message)
+		return
+	}
+	before := snapshot(preexistingEntities...)
+	if err := UnserializeBodyAndMerge_A(c, &preexistingEntities, inputGroups...); err != nil {
 		//unserializable
 		c.AbortWithStatusJSON(errors.ErrBadFormat.Code, errors.ErrBadFormat.Message)
 		return
 	}
 
-	if err := r.Orm.Update(preexistingEntities...); err != nil {
-		c.AbortWithStatusJSON(errors.ErrDatabaseIssue.Code, errors.ErrDatabaseIssue.Message)
+	r.stamp(c, preexistingEntities...)
+	if err := r.write(c, requestOrm, route.BatchPatch, before, preexistingEntities, func(o *orm.ORM[T]) error {
+		return o.Update(preexistingEntities...)
+	}); err != nil {
+		apiErr := writeError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
 		return
 	}
+	r.invalidateResponseCache(preexistingEntities...)
+	r.recordWrite(c, route.BatchPatch, before, preexistingEntities)
 	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
 	if err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
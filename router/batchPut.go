@@ -4,12 +4,18 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/philiphil/restman/configuration"
 	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
 	"github.com/philiphil/restman/orm/entity"
 	"github.com/philiphil/restman/route"
 )
 
 // BatchPut handles PUT requests for multiple entities, fully replacing existing entities.
 func (r *ApiRouter[T]) BatchPut(c *gin.Context) {
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
 	var entities []*T
 	if err := UnserializeBodyAndMerge_A(c, &entities); err != nil {
 		//unserializable
@@ -20,22 +26,31 @@ func (r *ApiRouter[T]) BatchPut(c *gin.Context) {
 		c.AbortWithStatusJSON(errors.ErrBadFormat.Code, errors.ErrBadFormat.Message)
 		return
 	}
+	if err := r.RateLimitCheck(c, route.BatchPut, len(entities)); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
 	//I must check the id's first
 	//All of them must have an id and if it's already in use, I must check write permissions
 
-	var ids []entity.ID
+	var ids []entity.Identifier
 	var preexistingEntities []*T
 	for _, e := range entities {
-		if (*e).GetId() != entity.NullId {
-			ids = append(ids, (*e).GetId())
+		if id := (*e).GetIdentifier(); !id.IsNull() {
+			ids = append(ids, id)
 		} else {
 			//null id is not allowed
 			c.AbortWithStatusJSON(errors.ErrBadFormat.Code, errors.ErrBadFormat.Message)
 			return
 		}
 	}
+	inputGroups, err := r.GetEffectiveInputSerializationGroups(c, route.BatchPut)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrInternal.Code, errors.ErrInternal.Message)
+		return
+	}
 	//try a batch get
-	preexistingEntities, err := r.Orm.FindByIDs(ids)
+	preexistingEntities, err = requestOrm.FindByIDs(ids)
 	if err != nil {
 		//check only for database issue, non existing entities are not a problem
 		if err != errors.NotAllItemFound {
@@ -43,6 +58,33 @@ func (r *ApiRouter[T]) BatchPut(c *gin.Context) {
 			return
 		}
 	}
+	if len(inputGroups) > 0 {
+		//as with Put, the body is merged into the stored entities so that the fields outside of the groups are kept
+		stored := make(map[string]*T, len(preexistingEntities))
+		for _, e := range preexistingEntities {
+			stored[(*e).GetIdentifier().String()] = e
+		}
+		restricted := make([]*T, len(ids))
+		for i, id := range ids {
+			if e, ok := stored[id.String()]; ok {
+				copied := *e
+				restricted[i] = &copied
+			} else {
+				fresh := r.Orm.NewEntity()
+				restricted[i] = &fresh
+			}
+		}
+		if err := UnserializeBodyAndMerge_A(c, &restricted, inputGroups...); err != nil || len(restricted) != len(ids) {
+			c.AbortWithStatusJSON(errors.ErrBadFormat.Code, errors.ErrBadFormat.Message)
+			return
+		}
+		for i, e := range restricted {
+			var cast entity.Entity = *e
+			converted, _ := cast.SetId(ids[i]).(T)
+			restricted[i] = &converted
+		}
+		entities = restricted
+	}
 	//check if preexisting entities are writable
 	if len(preexistingEntities) > 0 {
 		for _, e := range preexistingEntities {
@@ -53,10 +95,20 @@ func (r *ApiRouter[T]) BatchPut(c *gin.Context) {
 		}
 	}
 
-	if err := r.Orm.Update(entities...); err != nil {
-		c.AbortWithStatusJSON(errors.ErrDatabaseIssue.Code, errors.ErrDatabaseIssue.Message)
+	if err := r.AccessCheck(c, route.BatchPut, preexistingEntities...); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	r.stamp(c, entities...)
+	if err := r.write(c, requestOrm, route.BatchPut, preexistingEntities, entities, func(o *orm.ORM[T]) error {
+		return o.Update(entities...)
+	}); err != nil {
+		apiErr := writeError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
 		return
 	}
+	r.invalidateResponseCache(entities...)
+	r.recordWrite(c, route.BatchPut, preexistingEntities, entities)
 	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
 	if err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
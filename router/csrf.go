@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/security"
+)
+
+// CSRFCheck runs the CSRF protection of every firewall implementing security.CSRFProtection.
+// It is part of AccessCheck, so that every route of the ApiRouter is protected, secured by an expression or not.
+func (r *ApiRouter[T]) CSRFCheck(c *gin.Context) error {
+	for _, firewall := range r.Firewalls {
+		if protection, ok := firewall.(security.CSRFProtection); ok {
+			if err := protection.CheckCSRF(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
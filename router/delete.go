@@ -3,23 +3,39 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/route"
 )
 
 // Delete handles HTTP DELETE requests to remove a single entity by ID.
 func (r *ApiRouter[T]) Delete(c *gin.Context) {
-	id := c.Param("id")
-	object, err := r.Orm.GetByID(id)
+	requestOrm, err := r.RequestOrm(c)
 	if err != nil {
-		c.AbortWithStatusJSON(errors.ErrNotFound.Code, errors.ErrNotFound.Message)
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	id := r.IdParam(c)
+	object, err := requestOrm.GetByID(id)
+	if err != nil {
+		apiErr := lookupError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
 		return
 	}
 	if err = r.WritingCheck(c, object); err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
 	}
-	if err = r.Orm.Delete(object); err != nil {
+	if err = r.AccessCheck(c, route.Delete, object); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	if err = r.write(c, requestOrm, route.Delete, []*T{object}, nil, func(o *orm.ORM[T]) error {
+		return o.Delete(object)
+	}); err != nil {
 		c.AbortWithStatusJSON(500, "Database issue")
 		return
 	}
+	r.invalidateResponseCache(object)
+	r.recordWrite(c, route.Delete, []*T{object}, nil)
 	c.JSON(204, nil)
 }
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+)
+
+//go:embed docsui
+var docsAssets embed.FS
+
+var docsPage = template.Must(template.ParseFS(docsAssets, "docsui/index.html"))
+
+// DocsUI serves an interactive documentation of the OpenAPI document, its assets are compiled into the binary
+// To create a DocsUI, you should use the NewDocsUI function
+type DocsUI struct {
+	// Path serves the page, its assets are served under it (default: "/api/docs")
+	Path string
+	// SpecURL is the URL of the OpenAPI document the page loads, the JSON one of the OpenAPI by default
+	SpecURL string
+	Title   string
+	// Disabled registers no route, to keep the documentation out of production
+	Disabled bool
+}
+
+// NewDocsUI creates a DocsUI browsing the document of an OpenAPI, which must be served too.
+func NewDocsUI(docs *OpenAPI) *DocsUI {
+	return &DocsUI{
+		Path:    "/api/docs",
+		SpecURL: docs.Path + ".json",
+		Title:   docs.Info.Title,
+	}
+}
+
+// AllowRoutes adds the routes of the page and of its assets to the gin router, unless the DocsUI is disabled
+func (d *DocsUI) AllowRoutes(router *gin.Engine) {
+	if d.Disabled {
+		return
+	}
+	router.GET(d.Path, d.page)
+	assets, _ := fs.Sub(docsAssets, "docsui")
+	fs.WalkDir(assets, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || name == "index.html" {
+			return err
+		}
+		data, err := fs.ReadFile(assets, name)
+		if err != nil {
+			return err
+		}
+		contentType := mime.TypeByExtension(path.Ext(name))
+		router.GET(d.Path+"/"+name, func(c *gin.Context) {
+			c.Header("Cache-Control", "no-cache")
+			c.Data(http.StatusOK, contentType, data)
+		})
+		return nil
+	})
+}
+
+func (d *DocsUI) page(c *gin.Context) {
+	var page bytes.Buffer
+	err := docsPage.Execute(&page, map[string]string{
+		"Title":   d.Title,
+		"SpecURL": d.SpecURL,
+		"Assets":  d.Path + "/",
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrInternal.Code, errors.ErrInternal.Message)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
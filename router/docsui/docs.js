@@ -0,0 +1,309 @@
+// Interactive documentation of an OpenAPI 3.1 document, served by restman's DocsUI.
+(function () {
+  "use strict";
+
+  var methods = ["get", "post", "put", "patch", "delete", "head", "options"];
+  var specURL = document.currentScript.dataset.spec;
+  var spec;
+
+  function el(tag, attrs, children) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (key) {
+      if (key === "text") {
+        node.textContent = attrs[key];
+      } else {
+        node.setAttribute(key, attrs[key]);
+      }
+    });
+    (children || []).forEach(function (child) {
+      if (child) {
+        node.appendChild(typeof child === "string" ? document.createTextNode(child) : child);
+      }
+    });
+    return node;
+  }
+
+  // resolve follows a local reference, like "#/components/schemas/Book"
+  function resolve(object) {
+    var depth = 0;
+    while (object && object.$ref && depth++ < 16) {
+      object = object.$ref.replace(/^#\//, "").split("/").reduce(function (node, key) {
+        return node && node[key.replace(/~1/g, "/").replace(/~0/g, "~")];
+      }, spec);
+    }
+    return object || {};
+  }
+
+  // example builds a value matching a schema, to prefill the request bodies
+  function example(schema, depth) {
+    schema = resolve(schema);
+    if (depth > 6) {
+      return null;
+    }
+    if (schema.default !== undefined) {
+      return schema.default;
+    }
+    if (schema.enum) {
+      return schema.enum[0];
+    }
+    if (schema.oneOf) {
+      return example(schema.oneOf[0], depth + 1);
+    }
+    var type = Array.isArray(schema.type) ? schema.type[0] : schema.type;
+    switch (type) {
+      case "object":
+        var value = {};
+        Object.keys(schema.properties || {}).forEach(function (name) {
+          value[name] = example(schema.properties[name], depth + 1);
+        });
+        return value;
+      case "array":
+        return [example(schema.items || {}, depth + 1)];
+      case "integer":
+      case "number":
+        return schema.minimum !== undefined ? schema.minimum : 0;
+      case "boolean":
+        return false;
+      case "string":
+        if (schema.format === "date-time") {
+          return new Date(0).toISOString();
+        }
+        if (schema.format === "uuid") {
+          return "00000000-0000-0000-0000-000000000000";
+        }
+        return "";
+      case "null":
+        return null;
+    }
+    return {};
+  }
+
+  function credential(name) {
+    return localStorage.getItem("restman-docs-" + name) || "";
+  }
+
+  function renderAuth() {
+    var schemes = (spec.components || {}).securitySchemes || {};
+    var names = Object.keys(schemes);
+    if (!names.length) {
+      return;
+    }
+    var container = document.getElementById("schemes");
+    names.forEach(function (name) {
+      var scheme = schemes[name];
+      var hint = scheme.type === "http" && scheme.scheme === "basic" ? "user:password" :
+        scheme.type === "http" ? "token" : scheme.name + " (" + scheme.in + ")";
+      var input = el("input", { placeholder: hint, value: credential(name) });
+      input.addEventListener("change", function () {
+        localStorage.setItem("restman-docs-" + name, input.value);
+      });
+      container.appendChild(el("label", {}, [el("h4", { text: name }), scheme.description ? el("p", { text: scheme.description }) : null, input]));
+    });
+    document.getElementById("auth").hidden = false;
+  }
+
+  // authenticate adds the credentials of the first requirement of the operation that has them all
+  function authenticate(operation, headers) {
+    var schemes = (spec.components || {}).securitySchemes || {};
+    (operation.security || []).some(function (requirement) {
+      var names = Object.keys(requirement);
+      if (!names.length || !names.every(credential)) {
+        return false;
+      }
+      names.forEach(function (name) {
+        var scheme = schemes[name] || {};
+        var value = credential(name);
+        if (scheme.type === "http" && scheme.scheme === "basic") {
+          headers.set("Authorization", "Basic " + btoa(value));
+        } else if (scheme.type === "http") {
+          headers.set("Authorization", "Bearer " + value);
+        } else if (scheme.in === "header") {
+          headers.set(scheme.name, value);
+        }
+      });
+      return true;
+    });
+  }
+
+  // query encodes a query parameter following its style
+  function query(parameter, raw, search) {
+    if (parameter.style === "deepObject") {
+      var object = JSON.parse(raw);
+      Object.keys(object).forEach(function (key) {
+        search.append(parameter.name + "[" + key + "]", object[key]);
+      });
+      return;
+    }
+    search.append(parameter.name, raw);
+  }
+
+  function renderOperation(path, method, operation) {
+    var inputs = [];
+    var rows = (operation.parameters || []).map(function (parameter) {
+      parameter = resolve(parameter);
+      var input = el("input", { placeholder: parameter.style === "deepObject" ? '{"field":"asc"}' : "" });
+      inputs.push({ parameter: parameter, input: input });
+      return el("tr", {}, [
+        el("td", {}, [el("code", { text: parameter.name }), parameter.required ? " *" : ""]),
+        el("td", { text: parameter.in }),
+        el("td", { text: parameter.description || "" }),
+        el("td", {}, [input])
+      ]);
+    });
+
+    var body, contentType;
+    if (operation.requestBody) {
+      var content = operation.requestBody.content || {};
+      contentType = el("select", {}, Object.keys(content).map(function (type) {
+        return el("option", { value: type, text: type });
+      }));
+      var first = content[Object.keys(content)[0]] || {};
+      body = el("textarea", {}, [JSON.stringify(example(first.schema, 0), null, 2)]);
+    }
+
+    var accepts = [];
+    Object.keys(operation.responses || {}).forEach(function (status) {
+      Object.keys(resolve(operation.responses[status]).content || {}).forEach(function (type) {
+        if (accepts.indexOf(type) < 0) {
+          accepts.push(type);
+        }
+      });
+    });
+    var accept = el("select", {}, accepts.map(function (type) {
+      return el("option", { value: type, text: type });
+    }));
+
+    var result = el("div");
+    var execute = el("button", { type: "button", text: "Execute" });
+    execute.addEventListener("click", function () {
+      var url = path;
+      var search = new URLSearchParams();
+      var headers = new Headers();
+      try {
+        inputs.forEach(function (item) {
+          var raw = item.input.value;
+          if (raw === "") {
+            return;
+          }
+          if (item.parameter.in === "path") {
+            url = url.replace("{" + item.parameter.name + "}", encodeURIComponent(raw));
+          } else if (item.parameter.in === "query") {
+            query(item.parameter, raw, search);
+          } else if (item.parameter.in === "header") {
+            headers.set(item.parameter.name, raw);
+          }
+        });
+      } catch (err) {
+        result.replaceChildren(el("p", { class: "error", text: err.message }));
+        return;
+      }
+      if (accepts.length) {
+        headers.set("Accept", accept.value);
+      }
+      if (body) {
+        headers.set("Content-Type", contentType.value);
+      }
+      authenticate(operation, headers);
+      var server = (spec.servers || [])[0];
+      var target = (server ? server.url.replace(/\/$/, "") : "") + url + (search.toString() ? "?" + search : "");
+      fetch(target, { method: method.toUpperCase(), headers: headers, body: body ? body.value : undefined, credentials: "same-origin" })
+        .then(function (response) {
+          return response.text().then(function (text) {
+            var shown = text;
+            try {
+              shown = JSON.stringify(JSON.parse(text), null, 2);
+            } catch (ignored) {
+              // not JSON, shown as it is
+            }
+            var headerLines = [];
+            response.headers.forEach(function (value, name) {
+              headerLines.push(name + ": " + value);
+            });
+            result.replaceChildren(
+              el("h4", {}, [el("span", { class: "status", text: response.status + " " + response.statusText }), " " + method.toUpperCase() + " " + target]),
+              el("pre", { text: headerLines.join("\n") }),
+              el("pre", { text: shown })
+            );
+          });
+        })
+        .catch(function (err) {
+          result.replaceChildren(el("p", { class: "error", text: err.message }));
+        });
+    });
+
+    var responses = el("table", {}, Object.keys(operation.responses || {}).sort().map(function (status) {
+      var response = resolve(operation.responses[status]);
+      var content = response.content || {};
+      var schema = content[Object.keys(content)[0]];
+      return el("tr", {}, [
+        el("td", { text: status }),
+        el("td", { text: response.description || "" }),
+        el("td", {}, [schema && schema.schema ? el("pre", { text: JSON.stringify(example(schema.schema, 0), null, 2) }) : null])
+      ]);
+    }));
+
+    var summary = el("summary", {}, [
+      el("span", { class: "method " + method, text: method }),
+      el("span", { class: "path" + ((operation.security || []).length ? " locked" : ""), text: path }),
+      el("span", { class: "summary", text: operation.summary || "" })
+    ]);
+    return el("details", { class: "operation", id: operation.operationId || method + path }, [summary, el("div", {}, [
+      operation.description ? el("p", { text: operation.description }) : null,
+      rows.length ? el("h4", { text: "Parameters" }) : null,
+      rows.length ? el("table", {}, rows) : null,
+      body ? el("h4", { text: "Request body" }) : null,
+      contentType, body,
+      accepts.length ? el("h4", { text: "Accept" }) : null,
+      accepts.length ? accept : null,
+      el("p", {}, [execute]),
+      result,
+      el("h4", { text: "Responses" }),
+      responses
+    ])]);
+  }
+
+  function render() {
+    document.getElementById("title").textContent = spec.info.title + " " + spec.info.version;
+    document.getElementById("description").textContent = spec.info.description || "";
+    renderAuth();
+
+    var byTag = {};
+    Object.keys(spec.paths || {}).sort().forEach(function (path) {
+      var item = spec.paths[path];
+      methods.forEach(function (method) {
+        if (!item[method]) {
+          return;
+        }
+        var tag = (item[method].tags || ["default"])[0];
+        (byTag[tag] = byTag[tag] || []).push(renderOperation(path, method, item[method]));
+      });
+    });
+
+    var main = document.getElementById("operations");
+    main.replaceChildren();
+    var described = (spec.tags || []).map(function (tag) { return tag.name; });
+    Object.keys(byTag).sort(function (a, b) {
+      return (described.indexOf(a) + 1 || Infinity) - (described.indexOf(b) + 1 || Infinity) || a.localeCompare(b);
+    }).forEach(function (tag) {
+      main.appendChild(el("h2", { text: tag }));
+      byTag[tag].forEach(function (operation) {
+        main.appendChild(operation);
+      });
+    });
+  }
+
+  fetch(specURL, { headers: { Accept: "application/json" }, credentials: "same-origin" })
+    .then(function (response) {
+      if (!response.ok) {
+        throw new Error(specURL + ": " + response.status + " " + response.statusText);
+      }
+      return response.json();
+    })
+    .then(function (document_) {
+      spec = document_;
+      render();
+    })
+    .catch(function (err) {
+      document.getElementById("operations").replaceChildren(el("p", { class: "error", text: err.message }));
+    });
+})();
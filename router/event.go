@@ -0,0 +1,64 @@
+package router
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/audit"
+	"github.com/philiphil/restman/event"
+	"github.com/philiphil/restman/format"
+	"github.com/philiphil/restman/route"
+	"github.com/philiphil/restman/serializer"
+)
+
+// SetEventBus publishes an event.Event on bus after every write of this ApiRouter, its payload is serialized with groups.
+// An event.TransactionalBus, such as gormoutbox.Outbox, stores the events in the transaction of the write.
+func (r *ApiRouter[T]) SetEventBus(bus event.Bus, groups ...string) {
+	r.EventBus = bus
+	r.EventGroups = groups
+}
+
+// publish publishes the event of a successful write on a non transactional bus.
+// The write is already done, a failing bus is reported on the gin context without changing the response.
+func (r *ApiRouter[T]) publish(c *gin.Context, routeType route.RouteType, before []*T, after []*T) {
+	if r.EventBus == nil {
+		return
+	}
+	if _, ok := r.EventBus.(event.TransactionalBus); ok {
+		return
+	}
+	e, err := r.event(c, routeType, before, after)
+	if err == nil {
+		err = r.EventBus.Publish(e)
+	}
+	if err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// event describes a write, its payload holds the written entities or the deleted ones.
+func (r *ApiRouter[T]) event(c *gin.Context, routeType route.RouteType, before []*T, after []*T) (event.Event, error) {
+	e := event.Event{
+		ID:        audit.NewRequestID(),
+		Time:      time.Now().UTC(),
+		RequestID: RequestID(c),
+		User:      r.recordedUser(c),
+		Resource:  r.ResourceName(),
+		Operation: writeOperation(routeType, before),
+		Batch:     routeType.IsBatch(),
+	}
+	objects := after
+	if len(objects) == 0 {
+		objects = before
+	}
+	for _, object := range objects {
+		e.IDs = append(e.IDs, (*object).GetIdentifier().String())
+	}
+	payload, err := serializer.NewSerializer(format.JSON).Serialize(objects, r.EventGroups...)
+	if err != nil {
+		return e, err
+	}
+	e.Payload = json.RawMessage(payload)
+	return e, nil
+}
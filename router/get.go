@@ -8,9 +8,22 @@ import (
 
 // Get handles HTTP GET requests to retrieve a single entity by ID.
 func (r *ApiRouter[T]) Get(c *gin.Context) {
-	object, err := r.Orm.GetByID(c.Param("id"))
+	if err := r.authenticationCheck(c, route.Get); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	if r.serveFromResponseCache(c, route.Get) {
+		return
+	}
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	object, err := requestOrm.GetByID(r.IdParam(c))
 	if err != nil {
-		c.AbortWithStatusJSON(errors.ErrNotFound.Code, errors.ErrNotFound.Message)
+		apiErr := lookupError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
 		return
 	}
 
@@ -19,6 +32,10 @@ func (r *ApiRouter[T]) Get(c *gin.Context) {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
 	}
+	if err = r.AccessCheck(c, route.Get, object); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
 
 	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
 	if err != nil {
@@ -26,15 +43,15 @@ func (r *ApiRouter[T]) Get(c *gin.Context) {
 		return
 	}
 
-	groups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get)
+	groups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get, *object)
 	if err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
 	}
 
-	c.Render(200, SerializerRenderer{
+	c.Render(200, r.withResponseCache(c, SerializerRenderer{
 		Data:   object,
 		Format: responseFormat,
 		Groups: groups,
-	})
+	}, r.itemTags(object)...))
 }
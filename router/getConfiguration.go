@@ -81,12 +81,14 @@ func (r *ApiRouter[T]) GetRouteWideConfigurationFallback(configurationType confi
 	return configuration.Configuration{}, errors.ApiError{Code: errors.ErrInternal.Code, Message: errors.ErrInternal.Message}
 }
 
+// IsOutputSerializationGroupOverwriteEnabled tells if the router lets clients narrow the output groups.
+// It only depends on the configuration, never on the request.
 func (r *ApiRouter[T]) IsOutputSerializationGroupOverwriteEnabled(c *gin.Context) (bool, error) {
 	groupOverwriteConf, err := r.GetConfiguration(configuration.OutputSerializationGroupOverwriteClientControlType, route.GetList)
 	if err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(c.DefaultQuery(groupOverwriteConf.Values[0], "false"))
+	return strconv.ParseBool(groupOverwriteConf.Values[0])
 }
 
 func (r *ApiRouter[T]) GetOverwriteGroups(c *gin.Context, routeType route.RouteType) ([]string, error) {
@@ -102,12 +104,18 @@ func (r *ApiRouter[T]) GetOverwriteGroups(c *gin.Context, routeType route.RouteT
 	return groups, nil
 }
 
-func (r *ApiRouter[T]) GetEffectiveOutputSerializationGroups(c *gin.Context, routeType route.RouteType) ([]string, error) {
+// GetEffectiveOutputSerializationGroups returns the groups the response is serialized with.
+// The configured groups go through the GroupResolver, then the client overwrite, if enabled, can only narrow them.
+// object is the entity being serialized, none for collections.
+func (r *ApiRouter[T]) GetEffectiveOutputSerializationGroups(c *gin.Context, routeType route.RouteType, object ...any) ([]string, error) {
 	groups, err := r.GetConfiguration(configuration.OutputSerializationGroupsType, routeType)
 	if err != nil {
 		return nil, err
 	}
-	effectiveGroups := groups.Values
+	effectiveGroups, err := r.resolveGroups(c, routeType, false, groups.Values, object...)
+	if err != nil {
+		return nil, err
+	}
 
 	enabled, err := r.IsOutputSerializationGroupOverwriteEnabled(c)
 	if err != nil {
@@ -119,7 +127,7 @@ func (r *ApiRouter[T]) GetEffectiveOutputSerializationGroups(c *gin.Context, rou
 			return nil, err
 		}
 		if len(overwriteGroups) > 0 {
-			effectiveGroups = overwriteGroups
+			effectiveGroups = narrowGroups(effectiveGroups, overwriteGroups)
 		}
 	}
 	return effectiveGroups, nil
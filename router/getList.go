@@ -2,6 +2,7 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/cache"
 	"github.com/philiphil/restman/errors"
 	"github.com/philiphil/restman/format"
 	"github.com/philiphil/restman/route"
@@ -9,6 +10,18 @@ import (
 
 // GetList handles HTTP GET requests to retrieve a collection of entities with optional pagination and sorting.
 func (r *ApiRouter[T]) GetList(c *gin.Context) {
+	if err := r.AccessCheck(c, route.GetList); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	if r.serveFromResponseCache(c, route.GetList) {
+		return
+	}
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
 	paginate, err := r.IsPaginationEnabled(c)
 	if err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
@@ -44,12 +57,12 @@ func (r *ApiRouter[T]) GetList(c *gin.Context) {
 
 	var objects []T
 	if paginate {
-		objects, err = r.Orm.GetPaginatedList(itemPerPage, page, sortOrder)
+		objects, err = requestOrm.GetPaginatedList(itemPerPage, page, sortOrder)
 		if err != nil {
 			c.AbortWithStatusJSON(errors.ErrDatabaseIssue.Code, errors.ErrDatabaseIssue.Message)
 			return
 		}
-		count, err := r.Orm.Count()
+		count, err := requestOrm.Count()
 		if err != nil {
 			c.AbortWithStatusJSON(errors.ErrDatabaseIssue.Code, errors.ErrDatabaseIssue.Message)
 			return
@@ -62,26 +75,26 @@ func (r *ApiRouter[T]) GetList(c *gin.Context) {
 		if responseFormat == format.JSONLD {
 			c.Render(
 				200,
-				SerializerRenderer{
+				r.withResponseCache(c, SerializerRenderer{
 					Data:   JsonldCollection(objects, c.Request.URL.String(), page+1, params, int((count+int64(itemPerPage)-1)/int64(itemPerPage))),
 					Format: responseFormat,
 					Groups: groups,
-				},
+				}, cache.CollectionTag(r.ResourceName())),
 			)
 			return
 		}
 	} else {
-		objects, err = r.Orm.GetAll(sortOrder)
+		objects, err = requestOrm.GetAll(sortOrder)
 		if err != nil {
 			c.AbortWithStatusJSON(errors.ErrDatabaseIssue.Code, errors.ErrDatabaseIssue.Message)
 		}
 	}
 
 	c.Render(200,
-		SerializerRenderer{
+		r.withResponseCache(c, SerializerRenderer{
 			Data:   objects,
 			Format: responseFormat,
 			Groups: groups,
-		})
+		}, cache.CollectionTag(r.ResourceName())))
 
 }
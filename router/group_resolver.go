@@ -0,0 +1,84 @@
+package router
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/route"
+	"github.com/philiphil/restman/security"
+)
+
+// GroupContext describes the serialization a GroupResolver computes the groups of
+type GroupContext struct {
+	// User is the authenticated user, nil for anonymous requests
+	User security.User
+	// Route is the route being served
+	Route route.RouteType
+	// Input is true when deserializing the request body, false when serializing the response
+	Input bool
+	// Object is the entity being (de)serialized, nil for collections
+	Object any
+	// Groups are the groups configured for the route, an empty list means every field
+	Groups []string
+}
+
+// GroupResolver computes the serialization groups of a request, usually by adding groups to the configured ones
+// for example admins could get an "admin" group exposing fields hidden from regular users
+type GroupResolver func(c *gin.Context, context GroupContext) []string
+
+// SetGroupResolver sets the hook computing the input and output serialization groups of every request.
+// Responses are then cached per user.
+func (r *ApiRouter[T]) SetGroupResolver(resolver GroupResolver) {
+	r.GroupResolver = resolver
+}
+
+// resolveGroups applies the GroupResolver to the configured groups
+func (r *ApiRouter[T]) resolveGroups(c *gin.Context, routeType route.RouteType, input bool, groups []string, object ...any) ([]string, error) {
+	if r.GroupResolver == nil {
+		return groups, nil
+	}
+	user, err := r.FirewallCheck(c)
+	if err != nil {
+		return nil, err
+	}
+	context := GroupContext{
+		User:   user,
+		Route:  routeType,
+		Input:  input,
+		Groups: slices.Clone(groups),
+	}
+	if len(object) > 0 {
+		context.Object = object[0]
+	}
+	return r.GroupResolver(c, context), nil
+}
+
+// GetEffectiveInputSerializationGroups returns the groups the body of a write request is deserialized with.
+// object is the entity the body is merged into.
+func (r *ApiRouter[T]) GetEffectiveInputSerializationGroups(c *gin.Context, routeType route.RouteType, object ...any) ([]string, error) {
+	groups, err := r.GetConfiguration(configuration.InputSerializationGroupsType, routeType)
+	if err != nil {
+		return nil, err
+	}
+	return r.resolveGroups(c, routeType, true, groups.Values, object...)
+}
+
+// narrowGroups restricts the allowed groups to the requested ones, the client can never widen what it is allowed.
+// Requested groups outside of the allowed ones are ignored, allowed groups are kept if none remains.
+func narrowGroups(allowed []string, requested []string) []string {
+	if len(allowed) == 0 {
+		//every field is allowed, any group narrows it
+		return requested
+	}
+	narrowed := make([]string, 0, len(requested))
+	for _, group := range requested {
+		if slices.Contains(allowed, group) && !slices.Contains(narrowed, group) {
+			narrowed = append(narrowed, group)
+		}
+	}
+	if len(narrowed) == 0 {
+		return allowed
+	}
+	return narrowed
+}
@@ -11,9 +11,19 @@ import (
 
 // Head handles HTTP HEAD requests to retrieve entity metadata without the response body.
 func (r *ApiRouter[T]) Head(c *gin.Context) {
-	object, err := r.Orm.GetByID(c.Param("id"))
+	requestOrm, err := r.RequestOrm(c)
 	if err != nil {
-		c.AbortWithStatusJSON(errors.ErrNotFound.Code, errors.ErrNotFound.Message)
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	object, err := requestOrm.GetByID(r.IdParam(c))
+	if err != nil {
+		apiErr := lookupError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+		return
+	}
+	if err = r.AccessCheck(c, route.Head, object); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
 	}
 	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
@@ -23,7 +33,7 @@ func (r *ApiRouter[T]) Head(c *gin.Context) {
 	}
 	s := serializer.NewSerializer(responseFormat)
 
-	groups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get)
+	groups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get, *object)
 	if err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// lookupError converts the error of a lookup by id into an ApiError
+// a malformed id is a bad request, anything else means the entity does not exist
+func lookupError(err error) errors.ApiError {
+	if err == errors.InvalidIdentifier {
+		return errors.ErrBadRequest
+	}
+	return errors.ErrNotFound
+}
+
+// GetIdentifiers parses the ids of a batch request into the identifier type of the entity.
+// A single malformed id makes the whole request a bad request.
+func (r *ApiRouter[T]) GetIdentifiers(c *gin.Context) ([]entity.Identifier, error) {
+	ids, err := r.Orm.ParseIds(r.GetIds(c))
+	if err != nil {
+		return nil, errors.ErrBadRequest
+	}
+	return ids, nil
+}
+
+// ItemPath returns the route parameters identifying a single entity.
+// It is "/:id" unless the entity has a composite identifier, which gets one parameter per key part.
+func (r *ApiRouter[T]) ItemPath() string {
+	composite, ok := r.Orm.NewEntity().GetIdentifier().(entity.CompositeIdentifier)
+	if !ok {
+		return "/:id"
+	}
+	var builder strings.Builder
+	for _, key := range composite.Keys() {
+		builder.WriteString("/:")
+		builder.WriteString(key)
+	}
+	return builder.String()
+}
+
+// IdParam returns the id of the requested entity in the string form parsed by its identifier.
+func (r *ApiRouter[T]) IdParam(c *gin.Context) string {
+	composite, ok := r.Orm.NewEntity().GetIdentifier().(entity.CompositeIdentifier)
+	if !ok {
+		return c.Param("id")
+	}
+	keys := composite.Keys()
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = c.Param(key)
+	}
+	return entity.JoinCompositeId(parts...)
+}
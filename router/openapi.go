@@ -0,0 +1,518 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/format"
+	"github.com/philiphil/restman/jsonschema"
+	"github.com/philiphil/restman/openapi"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/revision"
+	"github.com/philiphil/restman/route"
+)
+
+// documentedFormats are the formats of the bodies, in requests and responses
+var documentedFormats = []format.Format{format.JSON, format.JSONLD, format.XML, format.CSV}
+
+// Documented is an interface that any ApiRouter implements to be described in an OpenAPI document
+type Documented interface {
+	// DescribeRoutes adds the operations of the routes and of the subresources to the document
+	// parentRoute and parentName are the item route and the operation prefix of the parent of a subresource, empty otherwise
+	DescribeRoutes(doc *openapi.Document, parentRoute string, parentName string)
+}
+
+// OpenAPI serves the OpenAPI document of ApiRouters
+// To create an OpenAPI, you should use the NewOpenAPI function
+type OpenAPI struct {
+	Info    openapi.Info
+	Servers []openapi.Server
+	// Path serves the document as JSON, or as YAML to the clients accepting it, Path+".json" and Path+".yaml" serve either (default: "/api/openapi")
+	Path string
+
+	routers []Documented
+}
+
+// NewOpenAPI creates an OpenAPI describing the given ApiRouters.
+func NewOpenAPI(info openapi.Info, routers ...Documented) *OpenAPI {
+	return &OpenAPI{
+		Info:    info,
+		Path:    "/api/openapi",
+		routers: routers,
+	}
+}
+
+// Register adds ApiRouters to the document.
+func (o *OpenAPI) Register(routers ...Documented) {
+	o.routers = append(o.routers, routers...)
+}
+
+// Document builds the document of the ApiRouters, as they are configured when it is called.
+func (o *OpenAPI) Document() *openapi.Document {
+	doc := openapi.NewDocument(o.Info)
+	doc.Servers = o.Servers
+	for _, router := range o.routers {
+		router.DescribeRoutes(doc, "", "")
+	}
+	return doc
+}
+
+// AllowRoutes adds the routes serving the document to the gin router
+func (o *OpenAPI) AllowRoutes(router *gin.Engine) {
+	router.GET(o.Path, func(c *gin.Context) {
+		o.serve(c, strings.Contains(c.GetHeader("Accept"), "yaml"))
+	})
+	router.GET(o.Path+".json", func(c *gin.Context) {
+		o.serve(c, false)
+	})
+	router.GET(o.Path+".yaml", func(c *gin.Context) {
+		o.serve(c, true)
+	})
+}
+
+func (o *OpenAPI) serve(c *gin.Context, yaml bool) {
+	doc := o.Document()
+	data, err := doc.JSON()
+	contentType := "application/json"
+	if yaml {
+		data, err = doc.YAML()
+		contentType = "application/yaml"
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrInternal.Code, errors.ErrInternal.Message)
+		return
+	}
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// DescribeRoutes adds the operations of the routes of this ApiRouter, and of its subresources, to the document
+func (r *ApiRouter[T]) DescribeRoutes(doc *openapi.Document, parentRoute string, parentName string) {
+	name := r.GetSubresourceName()
+	operationPrefix := name
+	if parentName != "" {
+		operationPrefix = parentName + "_" + name
+	}
+	doc.AddTag(openapi.Tag{Name: name, Description: r.ResourceName()})
+
+	routeTypes := make([]route.RouteType, 0, len(r.Routes))
+	for routeType := range r.Routes {
+		routeTypes = append(routeTypes, routeType)
+	}
+	slices.Sort(routeTypes)
+	for _, routeType := range routeTypes {
+		base := parentRoute + "/" + name
+		if parentRoute == "" {
+			base = r.Route(routeType)
+		}
+		r.describeRoute(doc, routeType, base, operationPrefix)
+	}
+
+	base := parentRoute + "/" + name
+	if parentRoute == "" {
+		base = r.Route()
+	}
+	for _, subresource := range r.Subresources {
+		if documented, ok := subresource.(Documented); ok {
+			documented.DescribeRoutes(doc, base+r.ItemPath(), operationPrefix)
+		}
+	}
+}
+
+// describeRoute adds the operation of a route, base is the collection path of the resource
+func (r *ApiRouter[T]) describeRoute(doc *openapi.Document, routeType route.RouteType, base string, operationPrefix string) {
+	resource := r.ResourceName()
+	itemPath := r.ItemPath()
+	entityType := reflect.TypeFor[T]()
+	_, batchGet := r.Routes[route.BatchGet]
+	_, getList := r.Routes[route.GetList]
+	_, batchPost := r.Routes[route.BatchPost]
+	_, post := r.Routes[route.Post]
+
+	output := func(routeType route.RouteType) *jsonschema.Schema {
+		groups, _ := r.GetConfiguration(configuration.OutputSerializationGroupsType, routeType)
+		return doc.Schema(entityType, jsonschema.Output, groups.Values...)
+	}
+	input := func(routeType route.RouteType) *jsonschema.Schema {
+		groups, _ := r.GetConfiguration(configuration.InputSerializationGroupsType, routeType)
+		return doc.Schema(entityType, jsonschema.Input, groups.Values...)
+	}
+	operation := &openapi.Operation{Tags: []string{r.GetSubresourceName()}, Responses: map[string]*openapi.Response{}}
+	var method, path, name string
+	var item, body, negotiated bool
+
+	switch routeType {
+	case route.GetList, route.BatchGet:
+		if routeType == route.BatchGet && getList {
+			//documented with GetList, they share their route
+			return
+		}
+		method, path, name, negotiated = http.MethodGet, base, "list", true
+		operation.Summary = "Retrieves the collection of " + resource
+		if getList {
+			operation.Parameters = r.collectionParameters(doc, route.GetList)
+		}
+		if batchGet {
+			operation.Parameters = append(operation.Parameters, r.idsParameter(doc, !getList))
+			operation.Description = "With the ids parameter, only the " + resource + " with these ids are retrieved"
+		}
+		operation.Responses["200"] = r.collectionResponse(output(routeType), getList && r.paginable())
+	case route.Get:
+		method, path, name, item, negotiated = http.MethodGet, base+itemPath, "get", true, true
+		operation.Summary = "Retrieves a " + resource
+		operation.Responses["200"] = entityResponse(resource, output(route.Get))
+	case route.Post, route.BatchPost:
+		if routeType == route.BatchPost && post {
+			return
+		}
+		method, path, name, body, negotiated = http.MethodPost, base, "create", true, true
+		operation.Summary = "Creates a " + resource
+		//written entities are serialized like the Get route does
+		schema, created := input(route.Post), output(route.Get)
+		if batchPost && post {
+			operation.Description = "A list of " + resource + " creates them all"
+			schema = &jsonschema.Schema{OneOf: []*jsonschema.Schema{schema, jsonschema.ArrayOf(schema)}}
+			created = &jsonschema.Schema{OneOf: []*jsonschema.Schema{created, jsonschema.ArrayOf(created)}}
+		} else if batchPost {
+			schema, created = jsonschema.ArrayOf(schema), jsonschema.ArrayOf(created)
+		}
+		operation.RequestBody = requestBody(schema)
+		operation.Responses["201"] = entityResponse(resource+" created", created)
+	case route.Put:
+		method, path, name, item, body, negotiated = http.MethodPut, base+itemPath, "replace", true, true, true
+		operation.Summary = "Replaces a " + resource
+		operation.RequestBody = requestBody(input(route.Put))
+		operation.Responses["200"] = entityResponse(resource+" replaced", output(route.Get))
+	case route.Patch:
+		method, path, name, item, body, negotiated = http.MethodPatch, base+itemPath, "update", true, true, true
+		operation.Summary = "Updates a " + resource
+		operation.RequestBody = requestBody(input(route.Patch))
+		operation.Responses["200"] = entityResponse(resource+" updated", output(route.Get))
+	case route.Delete:
+		method, path, name, item = http.MethodDelete, base+itemPath, "delete", true
+		operation.Summary = "Deletes a " + resource
+		operation.Responses["204"] = &openapi.Response{Description: resource + " deleted"}
+	case route.Head:
+		method, path, name, item, negotiated = http.MethodHead, base+itemPath, "head", true, true
+		operation.Summary = "Checks a " + resource
+		operation.Responses["200"] = &openapi.Response{
+			Description: "Headers of the " + resource,
+			Headers: map[string]*openapi.Header{
+				"Content-Type":   {Schema: &jsonschema.Schema{Type: jsonschema.Types{"string"}}},
+				"Content-Length": {Schema: &jsonschema.Schema{Type: jsonschema.Types{"integer"}}},
+			},
+		}
+	case route.Options:
+		for _, path := range []string{base, base + itemPath} {
+			options := &openapi.Operation{
+				Tags:        operation.Tags,
+				Summary:     "Lists the methods allowed on " + resource,
+				OperationID: operationPrefix + "_options",
+				Responses: map[string]*openapi.Response{"200": {
+					Description: "Allowed methods",
+					Headers:     map[string]*openapi.Header{"Allow": {Schema: &jsonschema.Schema{Type: jsonschema.Types{"string"}}}},
+				}},
+			}
+			if path != base {
+				options.OperationID += "_item"
+			}
+			r.describeOperation(doc, routeType, http.MethodOptions, path, options, path != base, false, false)
+		}
+		return
+	case route.BatchDelete:
+		method, path, name = http.MethodDelete, base, "batch_delete"
+		operation.Summary = "Deletes the " + resource + " with the given ids"
+		operation.Parameters = []*openapi.Parameter{r.idsParameter(doc, true)}
+		operation.Responses["204"] = &openapi.Response{Description: resource + " deleted"}
+	case route.BatchPut:
+		method, path, name, body = http.MethodPut, base, "batch_replace", true
+		operation.Summary = "Replaces a list of " + resource
+		operation.RequestBody = requestBody(jsonschema.ArrayOf(input(route.BatchPut)))
+		operation.Responses["204"] = &openapi.Response{Description: resource + " replaced"}
+	case route.BatchPatch:
+		method, path, name, body = http.MethodPatch, base, "batch_update", true
+		operation.Summary = "Updates a list of " + resource
+		operation.RequestBody = requestBody(jsonschema.ArrayOf(input(route.BatchPatch)))
+		operation.Responses["204"] = &openapi.Response{Description: resource + " updated"}
+	case route.Trash:
+		method, path, name, negotiated = http.MethodGet, base+"/trash", "trash", true
+		operation.Summary = "Retrieves the deleted " + resource
+		operation.Parameters = r.collectionParameters(doc, route.GetList)
+		operation.Responses["200"] = r.collectionResponse(output(route.Trash), r.paginable())
+	case route.Restore:
+		method, path, name, item, negotiated = http.MethodPost, base+"/trash"+itemPath+"/restore", "restore", true, true
+		operation.Summary = "Restores a deleted " + resource
+		operation.Responses["200"] = entityResponse(resource+" restored", output(route.Restore))
+	case route.BatchRestore:
+		method, path, name, negotiated = http.MethodPost, base+"/trash/restore", "batch_restore", true
+		operation.Summary = "Restores the deleted " + resource + " with the given ids"
+		operation.Parameters = []*openapi.Parameter{r.idsParameter(doc, true)}
+		operation.Responses["200"] = entityResponse(resource+" restored", jsonschema.ArrayOf(output(route.BatchRestore)))
+	case route.Purge:
+		method, path, name, item = http.MethodDelete, base+"/trash"+itemPath, "purge", true
+		operation.Summary = "Deletes a deleted " + resource + " for good"
+		operation.Responses["204"] = &openapi.Response{Description: resource + " purged"}
+	case route.BatchPurge:
+		method, path, name = http.MethodDelete, base+"/trash", "batch_purge"
+		operation.Summary = "Deletes the deleted " + resource + " with the given ids for good"
+		operation.Parameters = []*openapi.Parameter{r.idsParameter(doc, true)}
+		operation.Responses["204"] = &openapi.Response{Description: resource + " purged"}
+	case route.Revisions:
+		method, path, name, item, negotiated = http.MethodGet, base+itemPath+"/revisions", "revisions", true, true
+		operation.Summary = "Retrieves the revisions of a " + resource
+		operation.Responses["200"] = entityResponse("Revisions, oldest first", jsonschema.ArrayOf(doc.Schema(reflect.TypeFor[revision.Revision](), jsonschema.Output)))
+	case route.Revision:
+		method, path, name, item, negotiated = http.MethodGet, base+itemPath+"/revisions/:rev", "revision", true, true
+		operation.Summary = "Retrieves a " + resource + " as it was at a revision"
+		operation.Responses["200"] = entityResponse(resource+" at the revision", output(route.Get))
+	case route.Revert:
+		method, path, name, item, negotiated = http.MethodPost, base+itemPath+"/revisions/:rev/revert", "revert", true, true
+		operation.Summary = "Reverts a " + resource + " to a revision"
+		operation.Responses["200"] = entityResponse(resource+" reverted", output(route.Get))
+	case route.Stream, route.ItemStream:
+		method, path, name = http.MethodGet, base+"/stream", "stream"
+		operation.Summary = "Streams the changes of the " + resource
+		if routeType == route.ItemStream {
+			path, name, item = base+itemPath+"/stream", "item_stream", true
+			operation.Summary = "Streams the changes of a " + resource
+		}
+		operation.Description = "Server-Sent Events, the event type is the operation and the data the " + resource + ". Last-Event-ID resumes the stream."
+		operation.Responses["200"] = &openapi.Response{
+			Description: "Event stream",
+			Content:     map[string]*openapi.MediaType{"text/event-stream": {Schema: &jsonschema.Schema{Type: jsonschema.Types{"string"}}}},
+		}
+	default:
+		return
+	}
+	operation.OperationID = operationPrefix + "_" + name
+	r.describeOperation(doc, routeType, method, path, operation, item, body, negotiated)
+}
+
+// describeOperation completes an operation with its path parameters, security and errors, then adds it to the document.
+// item tells that the path identifies an entity, body that the request has one and negotiated that the response format follows the Accept header.
+func (r *ApiRouter[T]) describeOperation(doc *openapi.Document, routeType route.RouteType, method string, path string, operation *openapi.Operation, item bool, body bool, negotiated bool) {
+	path, names := openAPIPath(path)
+	keys := r.identifierKeys()
+	//the parameters of the entity follow those of its parents
+	first := len(names)
+	if item {
+		first = slices.Index(names, "rev") - len(keys)
+		if first < 0 {
+			first = len(names) - len(keys)
+		}
+	}
+	parameters := make([]*openapi.Parameter, 0, len(names)+len(operation.Parameters))
+	for i, name := range names {
+		parameter := &openapi.Parameter{Name: name, In: "path", Required: true, Schema: &jsonschema.Schema{Type: jsonschema.Types{"string"}}}
+		switch {
+		case name == "rev" && routeType != route.Options:
+			parameter.Description = "Number of the revision"
+			parameter.Schema = &jsonschema.Schema{Type: jsonschema.Types{"integer"}}
+		case i >= first:
+			parameter.Description = "Identifier of the " + r.ResourceName()
+			if len(keys) == 1 {
+				parameter.Schema = r.identifierSchema(doc)
+			}
+		default:
+			parameter.Description = "Identifier of the parent"
+		}
+		parameters = append(parameters, parameter)
+	}
+	operation.Parameters = append(parameters, operation.Parameters...)
+	operation.Security = r.securityRequirements(doc, routeType)
+
+	errs := []errors.ApiError{errors.ErrInternal}
+	if item || body {
+		errs = append(errs, errors.ErrBadRequest)
+	}
+	if item {
+		errs = append(errs, errors.ErrNotFound)
+	}
+	if negotiated {
+		errs = append(errs, errors.ErrNotAcceptable)
+	}
+	if len(operation.Security) > 0 || r.routeSecurity(routeType) != "" || r.authenticationRequired(routeType) {
+		errs = append(errs, errors.ErrUnauthorized, errors.ErrForbidden)
+	}
+	if r.RateLimiter != nil {
+		errs = append(errs, errors.ErrTooManyRequests)
+	}
+	for _, err := range errs {
+		operation.Responses[strconv.Itoa(err.Code)] = doc.ErrorResponse(err)
+	}
+	doc.AddOperation(method, path, operation)
+}
+
+// collectionParameters returns the pagination, sorting and group parameters of the lists
+func (r *ApiRouter[T]) collectionParameters(doc *openapi.Document, routeType route.RouteType) []*openapi.Parameter {
+	var parameters []*openapi.Parameter
+	value := func(configurationType configuration.ConfigurationType) string {
+		conf, _ := r.GetConfiguration(configurationType, routeType)
+		return conf.Values[0]
+	}
+	enabled := func(configurationType configuration.ConfigurationType) bool {
+		enabled, _ := strconv.ParseBool(value(configurationType))
+		return enabled
+	}
+	integer := func(conf string) float64 {
+		value, _ := strconv.Atoi(conf)
+		return float64(value)
+	}
+
+	if r.paginable() {
+		one, perPage, maxPerPage := 1.0, integer(value(configuration.ItemPerPageType)), integer(value(configuration.MaxItemPerPageType))
+		parameters = append(parameters,
+			&openapi.Parameter{
+				Name: value(configuration.PageParameterNameType), In: "query", Description: "Page of the collection",
+				Schema: &jsonschema.Schema{Type: jsonschema.Types{"integer"}, Minimum: &one, Default: 1},
+			},
+			&openapi.Parameter{
+				Name: value(configuration.ItemPerPageParameterNameType), In: "query", Description: "Number of items per page",
+				Schema: &jsonschema.Schema{Type: jsonschema.Types{"integer"}, Minimum: &one, Maximum: &maxPerPage, Default: perPage},
+			},
+		)
+		if enabled(configuration.PaginationClientControlType) {
+			parameters = append(parameters, &openapi.Parameter{
+				Name: value(configuration.PaginationParameterNameType), In: "query", Description: "Enables or disables the pagination",
+				Schema: &jsonschema.Schema{Type: jsonschema.Types{"boolean"}, Default: enabled(configuration.PaginationType)},
+			})
+		}
+	}
+
+	if enabled(configuration.SortingClientControlType) {
+		fields, _ := r.GetConfiguration(configuration.SortableFieldsType, routeType)
+		order := &jsonschema.Schema{Properties: map[string]*jsonschema.Schema{}, Type: jsonschema.Types{"object"}}
+		for _, field := range fields.Values {
+			order.Properties[field] = &jsonschema.Schema{Type: jsonschema.Types{"string"}, Enum: []any{"asc", "desc"}}
+		}
+		explode := true
+		parameters = append(parameters, &openapi.Parameter{
+			Name: value(configuration.SortingParameterNameType), In: "query", Description: "Sort order, by field",
+			Style: "deepObject", Explode: &explode, Schema: order,
+		})
+	}
+
+	if enabled(configuration.OutputSerializationGroupOverwriteClientControlType) {
+		parameters = append(parameters, &openapi.Parameter{
+			Name: value(configuration.OutputSerializationGroupOverwriteParameterNameType), In: "query",
+			Description: "Comma separated serialization groups, narrowing the groups of the route",
+			Schema:      &jsonschema.Schema{Type: jsonschema.Types{"string"}},
+		})
+	}
+	return parameters
+}
+
+// paginable tells whether the lists may be paginated
+func (r *ApiRouter[T]) paginable() bool {
+	pagination, _ := r.GetConfiguration(configuration.PaginationType, route.GetList)
+	clientControl, _ := r.GetConfiguration(configuration.PaginationClientControlType, route.GetList)
+	return pagination.Values[0] == "true" || clientControl.Values[0] == "true"
+}
+
+// idsParameter returns the parameter of the ids of the batch routes
+func (r *ApiRouter[T]) idsParameter(doc *openapi.Document, required bool) *openapi.Parameter {
+	ids, _ := r.GetConfiguration(configuration.BatchIdsParameterNameType, route.BatchGet)
+	explode := false
+	schema := &jsonschema.Schema{Type: jsonschema.Types{"string"}}
+	if len(r.identifierKeys()) == 1 {
+		schema = r.identifierSchema(doc)
+	}
+	return &openapi.Parameter{
+		Name:        ids.Values[0],
+		In:          "query",
+		Description: fmt.Sprintf("Comma separated ids, %s[]=1&%s[]=2 is accepted too", ids.Values[0], ids.Values[0]),
+		Required:    required,
+		Style:       "form",
+		Explode:     &explode,
+		Schema:      jsonschema.ArrayOf(schema),
+	}
+}
+
+// identifierKeys returns the route parameters of the identifier
+func (r *ApiRouter[T]) identifierKeys() []string {
+	if composite, ok := r.Orm.NewEntity().GetIdentifier().(entity.CompositeIdentifier); ok {
+		return composite.Keys()
+	}
+	return []string{"id"}
+}
+
+func (r *ApiRouter[T]) identifierSchema(doc *openapi.Document) *jsonschema.Schema {
+	return doc.Schema(reflect.TypeOf(r.Orm.NewEntity().GetIdentifier()), jsonschema.Output)
+}
+
+// securityRequirements returns the firewalls of a route as alternatives, anonymous requests are one unless authentication is required
+func (r *ApiRouter[T]) securityRequirements(doc *openapi.Document, routeType route.RouteType) []openapi.SecurityRequirement {
+	var requirements []openapi.SecurityRequirement
+	for _, firewall := range r.Firewalls {
+		name, scheme, ok := openapi.FirewallSecurityScheme(firewall)
+		if !ok {
+			continue
+		}
+		doc.Components.SecuritySchemes[name] = &scheme
+		requirements = append(requirements, openapi.SecurityRequirement{name: {}})
+	}
+	if len(requirements) > 0 && !r.authenticationRequired(routeType) && r.routeSecurity(routeType) == "" {
+		requirements = append(requirements, openapi.SecurityRequirement{})
+	}
+	return requirements
+}
+
+// collectionResponse describes a list, JSON-LD pages are Hydra collections
+func (r *ApiRouter[T]) collectionResponse(item *jsonschema.Schema, paginated bool) *openapi.Response {
+	response := entityResponse("Collection of "+r.ResourceName(), jsonschema.ArrayOf(item))
+	if paginated {
+		response.Content[format.JSONLD] = &openapi.MediaType{Schema: &jsonschema.Schema{
+			Type: jsonschema.Types{"object"},
+			Properties: map[string]*jsonschema.Schema{
+				"@id":              {Type: jsonschema.Types{"string"}},
+				"hydra:member":     jsonschema.ArrayOf(item),
+				"hydra:totalItems": {Type: jsonschema.Types{"integer"}},
+				"hydra:view":       {Type: jsonschema.Types{"object"}, AdditionalProperties: &jsonschema.Schema{Type: jsonschema.Types{"string"}}},
+			},
+		}}
+	}
+	return response
+}
+
+// entityResponse describes a body in every documented format
+func entityResponse(description string, schema *jsonschema.Schema) *openapi.Response {
+	return &openapi.Response{Description: description, Content: content(schema)}
+}
+
+func requestBody(schema *jsonschema.Schema) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: content(schema)}
+}
+
+func content(schema *jsonschema.Schema) map[string]*openapi.MediaType {
+	content := make(map[string]*openapi.MediaType, len(documentedFormats))
+	for _, f := range documentedFormats {
+		content[string(f)] = &openapi.MediaType{Schema: schema}
+	}
+	return content
+}
+
+// openAPIPath converts the parameters of a gin path to the {param} syntax, it returns their names.
+// Repeated names, like the "id" of a subresource and of its parent, are prefixed with the segment before them.
+func openAPIPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	converted := slices.Clone(segments)
+	var names []string
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := segment[1:]
+		if slices.Contains(names, name) && i > 0 {
+			name = strings.TrimPrefix(segments[i-1], ":") + "_" + name
+		}
+		names = append(names, name)
+		converted[i] = "{" + name + "}"
+	}
+	return strings.Join(converted, "/"), names
+}
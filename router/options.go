@@ -16,6 +16,9 @@ func (r *ApiRouter[T]) Options(c *gin.Context) {
 		case route.BatchPut:
 		case route.BatchGet:
 		case route.BatchPost:
+		case route.Trash, route.Restore, route.BatchRestore, route.Purge, route.BatchPurge:
+		case route.Revisions, route.Revision, route.Revert:
+		case route.Stream, route.ItemStream:
 		default:
 			if len(allowed) > 0 {
 				allowed += ","
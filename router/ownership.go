@@ -0,0 +1,93 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/security"
+)
+
+// RequireOwnership restricts every entity of this ApiRouter to the user owning it.
+// Single and batch routes reject entities owned by someone else, lists and counts only contain the entities of the user,
+// and the user is stamped as owner on every written entity.
+// Users holding one of ownership.Roles access every entity.
+// It panics if the entity has no owner field nor implements security.OwnedEntity.
+func (r *ApiRouter[T]) RequireOwnership(ownership security.Ownership) {
+	if !ownership.Supports(r.Orm.NewEntity()) {
+		panic("RequireOwnership: entity has no owner field")
+	}
+	r.Ownership = &ownership
+}
+
+// ownershipCheck verifies that the user may access the object under the ownership policy
+// Objects without identifier are being created, or stand for the collection whose queries are restricted by ownershipConditions:
+// they may lack an owner, which stampOwner fills. Stored objects without owner are reserved to the users bypassing the ownership.
+func (r *ApiRouter[T]) ownershipCheck(user security.User, object *T) error {
+	if r.Ownership == nil {
+		return nil
+	}
+	allows := r.Ownership.Allows
+	if identifier := (*object).GetIdentifier(); identifier == nil || identifier.IsNull() {
+		allows = r.Ownership.AllowsCreation
+	}
+	if allows(user, *object) {
+		return nil
+	}
+	return errors.ErrUnauthorized
+}
+
+// ownershipConditions returns the conditions restricting the queries of the request to the entities of the user
+func (r *ApiRouter[T]) ownershipConditions(c *gin.Context) ([]orm.Condition, error) {
+	if r.Ownership == nil {
+		return nil, nil
+	}
+	user, err := r.FirewallCheck(c)
+	if err != nil {
+		return nil, err
+	}
+	if r.Ownership.Bypass(user) {
+		return nil, nil
+	}
+	if user == nil {
+		return nil, errors.ErrUnauthorized
+	}
+	field := r.Ownership.FieldName(r.Orm.NewEntity())
+	if field == "" {
+		//an accessor alone cannot filter queries
+		return nil, errors.ErrNotImplemented
+	}
+	owner, err := r.Ownership.OwnerValue(r.Orm.NewEntity(), user)
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	return []orm.Condition{orm.Where(field, owner)}, nil
+}
+
+// stampOwner writes the user as owner of the entities about to be saved
+// users bypassing the ownership may set another owner, they only fill the missing ones
+func (r *ApiRouter[T]) stampOwner(c *gin.Context, objects ...*T) {
+	if r.Ownership == nil {
+		return
+	}
+	user, err := r.FirewallCheck(c)
+	if err != nil || user == nil {
+		return
+	}
+	bypass := r.Ownership.Bypass(user)
+	for _, object := range objects {
+		if bypass && r.Ownership.Owner(*object) != "" {
+			continue
+		}
+		if stamped, err := r.Ownership.SetOwner(*object, user); err == nil {
+			if converted, ok := stamped.(T); ok {
+				*object = converted
+			}
+		}
+	}
+}
+
+// stamp writes the tenant and the owner on the entities about to be saved
+func (r *ApiRouter[T]) stamp(c *gin.Context, objects ...*T) {
+	r.stampTenant(c, objects...)
+	r.stampOwner(c, objects...)
+}
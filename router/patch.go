@@ -2,18 +2,24 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/philiphil/restman/configuration"
 	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
 	"github.com/philiphil/restman/orm/entity"
 	"github.com/philiphil/restman/route"
 )
 
 // Patch handles HTTP PATCH requests to partially update an existing entity.
 func (r *ApiRouter[T]) Patch(c *gin.Context) {
-	id := c.Param("id")
-	obj, err := r.Orm.GetByID(id)
+	requestOrm, err := r.RequestOrm(c)
 	if err != nil {
-		c.AbortWithStatusJSON(errors.ErrNotFound.Code, errors.ErrNotFound.Message)
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	id := r.IdParam(c)
+	obj, err := requestOrm.GetByID(id)
+	if err != nil {
+		apiErr := lookupError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
 		return
 	}
 
@@ -21,14 +27,19 @@ func (r *ApiRouter[T]) Patch(c *gin.Context) {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
 	}
+	if err = r.AccessCheck(c, route.Patch, obj); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
 
-	groups, errGroups := r.GetConfiguration(configuration.InputSerializationGroupsType, route.Patch)
+	before := snapshot(obj)
+	groups, errGroups := r.GetEffectiveInputSerializationGroups(c, route.Patch, *obj)
 	if errGroups != nil {
 		c.AbortWithStatusJSON(errors.ErrInternal.Code, errors.ErrInternal.Message)
 		return
 	}
 
-	if err = UnserializeBodyAndMerge(c, obj, groups.Values...); err != nil {
+	if err = UnserializeBodyAndMerge(c, obj, groups...); err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
 	}
@@ -36,11 +47,17 @@ func (r *ApiRouter[T]) Patch(c *gin.Context) {
 	cast = *obj
 	cast = cast.SetId(id)
 	convertedEntity, _ := cast.(T)
-	err = r.Orm.Update(&convertedEntity)
+	r.stamp(c, &convertedEntity)
+	err = r.write(c, requestOrm, route.Patch, before, []*T{&convertedEntity}, func(o *orm.ORM[T]) error {
+		return o.Update(&convertedEntity)
+	})
 	if err != nil {
-		c.AbortWithStatusJSON(errors.ErrDatabaseIssue.Code, errors.ErrDatabaseIssue.Message)
+		apiErr := writeError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
 		return
 	}
+	r.invalidateResponseCache(&convertedEntity)
+	r.recordWrite(c, route.Patch, before, []*T{&convertedEntity})
 
 	responseFormat, errParse := ParseAcceptHeader(c.GetHeader("Accept"))
 	if errParse != nil {
@@ -49,7 +66,7 @@ func (r *ApiRouter[T]) Patch(c *gin.Context) {
 	}
 
 	//what is sent back should use the "get" serialization groups
-	outputGroups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get)
+	outputGroups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get, *obj)
 	if err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
@@ -2,13 +2,18 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/philiphil/restman/configuration"
 	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
 	"github.com/philiphil/restman/route"
 )
 
 // Post handles HTTP POST requests to create one or more new entities.
 func (r *ApiRouter[T]) Post(c *gin.Context) {
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
 	single := true
 	var entities []*T
 	entity := r.Orm.NewEntity()
@@ -18,16 +23,16 @@ func (r *ApiRouter[T]) Post(c *gin.Context) {
 		return
 	}
 
-	groups, err := r.GetConfiguration(configuration.InputSerializationGroupsType, route.Post)
+	groups, err := r.GetEffectiveInputSerializationGroups(c, route.Post, entity)
 	if err != nil {
 		c.AbortWithStatusJSON(errors.ErrInternal.Code, errors.ErrInternal.Message)
 		return
 	}
 
-	if err := UnserializeBodyAndMerge(c, &entity, groups.Values...); err != nil {
+	if err := UnserializeBodyAndMerge(c, &entity, groups...); err != nil {
 		//if unserializable, might be array
 		if _, ok := r.Routes[route.BatchPost]; ok {
-			if err := UnserializeBodyAndMerge_A(c, &entities, groups.Values...); err != nil {
+			if err := UnserializeBodyAndMerge_A(c, &entities, groups...); err != nil {
 				//its still unserializable as an array
 				c.AbortWithStatusJSON(errors.ErrBadFormat.Code, errors.ErrBadFormat.Message)
 				return
@@ -43,10 +48,27 @@ func (r *ApiRouter[T]) Post(c *gin.Context) {
 		entities = append(entities, &entity)
 	}
 
-	if err := r.Orm.Create(entities...); err != nil {
+	postRoute := route.Post
+	if !single {
+		postRoute = route.BatchPost
+	}
+	if err = r.RateLimitCheck(c, postRoute, len(entities)); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	if err = r.AccessCheck(c, postRoute, entities...); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	r.stamp(c, entities...)
+	if err := r.write(c, requestOrm, postRoute, nil, entities, func(o *orm.ORM[T]) error {
+		return o.Create(entities...)
+	}); err != nil {
 		c.AbortWithStatusJSON(errors.ErrDatabaseIssue.Code, errors.ErrDatabaseIssue.Message)
 		return
 	}
+	r.invalidateResponseCache(entities...)
+	r.recordWrite(c, postRoute, nil, entities)
 	responseFormat, errParse := ParseAcceptHeader(c.GetHeader("Accept"))
 	if errParse != nil {
 		c.AbortWithStatusJSON(errParse.(errors.ApiError).Code, errParse.(errors.ApiError).Message)
@@ -54,7 +76,11 @@ func (r *ApiRouter[T]) Post(c *gin.Context) {
 	}
 
 	//what is sent back should use the "get" serialization groups
-	outputGroups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get)
+	var outputObject []any
+	if single {
+		outputObject = append(outputObject, entity)
+	}
+	outputGroups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get, outputObject...)
 	if err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
@@ -2,33 +2,48 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/philiphil/restman/configuration"
 	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
 	"github.com/philiphil/restman/orm/entity"
 	"github.com/philiphil/restman/route"
 )
 
 // Put handles HTTP PUT requests to replace or create an entity at a specific ID.
 func (r *ApiRouter[T]) Put(c *gin.Context) {
-	id := c.Param("id")
-	obj, err := r.Orm.GetByID(id)
+	requestOrm, err := r.RequestOrm(c)
 	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	id := r.IdParam(c)
+	obj, err := requestOrm.GetByID(id)
+	var before []*T
+	if err == errors.InvalidIdentifier {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.Code, errors.ErrBadRequest.Message)
+		return
+	} else if err != nil {
 		bfr := r.Orm.NewEntity()
 		obj = &bfr
+	} else {
+		before = snapshot(obj)
 	}
 
 	if err = r.WritingCheck(c, obj); err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
 	}
+	if err = r.AccessCheck(c, route.Put, obj); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
 
-	groups, errGroups := r.GetConfiguration(configuration.InputSerializationGroupsType, route.Put)
+	groups, errGroups := r.GetEffectiveInputSerializationGroups(c, route.Put, *obj)
 	if errGroups != nil {
 		c.AbortWithStatusJSON(errors.ErrInternal.Code, errors.ErrInternal.Message)
 		return
 	}
 
-	if err = UnserializeBodyAndMerge(c, obj, groups.Values...); err != nil {
+	if err = UnserializeBodyAndMerge(c, obj, groups...); err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
 	}
@@ -38,11 +53,17 @@ func (r *ApiRouter[T]) Put(c *gin.Context) {
 	cast = cast.SetId(id)
 
 	convertedEntity, _ := cast.(T)
-	err = r.Orm.Update(&convertedEntity)
+	r.stamp(c, &convertedEntity)
+	err = r.write(c, requestOrm, route.Put, before, []*T{&convertedEntity}, func(o *orm.ORM[T]) error {
+		return o.Update(&convertedEntity)
+	})
 	if err != nil {
-		c.AbortWithStatusJSON(errors.ErrDatabaseIssue.Code, errors.ErrDatabaseIssue.Message)
+		apiErr := writeError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
 		return
 	}
+	r.invalidateResponseCache(&convertedEntity)
+	r.recordWrite(c, route.Put, before, []*T{&convertedEntity})
 
 	responseFormat, errParse := ParseAcceptHeader(c.GetHeader("Accept"))
 	if errParse != nil {
@@ -51,7 +72,7 @@ func (r *ApiRouter[T]) Put(c *gin.Context) {
 	}
 
 	//what is sent back should use the "get" serialization groups
-	outputGroups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get)
+	outputGroups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get, *obj)
 	if err != nil {
 		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
 		return
@@ -0,0 +1,124 @@
+package router
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/ratelimit"
+	"github.com/philiphil/restman/route"
+	"github.com/philiphil/restman/security"
+)
+
+const rateLimitContextKey = "restman.rateLimit"
+
+// SetRateLimiter limits the requests of every route of this ApiRouter to the budgets of limiter.
+// Routes can be given their own budget with configuration.RateLimit.
+func (r *ApiRouter[T]) SetRateLimiter(limiter *ratelimit.Limiter) {
+	r.RateLimiter = limiter
+}
+
+// RateLimitBudget returns the budget spent by a route type.
+func RateLimitBudget(routeType route.RouteType) ratelimit.Budget {
+	if routeType.IsBatch() {
+		return ratelimit.BatchBudget
+	}
+	switch routeType {
+	case route.Get, route.GetList, route.Head, route.Options, route.Trash, route.Revisions, route.Revision, route.Stream, route.ItemStream:
+		return ratelimit.ReadBudget
+	}
+	return ratelimit.WriteBudget
+}
+
+// RateLimitSubject returns who a request is charged to: its API key, its user, or its client IP for anonymous requests.
+// The client IP depends on the trusted proxies of the gin engine.
+func (r *ApiRouter[T]) RateLimitSubject(c *gin.Context) string {
+	//blocking errors are reported by the handler, the request is then charged to its IP
+	user, _ := r.FirewallCheck(c)
+	if key, ok := security.CurrentAPIKey(c); ok {
+		return "key:" + key.Hash
+	}
+	if user != nil {
+		return "user:" + user.GetIdentifier().String()
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitCheck charges cost on the budget of the route for the subject of the request, once per request.
+// The RateLimit headers are set on the response, exhausted budgets give ErrTooManyRequests with a Retry-After header.
+func (r *ApiRouter[T]) RateLimitCheck(c *gin.Context, routeType route.RouteType, cost int) error {
+	if r.RateLimiter == nil {
+		return nil
+	}
+	if charged, ok := c.Get(rateLimitContextKey); ok {
+		if charged == nil {
+			return nil
+		}
+		return charged.(error)
+	}
+
+	budget := RateLimitBudget(routeType)
+	policy := r.RateLimiter.Policy(budget)
+	key := r.ResourceName() + ":" + budget.String()
+	if override, err := r._GetRouteWideConfiguration(configuration.RateLimitType, routeType); err == nil && len(override.Values) == 2 {
+		limit, _ := strconv.Atoi(override.Values[0])
+		window, _ := strconv.Atoi(override.Values[1])
+		policy.Limit, policy.Window = limit, time.Duration(window)*time.Second
+		key = r.ResourceName() + ":route" + strconv.Itoa(int(routeType))
+	}
+	if policy.Unlimited() {
+		c.Set(rateLimitContextKey, nil)
+		return nil
+	}
+
+	result, err := r.RateLimiter.Take(key+":"+r.RateLimitSubject(c), policy, cost)
+	if err != nil {
+		//an unavailable store does not take the API down with it
+		c.Set(rateLimitContextKey, nil)
+		return nil
+	}
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+	c.Header("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(seconds(policy.Window)))
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(max(1, seconds(result.RetryAfter))))
+		c.Set(rateLimitContextKey, errors.ErrTooManyRequests)
+		return errors.ErrTooManyRequests
+	}
+	c.Set(rateLimitContextKey, nil)
+	return nil
+}
+
+// rateLimited returns the gin handler charging a route before its handler runs.
+// Batches sent in the body are charged by their handler once the body is read, as they cost their number of items.
+func (r *ApiRouter[T]) rateLimited(routeType route.RouteType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		charged, cost := routeType, 1
+		switch routeType {
+		case route.Post, route.BatchPost, route.BatchPut, route.BatchPatch:
+			c.Next()
+			return
+		case route.GetList, route.BatchGet:
+			charged = r.IsBatchGetOrGetList(c)
+			if charged == route.BatchGet {
+				cost = len(r.GetIds(c))
+			}
+		case route.BatchDelete, route.BatchRestore, route.BatchPurge:
+			cost = len(r.GetIds(c))
+		}
+		if err := r.RateLimitCheck(c, charged, cost); err != nil {
+			c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+			return
+		}
+		c.Next()
+	}
+}
+
+// seconds rounds a duration up to whole seconds.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
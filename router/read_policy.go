@@ -0,0 +1,53 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/security"
+)
+
+// SetReadPolicy restricts the entities every user may read through this ApiRouter.
+// It is combined with the read policy of the entity, if it implements security.ReadPolicy.
+func (r *ApiRouter[T]) SetReadPolicy(policy security.ReadPolicyFunction) {
+	r.ReadPolicy = policy
+}
+
+// readPolicies returns the read policies of this ApiRouter and of its entity
+func (r *ApiRouter[T]) readPolicies() []security.ReadPolicyFunction {
+	var policies []security.ReadPolicyFunction
+	if r.ReadPolicy != nil {
+		policies = append(policies, r.ReadPolicy)
+	}
+	if rp, ok := security.HasReadPolicy(r.Orm.NewEntity()); ok {
+		policies = append(policies, rp.GetReadPolicy())
+	}
+	return policies
+}
+
+// readPolicyConditions compiles the read policies for the user of the request
+func (r *ApiRouter[T]) readPolicyConditions(c *gin.Context) ([]orm.Condition, error) {
+	policies := r.readPolicies()
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	user, err := r.FirewallCheck(c)
+	if err != nil {
+		return nil, err
+	}
+	var conditions []orm.Condition
+	for _, policy := range policies {
+		policyConditions, err := policy(user)
+		if err != nil {
+			if apiErr, ok := err.(errors.ApiError); ok {
+				return nil, apiErr
+			}
+			if user == nil {
+				return nil, errors.ErrUnauthorized
+			}
+			return nil, errors.ErrForbidden
+		}
+		conditions = append(conditions, policyConditions...)
+	}
+	return conditions, nil
+}
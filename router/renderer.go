@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/philiphil/restman/cache"
 	"github.com/philiphil/restman/format"
 	"github.com/philiphil/restman/serializer"
 )
@@ -12,6 +13,13 @@ type SerializerRenderer struct {
 	Data   any
 	Format format.Format
 	Groups []string
+
+	// Cached is an already serialized response, when set it is written as is and Data is ignored
+	Cached []byte
+	// Cache receives the serialized response under CacheKey, indexed by CacheTags
+	Cache     cache.ResponseCache
+	CacheKey  string
+	CacheTags []string
 }
 
 var (
@@ -66,6 +74,10 @@ func putSerializer(f format.Format, s *serializer.Serializer) {
 // Render
 func (r SerializerRenderer) Render(w http.ResponseWriter) (err error) {
 	r.WriteContentType(w)
+	if r.Cached != nil {
+		_, err = w.Write(r.Cached)
+		return err
+	}
 	s := getSerializer(r.Format)
 	defer putSerializer(r.Format, s)
 
@@ -73,7 +85,12 @@ func (r SerializerRenderer) Render(w http.ResponseWriter) (err error) {
 	if err != nil {
 		return err
 	}
-	_, err = w.Write([]byte(str))
+	data := []byte(str)
+	_, err = w.Write(data)
+	if err == nil && r.Cache != nil && r.CacheKey != "" {
+		//a cache failure must not fail a response that has already been written
+		_ = r.Cache.Set(r.CacheKey, data, r.CacheTags...)
+	}
 	return err
 }
 
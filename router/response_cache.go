@@ -0,0 +1,111 @@
+package router
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/cache"
+	"github.com/philiphil/restman/route"
+	"github.com/philiphil/restman/security"
+)
+
+const responseCacheKeyContextKey = "restman.responseCacheKey"
+
+// ResourceName returns the name identifying the entity of this ApiRouter in caches.
+func (r *ApiRouter[T]) ResourceName() string {
+	return reflect.TypeOf(r.Orm.NewEntity()).Name()
+}
+
+// responseCacheScope returns who a cached response may be served to.
+// Resources without reading rights are shared by everyone,
+// private, owned, policed, secured, authenticated and user-dependent ones are scoped to the authenticated user because the checks only happened for that user.
+// user is the one the firewalls resolved for the request.
+// With tenancy, every scope is further restricted to the tenant of the request.
+func (r *ApiRouter[T]) responseCacheScope(c *gin.Context, routeType route.RouteType, user security.User) (string, error) {
+	tenant, err := r.Tenant(c)
+	if err != nil {
+		return "", err
+	}
+	prefix := ""
+	if tenant != "" {
+		prefix = "tenant:" + tenant + "|"
+	}
+	if _, private := security.HasReadingRights(r.Orm.NewEntity()); !private && r.Ownership == nil && len(r.readPolicies()) == 0 && r.GroupResolver == nil && r.routeSecurity(routeType) == "" && !r.authenticationRequired(routeType) {
+		return prefix + "public", nil
+	}
+	if user == nil {
+		return prefix + "anonymous", nil
+	}
+	return prefix + "user:" + user.GetIdentifier().String(), nil
+}
+
+// serveFromResponseCache writes the cached response of the current request if there is one.
+// On a miss, the cache key is kept on the context so that the rendered response can be stored.
+// Any error is left to the regular handler which will report it.
+func (r *ApiRouter[T]) serveFromResponseCache(c *gin.Context, routeType route.RouteType) bool {
+	if r.ResponseCache == nil {
+		return false
+	}
+	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
+	if err != nil {
+		return false
+	}
+	//firewalls run first so that a blocking error is never hidden by a cache hit, their user is reused for the scope
+	user, err := r.FirewallCheck(c)
+	if err != nil {
+		return false
+	}
+	groups, err := r.GetEffectiveOutputSerializationGroups(c, routeType)
+	if err != nil {
+		return false
+	}
+	scope, err := r.responseCacheScope(c, routeType, user)
+	if err != nil {
+		return false
+	}
+
+	key := cache.ResponseCacheKey(r.ResourceName(), c.Request.URL.Path+"?"+c.Request.URL.Query().Encode(), responseFormat, groups, scope)
+	if data, ok := r.ResponseCache.Get(key); ok {
+		c.Render(200, SerializerRenderer{
+			Format: responseFormat,
+			Cached: data,
+		})
+		return true
+	}
+	c.Set(responseCacheKeyContextKey, key)
+	return false
+}
+
+// withResponseCache makes the renderer store its output under the key computed by serveFromResponseCache.
+func (r *ApiRouter[T]) withResponseCache(c *gin.Context, renderer SerializerRenderer, tags ...string) SerializerRenderer {
+	if r.ResponseCache == nil {
+		return renderer
+	}
+	key := c.GetString(responseCacheKeyContextKey)
+	if key == "" {
+		return renderer
+	}
+	renderer.Cache = r.ResponseCache
+	renderer.CacheKey = key
+	renderer.CacheTags = tags
+	return renderer
+}
+
+// itemTags returns the response cache tags of the given objects.
+func (r *ApiRouter[T]) itemTags(objects ...*T) []string {
+	resource := r.ResourceName()
+	tags := make([]string, 0, len(objects))
+	for _, object := range objects {
+		tags = append(tags, cache.ItemTag(resource, (*object).GetIdentifier().String()))
+	}
+	return tags
+}
+
+// invalidateResponseCache evicts the cached responses of the written objects and every cached collection.
+func (r *ApiRouter[T]) invalidateResponseCache(objects ...*T) {
+	if r.ResponseCache == nil {
+		return
+	}
+	//the write already succeeded, a stale entry will at worst live until its lifetime ends
+	_ = r.ResponseCache.Invalidate(append(r.itemTags(objects...), cache.CollectionTag(r.ResourceName()))...)
+}
@@ -0,0 +1,246 @@
+package router
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/revision"
+	"github.com/philiphil/restman/route"
+	"github.com/philiphil/restman/serializer/filter"
+)
+
+// SetRevisionStore keeps the state of the entities of this ApiRouter after every write in store, see route.RevisionOperations.
+func (r *ApiRouter[T]) SetRevisionStore(store revision.Store) {
+	r.RevisionStore = store
+}
+
+// recordRevisions stores the state of every written entity, deleted entities keep their last state.
+// The write is already done, a failing store is reported on the gin context without changing the response.
+func (r *ApiRouter[T]) recordRevisions(c *gin.Context, routeType route.RouteType, before []*T, after []*T) {
+	if r.RevisionStore == nil {
+		return
+	}
+	template := revision.Revision{
+		Resource:  r.ResourceName(),
+		Operation: string(writeOperation(routeType, before)),
+		User:      r.recordedUser(c),
+		RequestID: RequestID(c),
+		Time:      time.Now().UTC(),
+	}
+
+	written := make(map[string]bool, len(after))
+	save := func(object *T) {
+		snapshot, err := json.Marshal(object)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		revision := template
+		revision.EntityID = (*object).GetIdentifier().String()
+		revision.Snapshot = string(snapshot)
+		if err = r.RevisionStore.Save(&revision); err != nil {
+			_ = c.Error(err)
+		}
+	}
+	for _, object := range after {
+		written[(*object).GetIdentifier().String()] = true
+		save(object)
+	}
+	for _, object := range before {
+		if !written[(*object).GetIdentifier().String()] {
+			save(object)
+		}
+	}
+}
+
+// revisionSubject loads the entity whose revisions are requested and checks the request may access them.
+func (r *ApiRouter[T]) revisionSubject(c *gin.Context, routeType route.RouteType) (*T, error) {
+	if r.RevisionStore == nil {
+		return nil, errors.ErrNotImplemented
+	}
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		return nil, err
+	}
+	object, err := requestOrm.GetByID(r.IdParam(c))
+	if err != nil {
+		return nil, lookupError(err)
+	}
+	if routeType == route.Revert {
+		err = r.WritingCheck(c, object)
+	} else {
+		err = r.ReadingCheck(c, object)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = r.AccessCheck(c, routeType, object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// findRevision returns the revision of the rev parameter and the entity it snapshots.
+func (r *ApiRouter[T]) findRevision(c *gin.Context, object *T) (*revision.Revision, *T, error) {
+	number, err := strconv.Atoi(c.Param("rev"))
+	if err != nil || number < 1 {
+		return nil, nil, errors.ErrBadRequest
+	}
+	found, err := revision.Find(r.RevisionStore, r.ResourceName(), (*object).GetIdentifier().String(), number)
+	if err != nil {
+		return nil, nil, errors.ErrDatabaseIssue
+	}
+	if found == nil {
+		return nil, nil, errors.ErrNotFound
+	}
+	//the snapshot is decoded over the current entity, so that fields hidden from JSON keep their value
+	state := *object
+	if err = json.Unmarshal([]byte(found.Snapshot), &state); err != nil {
+		return nil, nil, errors.ErrInternal
+	}
+	return found, &state, nil
+}
+
+// Revisions handles HTTP GET requests to list the revisions of an entity, oldest first.
+func (r *ApiRouter[T]) Revisions(c *gin.Context) {
+	object, err := r.revisionSubject(c, route.Revisions)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	revisions, err := r.RevisionStore.List(r.ResourceName(), (*object).GetIdentifier().String())
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrDatabaseIssue.Code, errors.ErrDatabaseIssue.Message)
+		return
+	}
+	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	c.Render(200, SerializerRenderer{
+		Data:   revisions,
+		Format: responseFormat,
+	})
+}
+
+// Revision handles HTTP GET requests to retrieve the state of an entity at one of its revisions.
+func (r *ApiRouter[T]) Revision(c *gin.Context) {
+	object, err := r.revisionSubject(c, route.Revision)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	_, state, err := r.findRevision(c, object)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	//the entity may have been readable then and not anymore, or the opposite
+	if err = r.ReadingCheck(c, state); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+
+	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	groups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get, *state)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	c.Render(200, SerializerRenderer{
+		Data:   state,
+		Format: responseFormat,
+		Groups: groups,
+	})
+}
+
+// Revert handles HTTP POST requests to restore an entity to the state of one of its revisions.
+// The revert is a write of its own, recorded as a new revision.
+func (r *ApiRouter[T]) Revert(c *gin.Context) {
+	object, err := r.revisionSubject(c, route.Revert)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	_, state, err := r.findRevision(c, object)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	//a revert writes the entity as a Put would, the fields the user may not write keep their current value
+	inputGroups, err := r.GetEffectiveInputSerializationGroups(c, route.Put, *object)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrInternal.Code, errors.ErrInternal.Message)
+		return
+	}
+	reverted := revertFields(object, state, inputGroups)
+	if err = r.WritingCheck(c, &reverted); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+
+	before := snapshot(object)
+	var cast entity.Entity
+	cast = reverted
+	cast = cast.SetId((*object).GetIdentifier())
+	convertedEntity, _ := cast.(T)
+	//tenant and owner are stamped as for any other write, a revision cannot move the entity
+	r.stamp(c, &convertedEntity)
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	if err = r.write(c, requestOrm, route.Revert, before, []*T{&convertedEntity}, func(o *orm.ORM[T]) error {
+		return o.Update(&convertedEntity)
+	}); err != nil {
+		apiErr := writeError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+		return
+	}
+	r.invalidateResponseCache(&convertedEntity)
+	r.recordWrite(c, route.Revert, before, []*T{&convertedEntity})
+
+	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	groups, err := r.GetEffectiveOutputSerializationGroups(c, route.Get, convertedEntity)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	c.Render(200, SerializerRenderer{
+		Data:   &convertedEntity,
+		Format: responseFormat,
+		Groups: groups,
+	})
+}
+
+// revertFields returns object with the fields of state included in groups, every field when there is no group.
+func revertFields[T any](object *T, state *T, groups []string) T {
+	reverted := *object
+	target := reflect.ValueOf(&reverted).Elem()
+	if len(groups) == 0 || target.Kind() != reflect.Struct {
+		return *state
+	}
+	source := reflect.ValueOf(state).Elem()
+	for i := 0; i < target.NumField(); i++ {
+		if target.Field(i).CanSet() && filter.IsFieldIncluded(target.Type().Field(i), groups) {
+			target.Field(i).Set(source.Field(i))
+		}
+	}
+	return reverted
+}
@@ -0,0 +1,119 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/jsonschema"
+)
+
+// SchemaDescribed is an interface that any ApiRouter implements to serve the JSON Schemas of its entity
+type SchemaDescribed interface {
+	// GetSubresourceName returns the name of the resource, which names its schemas
+	GetSubresourceName() string
+	// JSONSchema returns the schema of the entity serialized with groups, the router wide groups when there is none
+	JSONSchema(direction jsonschema.Direction, groups ...string) *jsonschema.Schema
+}
+
+// JSONSchema returns the standalone JSON Schema of the entity serialized with groups, in the given direction.
+// Without groups, it uses the router wide serialization groups of that direction.
+func (r *ApiRouter[T]) JSONSchema(direction jsonschema.Direction, groups ...string) *jsonschema.Schema {
+	if len(groups) == 0 {
+		configurationType := configuration.OutputSerializationGroupsType
+		if direction == jsonschema.Input {
+			configurationType = configuration.InputSerializationGroupsType
+		}
+		routerGroups, _ := r.GetConfiguration(configurationType)
+		groups = routerGroups.Values
+	}
+	schema := jsonschema.Generate(reflect.TypeFor[T](), direction, groups...)
+	schema.Title = r.ResourceName()
+	return schema
+}
+
+// Schemas serves the JSON Schemas of the entities of ApiRouters
+// To create a Schemas, you should use the NewSchemas function
+type Schemas struct {
+	// Path serves the schemas at Path/{resource}?groups=read,write&direction=input (default: "/api/schemas")
+	Path string
+
+	routers map[string]SchemaDescribed
+}
+
+// NewSchemas creates a Schemas serving the schemas of the given ApiRouters.
+func NewSchemas(routers ...SchemaDescribed) *Schemas {
+	s := &Schemas{Path: "/api/schemas", routers: map[string]SchemaDescribed{}}
+	s.Register(routers...)
+	return s
+}
+
+// Register adds ApiRouters, their schemas are named after their resource.
+func (s *Schemas) Register(routers ...SchemaDescribed) {
+	for _, router := range routers {
+		s.routers[router.GetSubresourceName()] = router
+	}
+}
+
+// AllowRoutes adds the route serving the schemas to the gin router
+func (s *Schemas) AllowRoutes(router *gin.Engine) {
+	router.GET(s.Path+"/:resource", s.serve)
+}
+
+func (s *Schemas) serve(c *gin.Context) {
+	router, ok := s.routers[c.Param("resource")]
+	if !ok {
+		c.AbortWithStatusJSON(errors.ErrNotFound.Code, errors.ErrNotFound.Message)
+		return
+	}
+	var direction jsonschema.Direction
+	switch c.DefaultQuery("direction", "output") {
+	case "output":
+		direction = jsonschema.Output
+	case "input":
+		direction = jsonschema.Input
+	default:
+		c.AbortWithStatusJSON(errors.ErrBadRequest.Code, errors.ErrBadRequest.Message)
+		return
+	}
+	var groups []string
+	for _, value := range c.QueryArray("groups") {
+		for group := range strings.SplitSeq(value, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				groups = append(groups, group)
+			}
+		}
+	}
+	data, err := json.Marshal(router.JSONSchema(direction, groups...))
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrInternal.Code, errors.ErrInternal.Message)
+		return
+	}
+	c.Data(http.StatusOK, "application/schema+json", data)
+}
+
+// Export writes the output and input schemas of every resource to dir, as {resource}.json and {resource}.input.json, serialized with groups.
+// Without groups, the router wide serialization groups are used.
+func (s *Schemas) Export(dir string, groups ...string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for name, router := range s.routers {
+		for suffix, direction := range map[string]jsonschema.Direction{".json": jsonschema.Output, ".input.json": jsonschema.Input} {
+			data, err := json.MarshalIndent(router.JSONSchema(direction, groups...), "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(dir, name+suffix), data, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
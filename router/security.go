@@ -1,13 +1,73 @@
 package router
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/philiphil/restman/errors"
 	"github.com/philiphil/restman/security"
 )
 
+// firewallContextKey prefixes the keys of the firewall results on the context, every ApiRouter has its own firewalls
+const firewallContextKey = "restman.firewall."
+
+type firewallResult struct {
+	user security.User
+	err  error
+}
+
 // FirewallCheck executes all configured firewalls to authenticate and retrieve the current user.
+// Firewalls run once per request and per ApiRouter: the result is kept on the context and the user is exposed through security.CurrentUser,
+// on the gin context as well as on the request context handed to the repositories.
 func (r *ApiRouter[T]) FirewallCheck(c *gin.Context) (security.User, error) {
+	key := firewallContextKey + fmt.Sprintf("%p", r)
+	if cached, ok := c.Get(key); ok {
+		result := cached.(firewallResult)
+		return result.user, result.err
+	}
+	user, err := r.runFirewalls(c)
+	c.Set(key, firewallResult{user: user, err: err})
+	if err == nil && user != nil {
+		c.Set(security.CurrentUserContextKey, user)
+		if c.Request != nil {
+			c.Request = c.Request.WithContext(security.WithCurrentUser(c.Request.Context(), user))
+		}
+	} else {
+		r.challengeAnonymous(c)
+	}
+	return user, err
+}
+
+// challengeAnonymous makes the 401 answered to the anonymous request carry the challenges of the firewalls implementing security.Challenger
+func (r *ApiRouter[T]) challengeAnonymous(c *gin.Context) {
+	var challenges []string
+	for _, firewall := range r.Firewalls {
+		if challenger, ok := firewall.(security.Challenger); ok {
+			challenges = append(challenges, challenger.Challenge())
+		}
+	}
+	if len(challenges) > 0 && c.Writer != nil {
+		c.Writer = &challengeWriter{ResponseWriter: c.Writer, challenges: challenges}
+	}
+}
+
+// challengeWriter adds the WWW-Authenticate challenges to a 401 response, unless a firewall already did
+type challengeWriter struct {
+	gin.ResponseWriter
+	challenges []string
+}
+
+func (w *challengeWriter) WriteHeader(code int) {
+	if code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+		for _, challenge := range w.challenges {
+			w.Header().Add("WWW-Authenticate", challenge)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (r *ApiRouter[T]) runFirewalls(c *gin.Context) (security.User, error) {
 	var user security.User
 	var err error
 	for _, firewall := range r.Firewalls {
@@ -25,6 +85,16 @@ func (r *ApiRouter[T]) FirewallCheck(c *gin.Context) (security.User, error) {
 	return user, nil
 }
 
+// Authenticate is a gin handler running the firewalls of this ApiRouter, for custom operations registered next to its routes.
+// Blocking firewall errors abort the request, the user is then available through security.CurrentUser.
+func (r *ApiRouter[T]) Authenticate(c *gin.Context) {
+	if _, err := r.FirewallCheck(c); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	c.Next()
+}
+
 // ReadingCheck verifies that the authenticated user has permission to read the specified object.
 func (r *ApiRouter[T]) ReadingCheck(c *gin.Context, object *T) error {
 	user, err := r.FirewallCheck(c)
@@ -39,7 +109,7 @@ func (r *ApiRouter[T]) ReadingCheck(c *gin.Context, object *T) error {
 		}
 	}
 
-	return nil
+	return r.ownershipCheck(user, object)
 }
 
 // WritingCheck verifies that the authenticated user has permission to modify the specified object.
@@ -56,5 +126,43 @@ func (r *ApiRouter[T]) WritingCheck(c *gin.Context, object *T) error {
 		}
 	}
 
-	return nil
+	return r.ownershipCheck(user, object)
+}
+
+// TrashReadingCheck verifies that the authenticated user has permission to list the soft deleted objects.
+// It falls back to the reading rights when the object does not implement TrashReadingRights.
+func (r *ApiRouter[T]) TrashReadingCheck(c *gin.Context, object *T) error {
+	if rr, ok := security.HasTrashReadingRights(*object); ok {
+		return r.authorizationCheck(c, object, rr.GetTrashReadingRights())
+	}
+	return r.ReadingCheck(c, object)
+}
+
+// RestoringCheck verifies that the authenticated user has permission to restore the specified soft deleted object.
+// It falls back to the writing rights when the object does not implement RestoringRights.
+func (r *ApiRouter[T]) RestoringCheck(c *gin.Context, object *T) error {
+	if rr, ok := security.HasRestoringRights(*object); ok {
+		return r.authorizationCheck(c, object, rr.GetRestoringRights())
+	}
+	return r.WritingCheck(c, object)
+}
+
+// PurgingCheck verifies that the authenticated user has permission to permanently delete the specified object.
+// It falls back to the writing rights when the object does not implement PurgingRights.
+func (r *ApiRouter[T]) PurgingCheck(c *gin.Context, object *T) error {
+	if rr, ok := security.HasPurgingRights(*object); ok {
+		return r.authorizationCheck(c, object, rr.GetPurgingRights())
+	}
+	return r.WritingCheck(c, object)
+}
+
+func (r *ApiRouter[T]) authorizationCheck(c *gin.Context, object *T, auth security.AuthorizationFunction) error {
+	user, err := r.FirewallCheck(c)
+	if err != nil {
+		return err
+	}
+	if !auth(user, *object) {
+		return errors.ErrUnauthorized
+	}
+	return r.ownershipCheck(user, object)
 }
@@ -0,0 +1,50 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/security"
+)
+
+type loginRequest struct {
+	Username string `json:"username" form:"username"`
+	Password string `json:"password" form:"password"`
+}
+
+// AllowSessionRoutes registers the login and logout routes of a session firewall under prefix,
+// as POST <prefix>/login and POST <prefix>/logout.
+func AllowSessionRoutes(router *gin.Engine, firewall *security.SessionFirewall, prefix string) {
+	router.POST(prefix+"/login", SessionLogin(firewall))
+	router.POST(prefix+"/logout", SessionLogout(firewall))
+}
+
+// SessionLogin returns a handler opening a session from a username and a password, sent as JSON or as a form.
+// The CSRF token of the session is answered, it must be sent back in the CSRF header of every write.
+func SessionLogin(firewall *security.SessionFirewall) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var credentials loginRequest
+		if err := c.ShouldBind(&credentials); err != nil || credentials.Username == "" {
+			c.AbortWithStatusJSON(errors.ErrBadRequest.Code, errors.ErrBadRequest.Message)
+			return
+		}
+		session, err := firewall.Login(c, credentials.Username, credentials.Password)
+		if err != nil {
+			c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"csrfToken": session.CSRFToken, "expiresAt": session.ExpiresAt})
+	}
+}
+
+// SessionLogout returns a handler closing the session of the request.
+func SessionLogout(firewall *security.SessionFirewall) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := firewall.Logout(c); err != nil {
+			c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
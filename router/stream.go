@@ -0,0 +1,184 @@
+package router
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/broadcast"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/format"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/route"
+	"github.com/philiphil/restman/serializer"
+)
+
+// StreamHeartbeat is the interval of the comments keeping idle streams open through proxies
+var StreamHeartbeat = 15 * time.Second
+
+// EnableStreaming streams the changes of the entities of this ApiRouter to the subscribers of its stream routes, see route.StreamOperations.
+// The last bufferSize changes are kept for the subscribers resuming with a Last-Event-ID header.
+func (r *ApiRouter[T]) EnableStreaming(bufferSize int) {
+	r.Broadcast = broadcast.NewHub[T](bufferSize)
+}
+
+// broadcast sends the changes of a successful write to the stream subscribers, deleted entities are sent as they were.
+func (r *ApiRouter[T]) broadcast(c *gin.Context, routeType route.RouteType, before []*T, after []*T) {
+	if r.Broadcast == nil {
+		return
+	}
+	tenant := c.GetString(tenantContextKey)
+	operation := writeOperation(routeType, before)
+	r.Broadcast.Publish(operation, tenant, after...)
+
+	written := make(map[string]bool, len(after))
+	for _, object := range after {
+		written[(*object).GetIdentifier().String()] = true
+	}
+	for _, object := range before {
+		if !written[(*object).GetIdentifier().String()] {
+			r.Broadcast.Publish(operation, tenant, object)
+		}
+	}
+}
+
+// Stream handles HTTP GET requests subscribing to the changes of every entity, as Server-Sent Events.
+func (r *ApiRouter[T]) Stream(c *gin.Context) {
+	routeType, entityID, err := r.streamCheck(c, "")
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	r.stream(c, routeType, entityID)
+}
+
+// ItemStream handles HTTP GET requests subscribing to the changes of a single entity, as Server-Sent Events.
+func (r *ApiRouter[T]) ItemStream(c *gin.Context) {
+	routeType, entityID, err := r.streamCheck(c, r.IdParam(c))
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	r.stream(c, routeType, entityID)
+}
+
+// streamCheck authorizes a subscription to the changes of every entity, or of the entity with the given id when it is set.
+// It returns the stream route of the subscription and the id of its entity.
+func (r *ApiRouter[T]) streamCheck(c *gin.Context, id string) (route.RouteType, string, error) {
+	if id == "" {
+		return route.Stream, "", r.AccessCheck(c, route.Stream)
+	}
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		return route.ItemStream, "", err
+	}
+	object, err := requestOrm.GetByID(id)
+	if err != nil {
+		return route.ItemStream, "", lookupError(err)
+	}
+	if err = r.ReadingCheck(c, object); err != nil {
+		return route.ItemStream, "", err
+	}
+	if err = r.AccessCheck(c, route.ItemStream, object); err != nil {
+		return route.ItemStream, "", err
+	}
+	return route.ItemStream, (*object).GetIdentifier().String(), nil
+}
+
+// stream sends the changes the request may read until the client disconnects, only those of entityID when it is set.
+// Every event has the operation as type, the hub number as id and the entity serialized with the output groups of the route as data.
+// A "reset" event tells clients resuming too late that changes were missed.
+func (r *ApiRouter[T]) stream(c *gin.Context, routeType route.RouteType, entityID string) {
+	if r.Broadcast == nil {
+		c.AbortWithStatusJSON(errors.ErrNotImplemented.Code, errors.ErrNotImplemented.Message)
+		return
+	}
+	tenant, conditions, err := r.requestScope(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	lastID, _ := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+	subscription, replay, complete := r.Broadcast.Subscribe(lastID)
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(200)
+	if !complete {
+		fmt.Fprint(c.Writer, "event: reset\ndata: {}\n\n")
+	}
+	c.Writer.Flush()
+
+	send := func(message broadcast.Message[T]) error {
+		data, err := r.streamed(c, routeType, message, tenant, conditions, entityID)
+		if err != nil || data == nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", message.ID, message.Operation, data); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+	for _, message := range replay {
+		if send(message) != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(StreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case message, ok := <-subscription.C():
+			if !ok || send(message) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// streamed serializes a change with the output groups of the stream route, it is nil when the subscriber may not receive it.
+func (r *ApiRouter[T]) streamed(c *gin.Context, routeType route.RouteType, message broadcast.Message[T], tenant string, conditions []orm.Condition, entityID string) ([]byte, error) {
+	if !r.streamable(c, message, tenant, conditions, entityID) {
+		return nil, nil
+	}
+	groups, err := r.GetEffectiveOutputSerializationGroups(c, routeType, *message.Object)
+	if err != nil {
+		return nil, err
+	}
+	s := serializer.NewSerializer(format.JSON)
+	s.Compact = true
+	data, err := s.Serialize(message.Object, groups...)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+// streamable reports whether a subscriber may receive a change: it belongs to its tenant, matches the conditions of its queries and its reading rights.
+func (r *ApiRouter[T]) streamable(c *gin.Context, message broadcast.Message[T], tenant string, conditions []orm.Condition, entityID string) bool {
+	if entityID != "" && message.EntityID != entityID {
+		return false
+	}
+	if tenant != message.Tenant {
+		return false
+	}
+	for _, condition := range conditions {
+		if !condition.Matches(*message.Object) {
+			return false
+		}
+	}
+	return r.ReadingCheck(c, message.Object) == nil
+}
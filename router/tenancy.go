@@ -0,0 +1,129 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/security"
+)
+
+const tenantContextKey = "restman.tenant"
+
+// SetTenancy isolates the data of every tenant of this ApiRouter.
+// The resolver finds the tenant of each request, field is the database field holding it:
+// reads, counts and lookups are filtered on it, created and updated entities are stamped with it
+// (entities must implement security.TenantEntity) and writes on another tenant's entities are rejected.
+// An empty field disables filtering, for tenants isolated by SetTenantRepositoryProviders only.
+func (r *ApiRouter[T]) SetTenancy(resolver security.TenantResolver, field string) {
+	r.TenantResolver = resolver
+	r.TenantField = field
+}
+
+// SetTenantRepositoryProviders routes every tenant to its own repository (database, schema or collection).
+// It requires a resolver set by SetTenancy.
+func (r *ApiRouter[T]) SetTenantRepositoryProviders(providers orm.TenantRepositoryProviders[T]) {
+	r.TenantRepositories = providers
+}
+
+// Tenant returns the tenant of the request, or an empty string when tenancy is disabled.
+// It is resolved once per request.
+func (r *ApiRouter[T]) Tenant(c *gin.Context) (string, error) {
+	if r.TenantResolver == nil {
+		return "", nil
+	}
+	if tenant := c.GetString(tenantContextKey); tenant != "" {
+		return tenant, nil
+	}
+	user, err := r.FirewallCheck(c)
+	if err != nil {
+		return "", err
+	}
+	tenant, err := r.TenantResolver.ResolveTenant(c, user)
+	if err != nil {
+		return "", err
+	}
+	if tenant == "" {
+		return "", errors.ErrUnauthorized
+	}
+	c.Set(tenantContextKey, tenant)
+	return tenant, nil
+}
+
+// requestScope returns the tenant of a request and the conditions restricting the entities it may see:
+// those of its tenant, those owned by its user and those its read policies allow.
+func (r *ApiRouter[T]) requestScope(c *gin.Context) (string, []orm.Condition, error) {
+	//errors are left to the checks requiring a user, the result is kept for them
+	_, _ = r.FirewallCheck(c)
+	tenant, err := r.Tenant(c)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var conditions []orm.Condition
+	if tenant != "" && r.TenantField != "" {
+		conditions = append(conditions, orm.Where(r.TenantField, tenant))
+	}
+	ownership, err := r.ownershipConditions(c)
+	if err != nil {
+		return "", nil, err
+	}
+	conditions = append(conditions, ownership...)
+	readPolicy, err := r.readPolicyConditions(c)
+	if err != nil {
+		return "", nil, err
+	}
+	conditions = append(conditions, readPolicy...)
+	return tenant, conditions, nil
+}
+
+// RequestOrm returns the ORM a request operates on: the repository of its tenant,
+// restricted to the data of the tenant, to the entities owned by the user and to those its read policies allow.
+// Entities a user cannot read cannot be written either.
+// Its repository runs with the request context, which carries the current user (see security.CurrentUser).
+func (r *ApiRouter[T]) RequestOrm(c *gin.Context) (*orm.ORM[T], error) {
+	tenant, conditions, err := r.requestScope(c)
+	if err != nil {
+		return nil, err
+	}
+
+	base := &r.Orm
+	if tenant != "" && r.TenantRepositories != nil {
+		provider, err := r.TenantRepositories(tenant)
+		if err != nil || provider == nil {
+			return nil, errors.ErrDatabaseIssue
+		}
+		base = orm.NewORM(provider.Provide())
+	}
+	if c.Request != nil {
+		base = base.WithContext(c.Request.Context())
+	}
+	scoped, err := base.Scoped(conditions...)
+	if err != nil {
+		//never serve unfiltered data
+		return nil, errors.ErrNotImplemented
+	}
+	return scoped, nil
+}
+
+// stampTenant writes the tenant of the request on the entities about to be saved
+func (r *ApiRouter[T]) stampTenant(c *gin.Context, objects ...*T) {
+	tenant := c.GetString(tenantContextKey)
+	if tenant == "" {
+		return
+	}
+	for _, object := range objects {
+		if te, ok := security.HasTenant(*object); ok {
+			if stamped, ok := te.SetTenantId(tenant).(T); ok {
+				*object = stamped
+			}
+		}
+	}
+}
+
+// writeError converts the error of a write into an ApiError
+func writeError(err error) errors.ApiError {
+	if err == errors.OutOfScope {
+		return errors.ErrForbidden
+	}
+	return errors.ErrDatabaseIssue
+}
@@ -0,0 +1,265 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/format"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/route"
+)
+
+// trashError converts an ORM error of the soft delete lifecycle into an ApiError
+func trashError(err error) errors.ApiError {
+	switch err {
+	case errors.SoftDeleteUnsupported:
+		return errors.ErrNotImplemented
+	case errors.ItemNotFound, errors.NotAllItemFound:
+		return errors.ErrNotFound
+	case errors.InvalidIdentifier:
+		return errors.ErrBadRequest
+	}
+	return errors.ErrDatabaseIssue
+}
+
+// Trash handles HTTP GET requests to list the soft deleted entities with optional pagination and sorting.
+func (r *ApiRouter[T]) Trash(c *gin.Context) {
+	empty := r.Orm.NewEntity()
+	if err := r.TrashReadingCheck(c, &empty); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+
+	if err := r.AccessCheck(c, route.Trash); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	paginate, err := r.IsPaginationEnabled(c)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.Code, errors.ErrBadRequest.Message)
+		return
+	}
+	itemPerPage, err := r.GetItemPerPage(c)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.Code, errors.ErrBadRequest.Message)
+		return
+	}
+	sortOrder, err := r.GetSortOrder(c)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.Code, errors.ErrBadRequest.Message)
+		return
+	}
+	page, err := r.GetPage(c)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.Code, errors.ErrBadRequest.Message)
+		return
+	}
+	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	groups, err := r.GetEffectiveOutputSerializationGroups(c, route.Trash)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrInternal.Code, errors.ErrInternal.Message)
+		return
+	}
+
+	var objects []T
+	if !paginate {
+		objects, err = requestOrm.GetAllDeleted(sortOrder)
+		if err != nil {
+			apiErr := trashError(err)
+			c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+			return
+		}
+		c.Render(200, SerializerRenderer{
+			Data:   objects,
+			Format: responseFormat,
+			Groups: groups,
+		})
+		return
+	}
+
+	objects, err = requestOrm.GetDeletedPaginatedList(itemPerPage, page, sortOrder)
+	if err != nil {
+		apiErr := trashError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+		return
+	}
+	if responseFormat == format.JSONLD {
+		count, err := requestOrm.CountDeleted()
+		if err != nil {
+			apiErr := trashError(err)
+			c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+			return
+		}
+		params := map[string]string{}
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+		c.Render(200, SerializerRenderer{
+			Data:   JsonldCollection(objects, c.Request.URL.String(), page+1, params, int((count+int64(itemPerPage)-1)/int64(itemPerPage))),
+			Format: responseFormat,
+			Groups: groups,
+		})
+		return
+	}
+	c.Render(200, SerializerRenderer{
+		Data:   objects,
+		Format: responseFormat,
+		Groups: groups,
+	})
+}
+
+// Restore handles HTTP POST requests to bring back a single soft deleted entity by ID.
+func (r *ApiRouter[T]) Restore(c *gin.Context) {
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	object, err := requestOrm.GetDeletedByID(r.IdParam(c))
+	if err != nil {
+		apiErr := trashError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+		return
+	}
+	r.restore(c, requestOrm, route.Restore, object)
+}
+
+// BatchRestore handles HTTP POST requests to bring back multiple soft deleted entities by their IDs.
+func (r *ApiRouter[T]) BatchRestore(c *gin.Context) {
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	ids, err := r.GetIdentifiers(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	if len(ids) == 0 {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.Code, errors.ErrBadRequest.Message)
+		return
+	}
+	objects, err := requestOrm.FindDeletedByIDs(ids)
+	if err != nil {
+		apiErr := trashError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+		return
+	}
+	r.restore(c, requestOrm, route.BatchRestore, objects...)
+}
+
+func (r *ApiRouter[T]) restore(c *gin.Context, requestOrm *orm.ORM[T], routeType route.RouteType, objects ...*T) {
+	for _, object := range objects {
+		if err := r.RestoringCheck(c, object); err != nil {
+			c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+			return
+		}
+	}
+	if err := r.AccessCheck(c, routeType, objects...); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	if err := r.write(c, requestOrm, routeType, objects, objects, func(o *orm.ORM[T]) error {
+		return o.Restore(objects...)
+	}); err != nil {
+		apiErr := trashError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+		return
+	}
+	r.invalidateResponseCache(objects...)
+	r.recordWrite(c, routeType, objects, objects)
+
+	responseFormat, err := ParseAcceptHeader(c.GetHeader("Accept"))
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	groups, err := r.GetEffectiveOutputSerializationGroups(c, routeType)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+
+	var data any = objects
+	if routeType == route.Restore {
+		data = objects[0]
+	}
+	c.Render(200, SerializerRenderer{
+		Data:   data,
+		Format: responseFormat,
+		Groups: groups,
+	})
+}
+
+// Purge handles HTTP DELETE requests to permanently remove a single soft deleted entity by ID.
+func (r *ApiRouter[T]) Purge(c *gin.Context) {
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	object, err := requestOrm.GetDeletedByID(r.IdParam(c))
+	if err != nil {
+		apiErr := trashError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+		return
+	}
+	r.purge(c, requestOrm, route.Purge, object)
+}
+
+// BatchPurge handles HTTP DELETE requests to permanently remove multiple soft deleted entities by their IDs.
+func (r *ApiRouter[T]) BatchPurge(c *gin.Context) {
+	requestOrm, err := r.RequestOrm(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	ids, err := r.GetIdentifiers(c)
+	if err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	if len(ids) == 0 {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.Code, errors.ErrBadRequest.Message)
+		return
+	}
+	objects, err := requestOrm.FindDeletedByIDs(ids)
+	if err != nil {
+		apiErr := trashError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+		return
+	}
+	r.purge(c, requestOrm, route.BatchPurge, objects...)
+}
+
+func (r *ApiRouter[T]) purge(c *gin.Context, requestOrm *orm.ORM[T], routeType route.RouteType, objects ...*T) {
+	for _, object := range objects {
+		if err := r.PurgingCheck(c, object); err != nil {
+			c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+			return
+		}
+	}
+	if err := r.AccessCheck(c, routeType, objects...); err != nil {
+		c.AbortWithStatusJSON(err.(errors.ApiError).Code, err.(errors.ApiError).Message)
+		return
+	}
+	if err := r.write(c, requestOrm, routeType, objects, nil, func(o *orm.ORM[T]) error {
+		return o.Purge(objects...)
+	}); err != nil {
+		apiErr := trashError(err)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+		return
+	}
+	r.invalidateResponseCache(objects...)
+	r.recordWrite(c, routeType, objects, nil)
+	c.JSON(204, nil)
+}
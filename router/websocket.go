@@ -0,0 +1,430 @@
+package router
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/audit"
+	"github.com/philiphil/restman/broadcast"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/route"
+	"github.com/philiphil/restman/security"
+	"golang.org/x/net/websocket"
+)
+
+// Types of the WebSocket messages
+const (
+	// SocketSubscribe is sent by clients to subscribe to a topic, to an entity of it when the message has an id
+	SocketSubscribe = "subscribe"
+	// SocketUnsubscribe is sent by clients to end a subscription
+	SocketUnsubscribe = "unsubscribe"
+	// SocketSubscribed confirms a subscription
+	SocketSubscribed = "subscribed"
+	// SocketEvent carries a change
+	SocketEvent = "event"
+	// SocketReset tells that changes of a subscription were missed, it ends the subscription when the client lagged behind
+	SocketReset = "reset"
+	// SocketError tells why a message was refused
+	SocketError = "error"
+	// SocketPing is sent by the server every heartbeat, clients answer with SocketPong
+	SocketPing = "ping"
+	SocketPong = "pong"
+)
+
+// SocketMessage is a JSON message of a WebSocket, in both directions
+type SocketMessage struct {
+	Type  string `json:"type"`
+	Topic string `json:"topic,omitempty"`
+	// ID is the id of the entity of the subscription, empty for the whole topic
+	ID string `json:"id,omitempty"`
+	// LastEventID resumes a subscription after the last change received
+	LastEventID uint64          `json:"lastEventId,omitempty"`
+	EventID     uint64          `json:"eventId,omitempty"`
+	Operation   audit.Operation `json:"operation,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	Code        int             `json:"code,omitempty"`
+	Error       string          `json:"error,omitempty"`
+
+	//subscription ended by the message
+	ended *topicSubscription
+}
+
+// Topic is an interface that any ApiRouter implements to stream its changes over a WebSocket
+type Topic interface {
+	// GetSubresourceName returns the name of the resource, which is the name of its topic
+	GetSubresourceName() string
+	// FirewallCheck authenticates the request with the firewalls of the ApiRouter, the result is kept on the context
+	FirewallCheck(c *gin.Context) (security.User, error)
+	subscribeTopic(c *gin.Context, message SocketMessage, out chan<- SocketMessage) (*topicSubscription, error)
+}
+
+type topicSubscription struct {
+	done  chan struct{}
+	close func()
+}
+
+type topicKey struct {
+	topic, id string
+}
+
+// WebSocket serves the change streams of several ApiRouters on a single connection per client.
+// Every subscription is authorized like the stream routes of its ApiRouter, by its firewalls and the reading rights on its entities.
+// To create a WebSocket, you should use the NewWebSocket function and serve its Handle method
+type WebSocket struct {
+	// Firewalls are optional, when set only the clients they authenticate can connect
+	Firewalls []security.Firewall
+	// CheckOrigin accepts the Origin of the handshakes, same origin handshakes and those without Origin are accepted by default
+	CheckOrigin func(req *http.Request) bool
+	// Heartbeat is the interval of the pings, clients sending nothing for two of them are disconnected
+	Heartbeat time.Duration
+	// WriteTimeout bounds every write, clients reading too slowly are disconnected
+	WriteTimeout time.Duration
+	// Buffer is the number of changes waiting to be written to a client, a subscription lagging further is reset
+	Buffer int
+
+	topics map[string]Topic
+}
+
+// NewWebSocket creates a WebSocket serving the changes of the given ApiRouters, see EnableStreaming.
+func NewWebSocket(topics ...Topic) *WebSocket {
+	ws := &WebSocket{
+		Heartbeat:    30 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		Buffer:       64,
+		topics:       map[string]Topic{},
+	}
+	ws.Register(topics...)
+	return ws
+}
+
+// Register adds the topics of ApiRouters, a topic may be subscribed when its ApiRouter has the matching stream route.
+func (ws *WebSocket) Register(topics ...Topic) {
+	for _, topic := range topics {
+		ws.topics[topic.GetSubresourceName()] = topic
+	}
+}
+
+// Handle handles the HTTP GET requests opening a WebSocket.
+func (ws *WebSocket) Handle(c *gin.Context) {
+	user, err := ws.authenticate(c)
+	if err != nil {
+		apiErr, ok := err.(errors.ApiError)
+		if !ok {
+			apiErr = errors.ErrInternal
+		}
+		c.AbortWithStatusJSON(apiErr.Code, apiErr.Message)
+		return
+	}
+	//the firewalls of the ApiRouters run once per connection on base, every subscription gets its own copy
+	base := socketContext(c)
+	if user != nil {
+		base.Set(security.CurrentUserContextKey, user)
+		base.Request = base.Request.WithContext(security.WithCurrentUser(base.Request.Context(), user))
+	}
+	server := websocket.Server{
+		Handshake: ws.handshake,
+		Handler: func(conn *websocket.Conn) {
+			ws.serve(base, conn)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// authenticate returns the user of the first firewall of the WebSocket authenticating the request, nil without firewalls.
+func (ws *WebSocket) authenticate(c *gin.Context) (security.User, error) {
+	if len(ws.Firewalls) == 0 {
+		return nil, nil
+	}
+	for _, firewall := range ws.Firewalls {
+		user, err := firewall.GetUser(c)
+		if err == nil && user != nil {
+			return user, nil
+		}
+		if apiErr, ok := err.(errors.ApiError); err != nil && (!ok || apiErr.Blocking) {
+			return nil, err
+		}
+	}
+	return nil, errors.ErrUnauthorized
+}
+
+func (ws *WebSocket) handshake(config *websocket.Config, req *http.Request) error {
+	if ws.CheckOrigin != nil {
+		if !ws.CheckOrigin(req) {
+			return errors.ErrForbidden
+		}
+		return nil
+	}
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host != req.Host {
+		return errors.ErrForbidden
+	}
+	return nil
+}
+
+// serve runs a connection: a goroutine reads the messages of the client, the subscriptions send their changes on out
+// and the changes are written here, one at a time.
+func (ws *WebSocket) serve(base *gin.Context, conn *websocket.Conn) {
+	conn.MaxPayloadBytes = 1 << 16
+	done := make(chan struct{})
+	defer close(done)
+	received := make(chan SocketMessage)
+	go func() {
+		defer close(received)
+		for {
+			conn.SetReadDeadline(time.Now().Add(2 * ws.Heartbeat))
+			var data []byte
+			if err := websocket.Message.Receive(conn, &data); err != nil {
+				return
+			}
+			var message SocketMessage
+			if err := json.Unmarshal(data, &message); err != nil {
+				message = SocketMessage{Type: SocketError, Code: errors.ErrBadFormat.Code, Error: errors.ErrBadFormat.Message}
+			}
+			select {
+			case received <- message:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	out := make(chan SocketMessage, ws.Buffer)
+	subscriptions := map[topicKey]*topicSubscription{}
+	defer func() {
+		for _, subscription := range subscriptions {
+			subscription.close()
+		}
+	}()
+	send := func(message SocketMessage) error {
+		conn.SetWriteDeadline(time.Now().Add(ws.WriteTimeout))
+		return websocket.JSON.Send(conn, message)
+	}
+
+	heartbeat := time.NewTicker(ws.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case message, ok := <-received:
+			if !ok {
+				return
+			}
+			err = ws.receive(base, message, subscriptions, out, send)
+		case message := <-out:
+			key := topicKey{message.Topic, message.ID}
+			if message.ended != nil && subscriptions[key] == message.ended {
+				delete(subscriptions, key)
+			}
+			err = send(message)
+		case <-heartbeat.C:
+			err = send(SocketMessage{Type: SocketPing})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// receive handles a message of the client.
+func (ws *WebSocket) receive(base *gin.Context, message SocketMessage, subscriptions map[topicKey]*topicSubscription, out chan<- SocketMessage, send func(SocketMessage) error) error {
+	key := topicKey{message.Topic, message.ID}
+	refuse := func(err error) error {
+		apiErr, ok := err.(errors.ApiError)
+		if !ok {
+			apiErr = errors.ErrInternal
+		}
+		return send(SocketMessage{Type: SocketError, Topic: message.Topic, ID: message.ID, Code: apiErr.Code, Error: apiErr.Message})
+	}
+	switch message.Type {
+	case SocketSubscribe:
+		topic, ok := ws.topics[message.Topic]
+		if !ok {
+			return refuse(errors.ErrNotFound)
+		}
+		if subscription, ok := subscriptions[key]; ok {
+			subscription.close()
+			delete(subscriptions, key)
+		}
+		//the result is kept on base for the next subscriptions to the topic, its error is reported by subscribeTopic
+		topic.FirewallCheck(base)
+		subscription, err := topic.subscribeTopic(socketContext(base), message, out)
+		if err != nil {
+			return refuse(err)
+		}
+		subscriptions[key] = subscription
+		return send(SocketMessage{Type: SocketSubscribed, Topic: message.Topic, ID: message.ID})
+	case SocketUnsubscribe:
+		if subscription, ok := subscriptions[key]; ok {
+			subscription.close()
+			delete(subscriptions, key)
+		}
+		return nil
+	case SocketPing:
+		return send(SocketMessage{Type: SocketPong})
+	case SocketPong:
+		return nil
+	case SocketError:
+		return send(message)
+	}
+	return refuse(errors.ErrBadRequest)
+}
+
+// socketContext copies the context of the handshake for the connection and its subscriptions.
+// The connection is hijacked, so the headers and bodies written by the firewalls go to a discardWriter.
+func socketContext(base *gin.Context) *gin.Context {
+	c := base.Copy()
+	c.Writer = &discardWriter{header: http.Header{}, status: http.StatusOK, size: -1}
+	return c
+}
+
+// discardWriter is a gin.ResponseWriter dropping what is written
+type discardWriter struct {
+	header http.Header
+	status int
+	size   int
+}
+
+func (w *discardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardWriter) WriteHeader(code int) {
+	if !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *discardWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+	}
+}
+
+func (w *discardWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	w.size += len(data)
+	return len(data), nil
+}
+
+func (w *discardWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *discardWriter) Status() int {
+	return w.status
+}
+
+func (w *discardWriter) Size() int {
+	return w.size
+}
+
+func (w *discardWriter) Written() bool {
+	return w.size != -1
+}
+
+func (w *discardWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, http.ErrNotSupported
+}
+
+func (w *discardWriter) Flush() {}
+
+func (w *discardWriter) CloseNotify() <-chan bool {
+	return nil
+}
+
+func (w *discardWriter) Pusher() http.Pusher {
+	return nil
+}
+
+// subscribeTopic subscribes a WebSocket client to the changes of the entities of this ApiRouter, or of a single one when the message has an id.
+// The changes are filtered and serialized in a goroutine owning c, then sent on out.
+func (r *ApiRouter[T]) subscribeTopic(c *gin.Context, message SocketMessage, out chan<- SocketMessage) (*topicSubscription, error) {
+	streamRoute := route.Stream
+	if message.ID != "" {
+		streamRoute = route.ItemStream
+	}
+	if _, ok := r.Routes[streamRoute]; !ok {
+		return nil, errors.ErrNotFound
+	}
+	if r.Broadcast == nil {
+		return nil, errors.ErrNotImplemented
+	}
+	routeType, entityID, err := r.streamCheck(c, message.ID)
+	if err != nil {
+		return nil, err
+	}
+	tenant, conditions, err := r.requestScope(c)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, replay, complete := r.Broadcast.Subscribe(message.LastEventID)
+	topic := &topicSubscription{done: make(chan struct{})}
+	topic.close = func() {
+		close(topic.done)
+		subscription.Close()
+	}
+	reply := SocketMessage{Topic: message.Topic, ID: message.ID}
+	emit := func(message SocketMessage) bool {
+		select {
+		case out <- message:
+			return true
+		case <-topic.done:
+			return false
+		}
+	}
+	go func() {
+		if !complete {
+			reset := reply
+			reset.Type = SocketReset
+			if !emit(reset) {
+				return
+			}
+		}
+		forward := func(change broadcast.Message[T]) bool {
+			data, err := r.streamed(c, routeType, change, tenant, conditions, entityID)
+			if err != nil || data == nil {
+				return err == nil
+			}
+			event := reply
+			event.Type = SocketEvent
+			event.EventID = change.ID
+			event.Operation = change.Operation
+			event.Data = data
+			return emit(event)
+		}
+		for _, change := range replay {
+			if !forward(change) {
+				return
+			}
+		}
+		for {
+			select {
+			case change, ok := <-subscription.C():
+				if !ok {
+					//too slow, the client resubscribes from its last event
+					reset := reply
+					reset.Type = SocketReset
+					reset.ended = topic
+					emit(reset)
+					return
+				}
+				if !forward(change) {
+					return
+				}
+			case <-topic.done:
+				return
+			}
+		}
+	}()
+	return topic, nil
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/event"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/route"
+)
+
+// write runs a write with requestOrm, within the transaction of a transactional event bus when there is one.
+// before and after are the states of the written entities, after is read once the write is done.
+func (r *ApiRouter[T]) write(c *gin.Context, requestOrm *orm.ORM[T], routeType route.RouteType, before []*T, after []*T, write func(o *orm.ORM[T]) error) error {
+	bus, ok := r.EventBus.(event.TransactionalBus)
+	if !ok {
+		return write(requestOrm)
+	}
+	ctx := context.Background()
+	if c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	return bus.Transaction(ctx, func(ctx context.Context) ([]event.Event, error) {
+		if err := write(requestOrm.WithContext(ctx)); err != nil {
+			return nil, err
+		}
+		e, err := r.event(c, routeType, before, after)
+		if err != nil {
+			return nil, err
+		}
+		return []event.Event{e}, nil
+	})
+}
+
+// recordWrite audits a successful write, records the revisions it created, publishes its event and streams its changes.
+func (r *ApiRouter[T]) recordWrite(c *gin.Context, routeType route.RouteType, before []*T, after []*T) {
+	r.audit(c, routeType, before, after)
+	r.recordRevisions(c, routeType, before, after)
+	r.publish(c, routeType, before, after)
+	r.broadcast(c, routeType, before, after)
+}
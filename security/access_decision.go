@@ -0,0 +1,85 @@
+package security
+
+// DecisionStrategy is how an AccessDecisionManager combines the votes of its voters
+type DecisionStrategy int8
+
+const (
+	// StrategyAffirmative grants access as soon as one voter grants it
+	StrategyAffirmative DecisionStrategy = iota
+	// StrategyConsensus grants access if more voters grant than deny it
+	StrategyConsensus
+	// StrategyUnanimous grants access only if no voter denies it
+	StrategyUnanimous
+)
+
+// AccessDecisionManager decides on attributes and expressions by polling its voters
+// AllowIfAllAbstain decides when every voter abstains, AllowIfEqualGranted breaks the ties of StrategyConsensus
+type AccessDecisionManager struct {
+	Voters              []Voter
+	Strategy            DecisionStrategy
+	AllowIfAllAbstain   bool
+	AllowIfEqualGranted bool
+}
+
+// NewAccessDecisionManager creates an AccessDecisionManager denying access when every voter abstains.
+// Ties of StrategyConsensus are granted.
+func NewAccessDecisionManager(strategy DecisionStrategy, voters ...Voter) *AccessDecisionManager {
+	return &AccessDecisionManager{
+		Voters:              voters,
+		Strategy:            strategy,
+		AllowIfEqualGranted: true,
+	}
+}
+
+// DefaultAccessDecisionManager returns an affirmative AccessDecisionManager polling the RoleVoter,
+// the AttributeVoter and the AuthenticatedVoter.
+func DefaultAccessDecisionManager() *AccessDecisionManager {
+	return NewAccessDecisionManager(StrategyAffirmative, RoleVoter{}, AttributeVoter{}, AuthenticatedVoter{})
+}
+
+// AddVoter adds one or more voters to the manager.
+func (m *AccessDecisionManager) AddVoter(voters ...Voter) {
+	m.Voters = append(m.Voters, voters...)
+}
+
+// Decide checks if the user is granted the attribute on the subject.
+func (m *AccessDecisionManager) Decide(user User, attribute string, subject any) bool {
+	granted, denied := 0, 0
+	for _, voter := range m.Voters {
+		switch voter.Vote(user, attribute, subject) {
+		case AccessGranted:
+			if m.Strategy == StrategyAffirmative {
+				return true
+			}
+			granted++
+		case AccessDenied:
+			if m.Strategy == StrategyUnanimous {
+				return false
+			}
+			denied++
+		}
+	}
+
+	switch {
+	case granted == 0 && denied == 0:
+		return m.AllowIfAllAbstain
+	case m.Strategy == StrategyConsensus && granted == denied:
+		return m.AllowIfEqualGranted
+	case m.Strategy == StrategyConsensus:
+		return granted > denied
+	}
+	//affirmative without grant, or unanimous without denial
+	return granted > 0
+}
+
+// IsGranted evaluates a security expression for the user on the subject, every attribute of it is decided by the voters.
+// An empty expression is always granted.
+func (m *AccessDecisionManager) IsGranted(user User, expression string, subject any) (bool, error) {
+	parsed, err := ParseExpression(expression)
+	if err != nil {
+		return false, err
+	}
+	return parsed.Evaluate(func(attribute string) bool {
+		return m.Decide(user, attribute, subject)
+	}), nil
+}
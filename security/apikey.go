@@ -0,0 +1,192 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// APIKeyContextKey is the gin context key under which APIKeyFirewall stores the authenticated key
+const APIKeyContextKey = "restman.apiKey"
+
+// DefaultAPIKeyHeader is the header read by APIKeyFirewall when none is set
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// APIKey is a stored API key, only the hash of the key is kept at rest
+type APIKey struct {
+	// Hash is the HashAPIKey of the key
+	Hash   string
+	User   User
+	Scopes []string
+	// ExpiresAt is the end of validity of the key, it never expires when zero
+	ExpiresAt time.Time
+}
+
+// HasScope checks if the key was granted scope.
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired checks if the key is no longer valid at now.
+func (k APIKey) Expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// HashAPIKey returns the hex encoded SHA-256 of key, under which it is stored and looked up.
+// API keys are random and long, a fast hash is enough and keeps the lookup indexable.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey returns a new random key, to be shown once to its owner, and the hash to store.
+// prefix, such as "rm_live_", helps identifying leaked keys.
+func GenerateAPIKey(prefix string) (key string, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	key = prefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, HashAPIKey(key), nil
+}
+
+// APIKeyProvider loads a stored API key by its hash
+type APIKeyProvider interface {
+	LoadAPIKey(c *gin.Context, hash string) (*APIKey, error)
+}
+
+// APIKeyProviderFunc adapts a function to the APIKeyProvider interface
+type APIKeyProviderFunc func(c *gin.Context, hash string) (*APIKey, error)
+
+// LoadAPIKey calls the function.
+func (f APIKeyProviderFunc) LoadAPIKey(c *gin.Context, hash string) (*APIKey, error) {
+	return f(c, hash)
+}
+
+// StaticAPIKeys is an in-memory APIKeyProvider, typically built from the configuration
+type StaticAPIKeys []APIKey
+
+// LoadAPIKey returns the key with the given hash.
+func (keys StaticAPIKeys) LoadAPIKey(c *gin.Context, hash string) (*APIKey, error) {
+	for _, key := range keys {
+		if key.Hash == hash {
+			return &key, nil
+		}
+	}
+	return nil, errors.ErrUnauthorized
+}
+
+// APIKeyEntity is an interface for entities storing an API key
+type APIKeyEntity interface {
+	GetAPIKey() APIKey
+}
+
+// ORMAPIKeyProvider loads API keys stored by a RestMan ORM, T must implement APIKeyEntity
+type ORMAPIKeyProvider[T entity.Entity] struct {
+	ORM *orm.ORM[T]
+	// Field is the database field holding the hash of the key
+	Field string
+}
+
+// NewORMAPIKeyProvider creates an API key provider looking keys up by their hash in field.
+func NewORMAPIKeyProvider[T entity.Entity](o *orm.ORM[T], field string) *ORMAPIKeyProvider[T] {
+	return &ORMAPIKeyProvider[T]{ORM: o, Field: field}
+}
+
+// LoadAPIKey returns the key whose field equals hash.
+func (p *ORMAPIKeyProvider[T]) LoadAPIKey(c *gin.Context, hash string) (*APIKey, error) {
+	item, err := findOneBy(p.ORM, p.Field, hash)
+	if err != nil {
+		return nil, err
+	}
+	holder, ok := any(item).(APIKeyEntity)
+	if !ok {
+		return nil, errors.ErrNotImplemented
+	}
+	key := holder.GetAPIKey()
+	return &key, nil
+}
+
+// APIKeyFirewall authenticates requests with an API key sent in a header or, when enabled, a query parameter
+// Requests without a key get the non-blocking ErrNoCredentials, so that other firewalls can be tried
+// Unknown and expired keys are rejected with ErrUnauthorized, keys lacking one of Scopes with ErrForbidden
+// The authenticated key is kept in the gin context, see CurrentAPIKey
+type APIKeyFirewall struct {
+	Keys APIKeyProvider
+	// Header carrying the key, DefaultAPIKeyHeader when empty
+	Header string
+	// QueryParameter carrying the key, keys are only read from the header when empty
+	// Query strings end up in access logs, prefer the header
+	QueryParameter string
+	// Scopes every key must have been granted to pass this firewall
+	Scopes []string
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+}
+
+// NewAPIKeyFirewall creates an API key firewall reading the DefaultAPIKeyHeader.
+func NewAPIKeyFirewall(keys APIKeyProvider, scopes ...string) *APIKeyFirewall {
+	return &APIKeyFirewall{Keys: keys, Scopes: scopes}
+}
+
+// GetUser looks the key of the request up and returns its user.
+func (f *APIKeyFirewall) GetUser(c *gin.Context) (User, error) {
+	header := f.Header
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	presented := c.GetHeader(header)
+	if presented == "" && f.QueryParameter != "" {
+		presented = c.Query(f.QueryParameter)
+	}
+	if presented == "" {
+		return nil, errors.ErrNoCredentials
+	}
+	if f.Keys == nil {
+		return nil, errors.ErrNotImplemented
+	}
+
+	key, err := f.Keys.LoadAPIKey(c, HashAPIKey(presented))
+	if err != nil || key == nil || key.User == nil {
+		if apiErr, ok := err.(errors.ApiError); ok && apiErr.Code >= 500 {
+			return nil, apiErr
+		}
+		return nil, errors.ErrUnauthorized
+	}
+	now := time.Now()
+	if f.Now != nil {
+		now = f.Now()
+	}
+	if key.Expired(now) {
+		return nil, errors.ErrUnauthorized
+	}
+	for _, scope := range f.Scopes {
+		if !key.HasScope(scope) {
+			return nil, errors.ErrForbidden
+		}
+	}
+	c.Set(APIKeyContextKey, key)
+	return key.User, nil
+}
+
+// CurrentAPIKey returns the API key that authenticated the request, if any.
+func CurrentAPIKey(c *gin.Context) (*APIKey, bool) {
+	value, ok := c.Get(APIKeyContextKey)
+	if !ok {
+		return nil, false
+	}
+	key, ok := value.(*APIKey)
+	return key, ok
+}
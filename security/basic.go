@@ -0,0 +1,97 @@
+package security
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+)
+
+// BasicFirewall authenticates requests with HTTP Basic credentials
+// Users are loaded by username from the UserProvider and must implement PasswordUser
+// Requests without Basic credentials get the non-blocking ErrNoCredentials, so that other firewalls can be tried
+// Wrong credentials are rejected with the blocking ErrUnauthorized and a WWW-Authenticate challenge
+// The ApiRouter challenges the anonymous requests it ends up rejecting as well, see Challenger
+type BasicFirewall struct {
+	Users UserProvider
+	// Realm is announced in the challenge, "restman" when empty
+	Realm string
+}
+
+// NewBasicFirewall creates a Basic firewall loading users from users.
+func NewBasicFirewall(users UserProvider, realm string) *BasicFirewall {
+	return &BasicFirewall{Users: users, Realm: realm}
+}
+
+var (
+	dummyPasswordHash     string
+	dummyPasswordHashOnce sync.Once
+)
+
+// GetUser checks the Basic credentials of the request and returns their user.
+func (f *BasicFirewall) GetUser(c *gin.Context) (User, error) {
+	scheme, _, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Basic") {
+		return nil, errors.ErrNoCredentials
+	}
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return nil, f.challenge(c)
+	}
+	if f.Users == nil {
+		return nil, errors.ErrNotImplemented
+	}
+
+	user, err := authenticatePassword(c, f.Users, username, password)
+	if err != nil {
+		if err == errors.ErrUnauthorized {
+			return nil, f.challenge(c)
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// authenticatePassword loads the user identified by identifier and verifies its password.
+// Wrong credentials give ErrUnauthorized, server side failures of the provider are passed through.
+func authenticatePassword(c *gin.Context, users UserProvider, identifier, password string) (User, error) {
+	user, err := users.LoadUser(c, identifier)
+	hash := ""
+	if err == nil && user != nil {
+		if passwordUser, ok := user.(PasswordUser); ok {
+			hash = passwordUser.GetPasswordHash()
+		}
+	}
+	if hash == "" {
+		//unknown users cost as much as known ones, so that response times do not reveal identifiers
+		dummyPasswordHashOnce.Do(func() {
+			dummyPasswordHash, _ = BcryptHasher{}.Hash("restman")
+		})
+		VerifyPassword(dummyPasswordHash, password)
+		if apiErr, ok := err.(errors.ApiError); ok && apiErr.Code >= 500 {
+			return nil, apiErr
+		}
+		return nil, errors.ErrUnauthorized
+	}
+	if !VerifyPassword(hash, password) {
+		return nil, errors.ErrUnauthorized
+	}
+	return user, nil
+}
+
+// Challenge returns the WWW-Authenticate challenge of the firewall.
+func (f *BasicFirewall) Challenge() string {
+	realm := f.Realm
+	if realm == "" {
+		realm = "restman"
+	}
+	return "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+}
+
+// challenge sets the WWW-Authenticate header answered with the 401.
+func (f *BasicFirewall) challenge(c *gin.Context) error {
+	c.Header("WWW-Authenticate", f.Challenge())
+	return errors.ErrUnauthorized
+}
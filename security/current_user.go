@@ -0,0 +1,31 @@
+package security
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentUserContextKey is the gin context key under which the ApiRouter stores the authenticated user
+const CurrentUserContextKey = "restman.user"
+
+type currentUserKey struct{}
+
+// WithCurrentUser returns a copy of ctx carrying user.
+func WithCurrentUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, currentUserKey{}, user)
+}
+
+// CurrentUser returns the authenticated user of a request, false for anonymous requests.
+// ctx may be the gin context of a handler, or a context derived from the request context,
+// such as the one given to repositories and their database callbacks.
+func CurrentUser(ctx context.Context) (User, bool) {
+	var value any
+	if c, ok := ctx.(*gin.Context); ok {
+		value, _ = c.Get(CurrentUserContextKey)
+	} else if ctx != nil {
+		value = ctx.Value(currentUserKey{})
+	}
+	user, ok := value.(User)
+	return user, ok && user != nil
+}
@@ -0,0 +1,193 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expression is a boolean combination of attributes
+// for example "ROLE_ADMIN or (ROLE_EDITOR and BOOK_EDIT)"
+// operators are "and" (&&), "or" (||) and "not" (!), parentheses group them
+type Expression interface {
+	Evaluate(decide func(attribute string) bool) bool
+}
+
+type attributeExpression string
+
+func (e attributeExpression) Evaluate(decide func(string) bool) bool {
+	return decide(string(e))
+}
+
+type notExpression struct {
+	operand Expression
+}
+
+func (e notExpression) Evaluate(decide func(string) bool) bool {
+	return !e.operand.Evaluate(decide)
+}
+
+type andExpression []Expression
+
+func (e andExpression) Evaluate(decide func(string) bool) bool {
+	for _, operand := range e {
+		if !operand.Evaluate(decide) {
+			return false
+		}
+	}
+	return true
+}
+
+type orExpression []Expression
+
+func (e orExpression) Evaluate(decide func(string) bool) bool {
+	for _, operand := range e {
+		if operand.Evaluate(decide) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseExpression parses a security expression, an empty expression is always true.
+func ParseExpression(expression string) (Expression, error) {
+	tokens, err := tokenizeExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return andExpression{}, nil
+	}
+	parser := &expressionParser{tokens: tokens}
+	parsed, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.position < len(tokens) {
+		return nil, fmt.Errorf("unexpected %q in security expression %q", tokens[parser.position], expression)
+	}
+	return parsed, nil
+}
+
+// MustParseExpression is like ParseExpression but panics if the expression cannot be parsed.
+// It is meant for the expressions known at startup.
+func MustParseExpression(expression string) Expression {
+	parsed, err := ParseExpression(expression)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+type expressionParser struct {
+	tokens   []string
+	position int
+}
+
+func (p *expressionParser) next() string {
+	if p.position >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.position]
+}
+
+func (p *expressionParser) parseOr() (Expression, error) {
+	operands := orExpression{}
+	for {
+		operand, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if token := p.next(); token != "or" && token != "||" {
+			break
+		}
+		p.position++
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *expressionParser) parseAnd() (Expression, error) {
+	operands := andExpression{}
+	for {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if token := p.next(); token != "and" && token != "&&" {
+			break
+		}
+		p.position++
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *expressionParser) parseUnary() (Expression, error) {
+	token := p.next()
+	p.position++
+	switch token {
+	case "":
+		return nil, fmt.Errorf("unexpected end of security expression")
+	case "not", "!":
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{operand}, nil
+	case "(":
+		operand, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing ) in security expression")
+		}
+		p.position++
+		return operand, nil
+	case ")", "and", "&&", "or", "||":
+		return nil, fmt.Errorf("unexpected %q in security expression", token)
+	}
+	return attributeExpression(token), nil
+}
+
+func tokenizeExpression(expression string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '!':
+			tokens = append(tokens, "!")
+			i++
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("unexpected %q in security expression %q", r, expression)
+			}
+			tokens = append(tokens, string([]rune{r, r}))
+			i += 2
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()!&|", runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			if lower := strings.ToLower(word); lower == "and" || lower == "or" || lower == "not" {
+				word = lower
+			}
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens, nil
+}
@@ -7,3 +7,9 @@ import "github.com/gin-gonic/gin"
 type Firewall interface {
 	GetUser(c *gin.Context) (User, error)
 }
+
+// Challenger is implemented by firewalls telling the clients how to authenticate
+// Challenge is sent in the WWW-Authenticate header of the 401 answered to anonymous requests
+type Challenger interface {
+	Challenge() string
+}
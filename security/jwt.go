@@ -0,0 +1,268 @@
+package security
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+)
+
+// JWTClaims are the claims of a verified token
+type JWTClaims map[string]any
+
+// String returns the claim as a string, or "" when it is absent or not a string.
+func (claims JWTClaims) String(name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// Subject returns the sub claim.
+func (claims JWTClaims) Subject() string {
+	return claims.String("sub")
+}
+
+// Issuer returns the iss claim.
+func (claims JWTClaims) Issuer() string {
+	return claims.String("iss")
+}
+
+// Audience returns the aud claim, which may be a single string or a list.
+func (claims JWTClaims) Audience() []string {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []any:
+		audience := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audience = append(audience, s)
+			}
+		}
+		return audience
+	}
+	return nil
+}
+
+// Time returns a NumericDate claim such as exp, nbf or iat.
+func (claims JWTClaims) Time(name string) (time.Time, bool) {
+	switch value := claims[name].(type) {
+	case float64:
+		return time.Unix(0, int64(value*float64(time.Second))), true
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(0, int64(f*float64(time.Second))), true
+	}
+	return time.Time{}, false
+}
+
+// JWTUserProvider maps the claims of a verified token to a User
+// Returning an ApiError lets the provider choose the response, any other error is reported as unauthorized
+type JWTUserProvider func(c *gin.Context, claims JWTClaims) (User, error)
+
+// JWTFirewall authenticates requests carrying an "Authorization: Bearer" JSON Web Token
+// Requests without a bearer token get the non-blocking ErrNoCredentials, so that other firewalls can be tried
+// Invalid tokens are rejected with the blocking ErrUnauthorized
+type JWTFirewall struct {
+	Keys         JWTKeySet
+	UserProvider JWTUserProvider
+	// Algorithms restricts the accepted "alg" headers, every supported algorithm is accepted when empty
+	Algorithms []string
+	// Issuer, when set, must equal the iss claim
+	Issuer string
+	// Audience, when set, must contain one of the aud claim values
+	Audience []string
+	// Leeway is the clock skew tolerated on exp, nbf and iat
+	Leeway time.Duration
+	// RequireExpiration rejects tokens without an exp claim
+	RequireExpiration bool
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+}
+
+// NewJWTFirewall creates a JWT firewall verifying tokens with keys and mapping their claims with provider.
+func NewJWTFirewall(keys JWTKeySet, provider JWTUserProvider) *JWTFirewall {
+	return &JWTFirewall{
+		Keys:              keys,
+		UserProvider:      provider,
+		Leeway:            time.Minute,
+		RequireExpiration: true,
+	}
+}
+
+// GetUser verifies the bearer token of the request and returns the user of its claims.
+func (f *JWTFirewall) GetUser(c *gin.Context) (User, error) {
+	token, ok := BearerToken(c)
+	if !ok {
+		return nil, errors.ErrNoCredentials
+	}
+	claims, err := f.Verify(token)
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	if f.UserProvider == nil {
+		return nil, errors.ErrNotImplemented
+	}
+	user, err := f.UserProvider(c, claims)
+	if err != nil {
+		if apiErr, ok := err.(errors.ApiError); ok {
+			return nil, apiErr
+		}
+		return nil, errors.ErrUnauthorized
+	}
+	if user == nil {
+		return nil, errors.ErrUnauthorized
+	}
+	return user, nil
+}
+
+// BearerToken returns the token of the "Authorization: Bearer" header, if any.
+func BearerToken(c *gin.Context) (string, bool) {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// Verify checks the signature and the registered claims of a compact JWT and returns its claims.
+func (f *JWTFirewall) Verify(token string) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	var header jwtHeader
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if !f.acceptsAlgorithm(header.Algorithm) {
+		return nil, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if f.Keys == nil {
+		return nil, ErrUnknownKey
+	}
+	keys, err := f.Keys.Keys(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range keys {
+		if key.Accepts(header.Algorithm) && verifyJWTSignature(header.Algorithm, key.verificationKey(), signingInput, signature) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrInvalidSignature
+	}
+
+	var claims JWTClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := f.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (f *JWTFirewall) acceptsAlgorithm(algorithm string) bool {
+	if _, ok := jwtAlgorithms[algorithm]; !ok {
+		return false
+	}
+	if len(f.Algorithms) == 0 {
+		return true
+	}
+	for _, a := range f.Algorithms {
+		if a == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *JWTFirewall) validateClaims(claims JWTClaims) error {
+	now := time.Now()
+	if f.Now != nil {
+		now = f.Now()
+	}
+	if exp, ok := claims.Time("exp"); ok {
+		if !now.Before(exp.Add(f.Leeway)) {
+			return ErrTokenExpired
+		}
+	} else if f.RequireExpiration {
+		return ErrInvalidClaims
+	}
+	if nbf, ok := claims.Time("nbf"); ok && now.Add(f.Leeway).Before(nbf) {
+		return ErrTokenNotYetValid
+	}
+	if iat, ok := claims.Time("iat"); ok && now.Add(f.Leeway).Before(iat) {
+		return ErrTokenNotYetValid
+	}
+	if f.Issuer != "" && claims.Issuer() != f.Issuer {
+		return ErrInvalidClaims
+	}
+	if len(f.Audience) > 0 && !intersects(f.Audience, claims.Audience()) {
+		return ErrInvalidClaims
+	}
+	return nil
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeJWTSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// SignJWT builds a compact JWT of claims signed with key, whose Algorithm must be set.
+// The key ID, if any, is written in the kid header.
+func SignJWT(claims JWTClaims, key JWTKey) (string, error) {
+	header, err := json.Marshal(jwtHeader{Algorithm: key.Algorithm, KeyID: key.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature, err := signJWT(key.Algorithm, key.Key, []byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
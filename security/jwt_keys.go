@@ -0,0 +1,327 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reasons a token is rejected by JWTFirewall.Verify, the firewall itself always answers ErrUnauthorized
+var (
+	ErrInvalidToken     = errors.New("jwt: malformed token or unsupported algorithm")
+	ErrInvalidSignature = errors.New("jwt: invalid signature")
+	ErrUnknownKey       = errors.New("jwt: no key found")
+	ErrTokenExpired     = errors.New("jwt: token expired")
+	ErrTokenNotYetValid = errors.New("jwt: token not valid yet")
+	ErrInvalidClaims    = errors.New("jwt: invalid claims")
+)
+
+// supported "alg" headers and their hash, "none" is never accepted
+var jwtAlgorithms = map[string]crypto.Hash{
+	"HS256": crypto.SHA256,
+	"HS384": crypto.SHA384,
+	"HS512": crypto.SHA512,
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"EdDSA": 0,
+}
+
+// JWTKey is a key able to verify, and possibly sign, tokens
+// Key is a []byte secret for HMAC, an *rsa.PublicKey or *rsa.PrivateKey for RSA,
+// an ed25519.PublicKey or ed25519.PrivateKey for EdDSA
+type JWTKey struct {
+	ID string
+	// Algorithm pins the key to a single "alg", any algorithm of the key type is accepted when empty
+	Algorithm string
+	Key       any
+}
+
+// Accepts checks that the key may verify a token signed with algorithm.
+// Pinning the family to the key type prevents a public key from being used as an HMAC secret.
+func (k JWTKey) Accepts(algorithm string) bool {
+	if k.Algorithm != "" && k.Algorithm != algorithm {
+		return false
+	}
+	switch k.Key.(type) {
+	case []byte:
+		return strings.HasPrefix(algorithm, "HS")
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return strings.HasPrefix(algorithm, "RS") || strings.HasPrefix(algorithm, "PS")
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return algorithm == "EdDSA"
+	}
+	return false
+}
+
+func (k JWTKey) verificationKey() any {
+	switch key := k.Key.(type) {
+	case *rsa.PrivateKey:
+		return &key.PublicKey
+	case ed25519.PrivateKey:
+		return key.Public()
+	}
+	return k.Key
+}
+
+// JWTKeySet provides the keys that may have signed a token
+// kid is the "kid" header of the token, it may be empty
+type JWTKeySet interface {
+	Keys(kid string) ([]JWTKey, error)
+}
+
+// StaticKeySet is a fixed list of keys, typically read from the configuration
+type StaticKeySet []JWTKey
+
+// Keys returns the keys identified by kid, keys without ID match any kid.
+func (s StaticKeySet) Keys(kid string) ([]JWTKey, error) {
+	keys := make([]JWTKey, 0, len(s))
+	for _, key := range s {
+		if kid == "" || key.ID == "" || key.ID == kid {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, ErrUnknownKey
+	}
+	return keys, nil
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	X         string `json:"x"`
+	K         string `json:"k"`
+}
+
+// ParseJWKS reads a JSON Web Key Set.
+// RSA, Ed25519 and symmetric signing keys are kept, the others are skipped.
+func ParseJWKS(data []byte) (StaticKeySet, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	keys := make(StaticKeySet, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.key()
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			keys = append(keys, JWTKey{ID: jwk.KeyID, Algorithm: jwk.Algorithm, Key: key})
+		}
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) key() (any, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 2 || exponent.Int64() > 1<<31-1 {
+			return nil, ErrInvalidToken
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, ErrInvalidToken
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		k, err := base64.RawURLEncoding.DecodeString(jwk.K)
+		if err != nil {
+			return nil, err
+		}
+		return k, nil
+	}
+	return nil, nil
+}
+
+// JWKSKeySet is a JSON Web Key Set loaded lazily and cached
+// Keys are reloaded once Lifetime has elapsed, or when a token names an unknown kid
+// but at most once per MinRefreshInterval, failed reloads included, so that forged kids or a failing source cannot hammer it
+type JWKSKeySet struct {
+	Load func() ([]byte, error)
+	// Lifetime of the loaded keys, they are kept forever when 0
+	Lifetime           time.Duration
+	MinRefreshInterval time.Duration
+
+	mu        sync.Mutex
+	keys      StaticKeySet
+	loaded    time.Time
+	attempted time.Time
+	failure   error
+}
+
+// NewJWKSFile creates a key set read from a local JWKS file.
+func NewJWKSFile(path string) *JWKSKeySet {
+	return &JWKSKeySet{
+		Load: func() ([]byte, error) {
+			return os.ReadFile(path)
+		},
+		MinRefreshInterval: time.Minute,
+	}
+}
+
+// NewJWKSURL creates a key set fetched from a JWKS endpoint and cached for lifetime.
+func NewJWKSURL(url string, lifetime time.Duration) *JWKSKeySet {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return &JWKSKeySet{
+		Load: func() ([]byte, error) {
+			response, err := client.Get(url)
+			if err != nil {
+				return nil, err
+			}
+			defer response.Body.Close()
+			if response.StatusCode != http.StatusOK {
+				return nil, errors.New("jwks: unexpected status " + response.Status)
+			}
+			return io.ReadAll(io.LimitReader(response.Body, 1<<20))
+		},
+		Lifetime:           lifetime,
+		MinRefreshInterval: time.Minute,
+	}
+}
+
+// Refresh reloads the keys from the source.
+func (s *JWKSKeySet) Refresh() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refresh()
+}
+
+// refresh must be called with the lock held
+func (s *JWKSKeySet) refresh() error {
+	s.attempted = time.Now()
+	s.failure = s.load()
+	return s.failure
+}
+
+func (s *JWKSKeySet) load() error {
+	data, err := s.Load()
+	if err != nil {
+		return err
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+	s.keys = keys
+	s.loaded = time.Now()
+	return nil
+}
+
+// mayRefresh tells whether MinRefreshInterval has elapsed since the last reload attempt, it must be called with the lock held
+func (s *JWKSKeySet) mayRefresh() bool {
+	return s.attempted.IsZero() || time.Since(s.attempted) >= s.MinRefreshInterval
+}
+
+// Keys returns the cached keys identified by kid, reloading the set when needed.
+// A failed reload keeps serving the previous keys, and is not retried before MinRefreshInterval.
+func (s *JWKSKeySet) Keys(kid string) ([]JWTKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if (s.keys == nil || (s.Lifetime > 0 && time.Since(s.loaded) > s.Lifetime)) && s.mayRefresh() {
+		s.refresh()
+	}
+	if s.keys == nil && s.failure != nil {
+		return nil, s.failure
+	}
+	keys, err := s.keys.Keys(kid)
+	if err == nil || !s.mayRefresh() {
+		return keys, err
+	}
+	if err := s.refresh(); err != nil {
+		return nil, ErrUnknownKey
+	}
+	return s.keys.Keys(kid)
+}
+
+func verifyJWTSignature(algorithm string, key any, signingInput, signature []byte) error {
+	hash := jwtAlgorithms[algorithm]
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(hash.New, k)
+		mac.Write(signingInput)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case *rsa.PublicKey:
+		hasher := hash.New()
+		hasher.Write(signingInput)
+		if strings.HasPrefix(algorithm, "PS") {
+			return rsa.VerifyPSS(k, hash, hasher.Sum(nil), signature, nil)
+		}
+		return rsa.VerifyPKCS1v15(k, hash, hasher.Sum(nil), signature)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, signingInput, signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return ErrInvalidSignature
+}
+
+func signJWT(algorithm string, key any, signingInput []byte) ([]byte, error) {
+	hash, ok := jwtAlgorithms[algorithm]
+	if !ok || !(JWTKey{Key: key}).Accepts(algorithm) {
+		return nil, ErrInvalidToken
+	}
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(hash.New, k)
+		mac.Write(signingInput)
+		return mac.Sum(nil), nil
+	case *rsa.PrivateKey:
+		hasher := hash.New()
+		hasher.Write(signingInput)
+		if strings.HasPrefix(algorithm, "PS") {
+			return rsa.SignPSS(rand.Reader, k, hash, hasher.Sum(nil), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.SignPKCS1v15(rand.Reader, k, hash, hasher.Sum(nil))
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, signingInput), nil
+	}
+	return nil, ErrInvalidToken
+}
@@ -0,0 +1,74 @@
+package security
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NonceStore remembers the nonces already seen, so that a signed request cannot be replayed
+// Use records nonce for ttl and reports whether it was unused
+type NonceStore interface {
+	Use(nonce string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonceStore is an in-process implementation of the NonceStore interface.
+// It is meant for single instance deployments and tests.
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	pruned time.Time
+}
+
+// NewMemoryNonceStore creates a new in-memory nonce store.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+// Use records the nonce unless it was already recorded and has not expired.
+func (m *MemoryNonceStore) Use(nonce string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	//expired nonces are dropped at most once per ttl, not on every call
+	if now.Sub(m.pruned) > ttl {
+		for n, expires := range m.nonces {
+			if now.After(expires) {
+				delete(m.nonces, n)
+			}
+		}
+		m.pruned = now
+	}
+	if expires, ok := m.nonces[nonce]; ok && !now.After(expires) {
+		return false, nil
+	}
+	m.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// RedisNonceStore is a Redis-based implementation of the NonceStore interface, shared by every instance.
+type RedisNonceStore struct {
+	Client *redis.Client
+	prefix string
+}
+
+// NewRedisNonceStore creates a new Redis nonce store with the specified connection parameters.
+func NewRedisNonceStore(addr, password string, db int) *RedisNonceStore {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	return &RedisNonceStore{
+		Client: client,
+		prefix: "restman:nonce:",
+	}
+}
+
+// Use atomically records the nonce with SET NX, it was unused when the key did not exist.
+func (r *RedisNonceStore) Use(nonce string, ttl time.Duration) (bool, error) {
+	return r.Client.SetNX(context.Background(), r.prefix+nonce, 1, ttl).Result()
+}
@@ -0,0 +1,195 @@
+package security
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// OwnerTag marks the field holding the owner of an entity: `security:"owner"`
+const OwnerTag = "owner"
+
+// OwnedEntity is an interface for entities exposing their owner through accessors
+// it takes precedence over the tagged field
+type OwnedEntity interface {
+	GetOwnerId() entity.Identifier
+	SetOwnerId(entity.Identifier) entity.Entity
+}
+
+// HasOwner checks if the object implements the OwnedEntity interface.
+func HasOwner(obj any) (OwnedEntity, bool) {
+	oe, ok := obj.(OwnedEntity)
+	return oe, ok
+}
+
+// Ownership restricts entities to the users owning them
+// Field is the struct field holding the owner id, when empty it is the field tagged `security:"owner"`
+// Users holding one of the Roles bypass the ownership and access every entity
+type Ownership struct {
+	Field string
+	Roles []string
+}
+
+// FieldName returns the name of the field holding the owner of object, or an empty string if it has none.
+func (o Ownership) FieldName(object any) string {
+	field, ok := o.field(reflect.TypeOf(object))
+	if !ok {
+		return ""
+	}
+	return field.Name
+}
+
+// Supports checks if the owner of object can be read and written.
+func (o Ownership) Supports(object any) bool {
+	if _, ok := HasOwner(object); ok {
+		return true
+	}
+	return o.FieldName(object) != ""
+}
+
+// Bypass checks if the user holds one of the roles bypassing the ownership.
+func (o Ownership) Bypass(user User) bool {
+	if user == nil {
+		return false
+	}
+	for _, role := range o.Roles {
+		if HasRole(user, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Owner returns the id of the owner of object as a string, empty when it has none yet.
+func (o Ownership) Owner(object any) string {
+	if oe, ok := HasOwner(object); ok {
+		return identifierString(oe.GetOwnerId())
+	}
+	value := reflect.ValueOf(object)
+	field, ok := o.field(value.Type())
+	if !ok {
+		return ""
+	}
+	fieldValue := value.FieldByIndex(field.Index)
+	if fieldValue.IsZero() {
+		return ""
+	}
+	if id, ok := fieldValue.Interface().(entity.Identifier); ok {
+		return identifierString(id)
+	}
+	return fmt.Sprint(fieldValue.Interface())
+}
+
+// Allows checks if the user may access object: the user owns it or bypasses the ownership.
+// Objects without owner are only accessed by the users bypassing the ownership, see AllowsCreation for the objects being created.
+func (o Ownership) Allows(user User, object any) bool {
+	if o.Bypass(user) {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	owner := o.Owner(object)
+	return owner != "" && owner == user.GetIdentifier().String()
+}
+
+// AllowsCreation checks if the user may create object: as Allows, and objects without owner are allowed too since SetOwner fills it.
+func (o Ownership) AllowsCreation(user User, object any) bool {
+	if user != nil && o.Owner(object) == "" {
+		return true
+	}
+	return o.Allows(user, object)
+}
+
+// SetOwner returns object owned by the user.
+func (o Ownership) SetOwner(object entity.Entity, user User) (entity.Entity, error) {
+	if oe, ok := HasOwner(object); ok {
+		return oe.SetOwnerId(user.GetIdentifier()), nil
+	}
+	value := reflect.ValueOf(object)
+	field, ok := o.field(value.Type())
+	if !ok {
+		return nil, fmt.Errorf("%s has no owner field", value.Type().Name())
+	}
+	owner, err := ownerValue(field.Type, user.GetIdentifier())
+	if err != nil {
+		return nil, err
+	}
+	copied := reflect.New(value.Type()).Elem()
+	copied.Set(value)
+	copied.FieldByIndex(field.Index).Set(owner)
+	return copied.Interface().(entity.Entity), nil
+}
+
+// OwnerValue returns the value stored in the owner field of object for the entities of the user.
+// It is the value lists are filtered on.
+func (o Ownership) OwnerValue(object entity.Entity, user User) (any, error) {
+	owned, err := o.SetOwner(object, user)
+	if err != nil {
+		return nil, err
+	}
+	value := reflect.ValueOf(owned)
+	field, ok := o.field(value.Type())
+	if !ok {
+		return nil, fmt.Errorf("%s has no owner field", value.Type().Name())
+	}
+	return value.FieldByIndex(field.Index).Interface(), nil
+}
+
+func (o Ownership) field(t reflect.Type) (reflect.StructField, bool) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	if o.Field != "" {
+		return t.FieldByName(o.Field)
+	}
+	for _, field := range reflect.VisibleFields(t) {
+		if field.Tag.Get("security") == OwnerTag {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// ownerValue converts the id of a user to the type of the owner field
+func ownerValue(fieldType reflect.Type, id entity.Identifier) (reflect.Value, error) {
+	value := reflect.ValueOf(id)
+	if value.Type().AssignableTo(fieldType) {
+		return value, nil
+	}
+	if zero, ok := reflect.Zero(fieldType).Interface().(entity.Identifier); ok {
+		parsed, err := zero.Parse(id.String())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if parsedValue := reflect.ValueOf(parsed); parsedValue.Type().AssignableTo(fieldType) {
+			return parsedValue, nil
+		}
+	}
+	switch fieldType.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(id.String()).Convert(fieldType), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(id.String(), 10, 64)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(i).Convert(fieldType), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(id.String(), 10, 64)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(u).Convert(fieldType), nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot store %T in an owner field of type %s", id, fieldType)
+}
+
+func identifierString(id entity.Identifier) string {
+	if id == nil || id.IsNull() {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,104 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher hashes passwords for storage and verifies them
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) bool
+}
+
+// BcryptHasher hashes passwords with bcrypt, Cost defaults to bcrypt.DefaultCost
+type BcryptHasher struct {
+	Cost int
+}
+
+// Hash returns the bcrypt hash of password.
+func (h BcryptHasher) Hash(password string) (string, error) {
+	cost := h.Cost
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	return string(hash), err
+}
+
+// Verify checks password against a bcrypt hash.
+func (h BcryptHasher) Verify(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// Argon2idHasher hashes passwords with argon2id, encoded in the PHC string format
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>
+type Argon2idHasher struct {
+	Time uint32
+	// Memory in KiB
+	Memory     uint32
+	Threads    uint8
+	KeyLength  uint32
+	SaltLength uint32
+}
+
+// NewArgon2idHasher returns an argon2id hasher with the parameters recommended by OWASP.
+func NewArgon2idHasher() Argon2idHasher {
+	return Argon2idHasher{Time: 2, Memory: 19 * 1024, Threads: 1, KeyLength: 32, SaltLength: 16}
+}
+
+// Hash returns the argon2id hash of password.
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify checks password against an argon2id hash, using the parameters stored in the hash.
+func (h Argon2idHasher) Verify(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil || time == 0 || threads == 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false
+	}
+	computed := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, computed) == 1
+}
+
+// VerifyPassword checks password against a bcrypt or argon2id hash, recognised by its prefix.
+// Both formats can coexist, which allows migrating from one to the other.
+func VerifyPassword(hash, password string) bool {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return Argon2idHasher{}.Verify(hash, password)
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return BcryptHasher{}.Verify(hash, password)
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package security
+
+import "github.com/philiphil/restman/orm"
+
+// ReadPolicyFunction compiles what a user may read into repository conditions
+// nil conditions let the user read every entity, an error rejects the request
+// unlike ReadingRights it is enforced by the queries, so lists, counts and pages only contain authorized entities
+type ReadPolicyFunction func(user User) ([]orm.Condition, error)
+
+// ReadPolicy is an interface for objects that restrict the entities a user may read
+// it should return a ReadPolicyFunction
+type ReadPolicy interface {
+	GetReadPolicy() ReadPolicyFunction
+}
+
+// HasReadPolicy checks if the object implements the ReadPolicy interface.
+func HasReadPolicy(obj any) (ReadPolicy, bool) {
+	rp, ok := obj.(ReadPolicy)
+	return rp, ok
+}
@@ -12,6 +12,24 @@ type WritingRights interface {
 	GetWritingRights() AuthorizationFunction
 }
 
+// TrashReadingRights is an interface for objects whose soft deleted items can be listed
+// it should return an AuthorizationFunction
+type TrashReadingRights interface {
+	GetTrashReadingRights() AuthorizationFunction
+}
+
+// RestoringRights is an interface for objects that have restoring rights once soft deleted
+// it should return an AuthorizationFunction
+type RestoringRights interface {
+	GetRestoringRights() AuthorizationFunction
+}
+
+// PurgingRights is an interface for objects that have rights to be permanently deleted
+// it should return an AuthorizationFunction
+type PurgingRights interface {
+	GetPurgingRights() AuthorizationFunction
+}
+
 // HasReadingRights checks if the object implements the ReadingRights interface.
 func HasReadingRights(obj any) (ReadingRights, bool) {
 	rr, ok := obj.(ReadingRights)
@@ -23,3 +41,21 @@ func HasWritingRights(obj any) (WritingRights, bool) {
 	rr, ok := obj.(WritingRights)
 	return rr, ok
 }
+
+// HasTrashReadingRights checks if the object implements the TrashReadingRights interface.
+func HasTrashReadingRights(obj any) (TrashReadingRights, bool) {
+	rr, ok := obj.(TrashReadingRights)
+	return rr, ok
+}
+
+// HasRestoringRights checks if the object implements the RestoringRights interface.
+func HasRestoringRights(obj any) (RestoringRights, bool) {
+	rr, ok := obj.(RestoringRights)
+	return rr, ok
+}
+
+// HasPurgingRights checks if the object implements the PurgingRights interface.
+func HasPurgingRights(obj any) (PurgingRights, bool) {
+	rr, ok := obj.(PurgingRights)
+	return rr, ok
+}
@@ -0,0 +1,203 @@
+package security
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/philiphil/restman/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// Session is the server side state of a logged in browser
+type Session struct {
+	// Identifier is what the user logged in with, it is given back to the UserProvider on every request
+	Identifier string    `json:"identifier"`
+	CSRFToken  string    `json:"csrf"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Expired checks if the session is no longer valid at now.
+func (s Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// SessionStore keeps sessions, the value returned by Save is what the session cookie holds
+type SessionStore interface {
+	Save(session Session) (string, error)
+	Load(value string) (*Session, error)
+	Delete(value string) error
+}
+
+// randomToken returns a random URL safe token of 32 bytes.
+func randomToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// CookieSessionStore keeps the whole session in the cookie, encrypted and authenticated with AES-256-GCM
+// Nothing is stored server side, so a session cannot be revoked before it expires: logging out only clears the cookie
+type CookieSessionStore struct {
+	aead cipher.AEAD
+}
+
+// NewCookieSessionStore creates a cookie session store, the encryption key is derived from secret.
+func NewCookieSessionStore(secret []byte) (*CookieSessionStore, error) {
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &CookieSessionStore{aead: aead}, nil
+}
+
+// Save encrypts the session.
+func (s *CookieSessionStore) Save(session Session) (string, error) {
+	plaintext, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// Load decrypts the session, tampered values are rejected.
+func (s *CookieSessionStore) Load(value string) (*Session, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return nil, errors.ErrUnauthorized
+	}
+	plaintext, err := s.aead.Open(nil, data[:s.aead.NonceSize()], data[s.aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	var session Session
+	if err := json.Unmarshal(plaintext, &session); err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	return &session, nil
+}
+
+// Delete does nothing, the session lives in the cookie.
+func (s *CookieSessionStore) Delete(value string) error {
+	return nil
+}
+
+// MemorySessionStore is an in-process implementation of the SessionStore interface, the cookie holds a random session id.
+// It is meant for single instance deployments and tests.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+// NewMemorySessionStore creates a new in-memory session store.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]Session)}
+}
+
+// Save stores the session under a new random id.
+func (m *MemorySessionStore) Save(session Session) (string, error) {
+	id, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for key, stored := range m.sessions {
+		if stored.Expired(now) {
+			delete(m.sessions, key)
+		}
+	}
+	m.sessions[id] = session
+	return id, nil
+}
+
+// Load returns the session stored under id.
+func (m *MemorySessionStore) Load(id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, errors.ErrUnauthorized
+	}
+	return &session, nil
+}
+
+// Delete removes the session stored under id.
+func (m *MemorySessionStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+// RedisSessionStore is a Redis-based implementation of the SessionStore interface, the cookie holds a random session id.
+type RedisSessionStore struct {
+	Client *redis.Client
+	prefix string
+}
+
+// NewRedisSessionStore creates a new Redis session store with the specified connection parameters.
+func NewRedisSessionStore(addr, password string, db int) *RedisSessionStore {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	return &RedisSessionStore{
+		Client: client,
+		prefix: "restman:session:",
+	}
+}
+
+// Save stores the session under a new random id until it expires.
+func (r *RedisSessionStore) Save(session Session) (string, error) {
+	id, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	if err := r.Client.Set(context.Background(), r.prefix+id, data, time.Until(session.ExpiresAt)).Err(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Load returns the session stored under id.
+func (r *RedisSessionStore) Load(id string) (*Session, error) {
+	data, err := r.Client.Get(context.Background(), r.prefix+id).Bytes()
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	return &session, nil
+}
+
+// Delete removes the session stored under id.
+func (r *RedisSessionStore) Delete(id string) error {
+	return r.Client.Del(context.Background(), r.prefix+id).Err()
+}
@@ -0,0 +1,192 @@
+package security
+
+import (
+	"crypto/hmac"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+)
+
+// Defaults of SessionFirewall
+const (
+	DefaultSessionCookie = "restman_session"
+	DefaultCSRFCookie    = "XSRF-TOKEN"
+	DefaultCSRFHeader    = "X-CSRF-Token"
+)
+
+const (
+	sessionContextKey     = "restman.session"
+	sessionUserContextKey = "restman.sessionUser"
+)
+
+// CSRFProtection is implemented by firewalls authenticating with ambient credentials, such as cookies
+// CheckCSRF is run by the ApiRouter on every request, it must let safe methods and requests without such credentials pass
+type CSRFProtection interface {
+	CheckCSRF(c *gin.Context) error
+}
+
+// SessionFirewall authenticates browsers with a session cookie, opened by Login and closed by Logout
+// Requests without a valid session get the non-blocking ErrNoCredentials, so that other firewalls can be tried
+// Unsafe requests authenticated by the session must echo its CSRF token in the CSRFHeader (synchronizer token),
+// the token is also given in a cookie readable by scripts, so that frontends can send it like a double submit cookie
+type SessionFirewall struct {
+	Store SessionStore
+	Users UserProvider
+	// Lifetime of a session, 12 hours when zero
+	Lifetime       time.Duration
+	CookieName     string
+	CSRFCookieName string
+	CSRFHeader     string
+	// Insecure allows the cookies over plain HTTP, for development only
+	Insecure bool
+}
+
+// NewSessionFirewall creates a session firewall keeping sessions in store and loading users from users.
+func NewSessionFirewall(store SessionStore, users UserProvider) *SessionFirewall {
+	return &SessionFirewall{
+		Store:          store,
+		Users:          users,
+		Lifetime:       12 * time.Hour,
+		CookieName:     DefaultSessionCookie,
+		CSRFCookieName: DefaultCSRFCookie,
+		CSRFHeader:     DefaultCSRFHeader,
+	}
+}
+
+// GetUser returns the user of the session cookie.
+// The session and its user are kept on the context, firewalls run several times per request.
+func (f *SessionFirewall) GetUser(c *gin.Context) (User, error) {
+	if user, ok := c.Get(sessionUserContextKey); ok {
+		return user.(User), nil
+	}
+	session, ok := f.session(c)
+	if !ok {
+		return nil, errors.ErrNoCredentials
+	}
+	if err := f.CheckCSRF(c); err != nil {
+		return nil, err
+	}
+	if f.Users == nil {
+		return nil, errors.ErrNotImplemented
+	}
+	user, err := f.Users.LoadUser(c, session.Identifier)
+	if err != nil || user == nil {
+		//the user is gone, the session is worthless
+		return nil, errors.ErrNoCredentials
+	}
+	c.Set(sessionUserContextKey, user)
+	return user, nil
+}
+
+// session loads the unexpired session of the request cookie.
+func (f *SessionFirewall) session(c *gin.Context) (*Session, bool) {
+	if session, ok := c.Get(sessionContextKey); ok {
+		return session.(*Session), true
+	}
+	value, err := c.Cookie(f.cookieName())
+	if err != nil || value == "" || f.Store == nil {
+		return nil, false
+	}
+	session, err := f.Store.Load(value)
+	if err != nil || session == nil || session.Expired(time.Now()) {
+		return nil, false
+	}
+	c.Set(sessionContextKey, session)
+	return session, true
+}
+
+// CheckCSRF rejects unsafe requests authenticated by a session whose CSRF token is missing or wrong.
+func (f *SessionFirewall) CheckCSRF(c *gin.Context) error {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return nil
+	}
+	session, ok := f.session(c)
+	if !ok {
+		return nil
+	}
+	header := f.CSRFHeader
+	if header == "" {
+		header = DefaultCSRFHeader
+	}
+	token := c.GetHeader(header)
+	if token == "" || !hmac.Equal([]byte(token), []byte(session.CSRFToken)) {
+		return errors.ErrForbidden
+	}
+	return nil
+}
+
+// Login verifies the password of identifier and opens a new session, whose cookies are set on the response.
+// Any previous session of the request is closed, so that a session id planted before login cannot be used.
+func (f *SessionFirewall) Login(c *gin.Context, identifier, password string) (*Session, error) {
+	if f.Users == nil || f.Store == nil {
+		return nil, errors.ErrNotImplemented
+	}
+	if _, err := authenticatePassword(c, f.Users, identifier, password); err != nil {
+		return nil, err
+	}
+	if value, err := c.Cookie(f.cookieName()); err == nil && value != "" {
+		_ = f.Store.Delete(value)
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, errors.ErrInternal
+	}
+	lifetime := f.Lifetime
+	if lifetime == 0 {
+		lifetime = 12 * time.Hour
+	}
+	session := Session{Identifier: identifier, CSRFToken: token, ExpiresAt: time.Now().Add(lifetime)}
+	value, err := f.Store.Save(session)
+	if err != nil {
+		return nil, errors.ErrInternal
+	}
+	f.setCookie(c, f.cookieName(), value, int(lifetime.Seconds()), true)
+	f.setCookie(c, f.csrfCookieName(), token, int(lifetime.Seconds()), false)
+	return &session, nil
+}
+
+// Logout closes the session of the request and clears its cookies.
+// It is a write, so the CSRF token of the session is required.
+func (f *SessionFirewall) Logout(c *gin.Context) error {
+	if err := f.CheckCSRF(c); err != nil {
+		return err
+	}
+	if value, err := c.Cookie(f.cookieName()); err == nil && value != "" && f.Store != nil {
+		if err := f.Store.Delete(value); err != nil {
+			return errors.ErrInternal
+		}
+	}
+	f.setCookie(c, f.cookieName(), "", -1, true)
+	f.setCookie(c, f.csrfCookieName(), "", -1, false)
+	return nil
+}
+
+func (f *SessionFirewall) setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   !f.Insecure,
+		HttpOnly: httpOnly,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (f *SessionFirewall) cookieName() string {
+	if f.CookieName == "" {
+		return DefaultSessionCookie
+	}
+	return f.CookieName
+}
+
+func (f *SessionFirewall) csrfCookieName() string {
+	if f.CSRFCookieName == "" {
+		return DefaultCSRFCookie
+	}
+	return f.CSRFCookieName
+}
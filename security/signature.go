@@ -0,0 +1,206 @@
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+)
+
+// Headers of a signed request
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureKeyHeader       = "X-Signature-Key"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+)
+
+const signatureContextKey = "restman.signatureUser"
+
+// DefaultSignatureMaxBodyBytes is the largest body a SignatureFirewall reads to verify a signature, unless MaxBodyBytes says otherwise
+const DefaultSignatureMaxBodyBytes = 10 << 20
+
+// SigningKey is a secret shared with a client, the requests it signs authenticate User
+// Rotating a secret means adding the new key next to the old one, then removing the old one once clients moved
+type SigningKey struct {
+	ID     string
+	Secret []byte
+	User   User
+	// NotAfter retires the key, it never expires when zero
+	NotAfter time.Time
+}
+
+// SigningKeyProvider returns the active keys named id
+// id is the SignatureKeyHeader of the request, it may be empty
+type SigningKeyProvider interface {
+	SigningKeys(c *gin.Context, id string) ([]SigningKey, error)
+}
+
+// StaticSigningKeys is a fixed list of keys, typically read from the configuration
+type StaticSigningKeys []SigningKey
+
+// SigningKeys returns the keys named id, or every key when id is empty.
+func (keys StaticSigningKeys) SigningKeys(c *gin.Context, id string) ([]SigningKey, error) {
+	matching := make([]SigningKey, 0, len(keys))
+	for _, key := range keys {
+		if id == "" || key.ID == id {
+			matching = append(matching, key)
+		}
+	}
+	return matching, nil
+}
+
+// RequestSignature returns the hex encoded HMAC-SHA256 of a request.
+// The signed string is the method, the request URI (path and query), the timestamp, the nonce
+// and the hex encoded SHA-256 of the body, separated by new lines.
+func RequestSignature(secret []byte, method, uri, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.ToUpper(method) + "\n" + uri + "\n" + timestamp + "\n" + nonce + "\n" + hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets the signature headers of an outgoing request, its body is left readable.
+func SignRequest(req *http.Request, keyID string, secret []byte) error {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(SignatureTimestampHeader, timestamp)
+	req.Header.Set(SignatureNonceHeader, hex.EncodeToString(nonce))
+	if keyID != "" {
+		req.Header.Set(SignatureKeyHeader, keyID)
+	}
+	req.Header.Set(SignatureHeader, "sha256="+RequestSignature(secret, req.Method, req.URL.RequestURI(), timestamp, req.Header.Get(SignatureNonceHeader), body))
+	return nil
+}
+
+// readBody reads the whole body and puts back an equivalent reader, so that it can be read again.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, err
+}
+
+// maxBodyBytes returns the largest body read to verify a signature
+func (f *SignatureFirewall) maxBodyBytes() int64 {
+	if f.MaxBodyBytes == 0 {
+		return DefaultSignatureMaxBodyBytes
+	}
+	return f.MaxBodyBytes
+}
+
+// SignatureFirewall authenticates service to service requests signed with a shared secret, see SignRequest
+// Requests without a signature get the non-blocking ErrNoCredentials, so that other firewalls can be tried
+// Invalid, stale and replayed requests are rejected with ErrUnauthorized
+type SignatureFirewall struct {
+	Keys SigningKeyProvider
+	// Nonces, when set, makes the nonce mandatory and rejects the ones already used
+	Nonces NonceStore
+	// Tolerance is the accepted distance between the timestamp and the server clock
+	Tolerance time.Duration
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+	// MaxBodyBytes is the largest body read to verify a signature, DefaultSignatureMaxBodyBytes when 0 and unlimited when negative
+	// Larger bodies are rejected with 413
+	MaxBodyBytes int64
+}
+
+// NewSignatureFirewall creates a signature firewall accepting timestamps within five minutes.
+func NewSignatureFirewall(keys SigningKeyProvider, nonces NonceStore) *SignatureFirewall {
+	return &SignatureFirewall{Keys: keys, Nonces: nonces, Tolerance: 5 * time.Minute}
+}
+
+// GetUser verifies the signature of the request and returns the user of the key that signed it.
+// The verified user is kept on the context, firewalls run several times per request and the nonce may only be used once.
+func (f *SignatureFirewall) GetUser(c *gin.Context) (User, error) {
+	if user, ok := c.Get(signatureContextKey); ok {
+		return user.(User), nil
+	}
+	signature := strings.TrimPrefix(c.GetHeader(SignatureHeader), "sha256=")
+	if signature == "" {
+		return nil, errors.ErrNoCredentials
+	}
+	if f.Keys == nil {
+		return nil, errors.ErrNotImplemented
+	}
+
+	now := time.Now()
+	if f.Now != nil {
+		now = f.Now()
+	}
+	timestamp := c.GetHeader(SignatureTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > f.Tolerance || skew < -f.Tolerance {
+		return nil, errors.ErrUnauthorized
+	}
+	nonce := c.GetHeader(SignatureNonceHeader)
+	if f.Nonces != nil && nonce == "" {
+		return nil, errors.ErrUnauthorized
+	}
+
+	keyID := c.GetHeader(SignatureKeyHeader)
+	keys, err := f.Keys.SigningKeys(c, keyID)
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	if limit := f.maxBodyBytes(); limit > 0 && c.Request.Body != nil && c.Request.Body != http.NoBody {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	}
+	body, err := readBody(&c.Request.Body)
+	if err != nil {
+		if _, tooLarge := err.(*http.MaxBytesError); tooLarge {
+			return nil, errors.ErrTooLarge
+		}
+		return nil, errors.ErrBadRequest
+	}
+	var signer *SigningKey
+	for i, key := range keys {
+		if !key.NotAfter.IsZero() && !now.Before(key.NotAfter) {
+			continue
+		}
+		expected := RequestSignature(key.Secret, c.Request.Method, c.Request.URL.RequestURI(), timestamp, nonce, body)
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			signer = &keys[i]
+			break
+		}
+	}
+	if signer == nil || signer.User == nil {
+		return nil, errors.ErrUnauthorized
+	}
+
+	if f.Nonces != nil {
+		//a nonce only has to be remembered while its timestamp is acceptable
+		//it is scoped by the key that verified it, the key header itself is not signed
+		unused, err := f.Nonces.Use(signer.ID+":"+nonce, 2*f.Tolerance)
+		if err != nil {
+			return nil, errors.ErrInternal
+		}
+		if !unused {
+			return nil, errors.ErrUnauthorized
+		}
+	}
+	c.Set(signatureContextKey, signer.User)
+	return signer.User, nil
+}
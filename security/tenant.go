@@ -0,0 +1,106 @@
+package security
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// TenantResolver finds the tenant a request acts for
+// user is the user returned by the firewalls, it may be nil
+type TenantResolver interface {
+	ResolveTenant(c *gin.Context, user User) (string, error)
+}
+
+// TenantUser is an interface for users belonging to a tenant
+type TenantUser interface {
+	GetTenantId() string
+}
+
+// TenantEntity is an interface for entities isolated per tenant
+// the router stamps the tenant of the request on every written entity
+type TenantEntity interface {
+	GetTenantId() string
+	SetTenantId(string) entity.Entity
+}
+
+// HasTenant checks if the object implements the TenantEntity interface.
+func HasTenant(obj any) (TenantEntity, bool) {
+	te, ok := obj.(TenantEntity)
+	return te, ok
+}
+
+// UserTenantResolver resolves the tenant of the authenticated user, which must implement TenantUser
+type UserTenantResolver struct{}
+
+// ResolveTenant returns the tenant of the user, anonymous users have none.
+func (UserTenantResolver) ResolveTenant(c *gin.Context, user User) (string, error) {
+	tenantUser, ok := user.(TenantUser)
+	if !ok || tenantUser.GetTenantId() == "" {
+		return "", errors.ErrUnauthorized
+	}
+	return tenantUser.GetTenantId(), nil
+}
+
+// DefaultTenantHeader is the header read by HeaderTenantResolver when none is set
+const DefaultTenantHeader = "X-Tenant-ID"
+
+// HeaderTenantResolver resolves the tenant from a request header
+// The header is not authenticated, it should only be trusted behind a gateway setting it
+type HeaderTenantResolver struct {
+	Header string
+}
+
+// ResolveTenant returns the value of the header.
+func (h HeaderTenantResolver) ResolveTenant(c *gin.Context, user User) (string, error) {
+	header := h.Header
+	if header == "" {
+		header = DefaultTenantHeader
+	}
+	tenant := c.GetHeader(header)
+	if tenant == "" {
+		return "", errors.ErrBadRequest
+	}
+	return tenant, nil
+}
+
+// SubdomainTenantResolver resolves the tenant from the subdomain of Domain, acme.example.com gives acme for example.com
+type SubdomainTenantResolver struct {
+	Domain string
+}
+
+// ResolveTenant returns the label preceding the domain in the request host.
+func (s SubdomainTenantResolver) ResolveTenant(c *gin.Context, user User) (string, error) {
+	host := c.Request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	suffix := "." + strings.TrimPrefix(strings.ToLower(s.Domain), ".")
+	host = strings.ToLower(host)
+	if !strings.HasSuffix(host, suffix) {
+		return "", errors.ErrBadRequest
+	}
+	tenant := strings.TrimSuffix(host, suffix)
+	if tenant == "" || strings.Contains(tenant, ".") {
+		return "", errors.ErrBadRequest
+	}
+	return tenant, nil
+}
+
+// TenantResolverChain tries every resolver in order and returns the first tenant found
+type TenantResolverChain []TenantResolver
+
+// ResolveTenant returns the first tenant resolved, or the error of the last resolver.
+func (chain TenantResolverChain) ResolveTenant(c *gin.Context, user User) (string, error) {
+	err := error(errors.ErrBadRequest)
+	for _, resolver := range chain {
+		var tenant string
+		if tenant, err = resolver.ResolveTenant(c, user); err == nil {
+			return tenant, nil
+		}
+	}
+	return "", err
+}
@@ -8,5 +8,24 @@ import (
 // its compatible with entity.Entity
 type User interface {
 	SetId(any) entity.Entity
-	GetId() entity.ID
+	GetIdentifier() entity.Identifier
+}
+
+// RoleHolder is an interface for users having roles
+type RoleHolder interface {
+	GetRoles() []string
+}
+
+// HasRole checks if the user holds the role, users not implementing RoleHolder hold none.
+func HasRole(user User, role string) bool {
+	holder, ok := user.(RoleHolder)
+	if !ok {
+		return false
+	}
+	for _, r := range holder.GetRoles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
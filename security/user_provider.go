@@ -0,0 +1,71 @@
+package security
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+// UserProvider loads the user a credential belongs to
+// identifier is what the client presented, such as a username or an email
+type UserProvider interface {
+	LoadUser(c *gin.Context, identifier string) (User, error)
+}
+
+// UserProviderFunc adapts a function to the UserProvider interface
+type UserProviderFunc func(c *gin.Context, identifier string) (User, error)
+
+// LoadUser calls the function.
+func (f UserProviderFunc) LoadUser(c *gin.Context, identifier string) (User, error) {
+	return f(c, identifier)
+}
+
+// PasswordUser is an interface for users authenticating with a password
+// GetPasswordHash returns a bcrypt or argon2id hash, see VerifyPassword
+type PasswordUser interface {
+	GetPasswordHash() string
+}
+
+// ORMUserProvider loads users stored by a RestMan ORM, T must implement User
+type ORMUserProvider[T entity.Entity] struct {
+	ORM *orm.ORM[T]
+	// Field is the database field matched against the identifier, such as "username" or "email"
+	Field string
+}
+
+// NewORMUserProvider creates a user provider looking users up by field.
+func NewORMUserProvider[T entity.Entity](o *orm.ORM[T], field string) *ORMUserProvider[T] {
+	return &ORMUserProvider[T]{ORM: o, Field: field}
+}
+
+// LoadUser returns the user whose field equals identifier.
+func (p *ORMUserProvider[T]) LoadUser(c *gin.Context, identifier string) (User, error) {
+	item, err := findOneBy(p.ORM, p.Field, identifier)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := any(item).(User)
+	if !ok {
+		return nil, errors.ErrNotImplemented
+	}
+	return user, nil
+}
+
+// findOneBy returns the single entity whose field equals value, ErrUnauthorized when there is none.
+func findOneBy[T entity.Entity](o *orm.ORM[T], field string, value any) (T, error) {
+	var zero T
+	scoped, err := o.Scoped(orm.Where(field, value))
+	if err != nil {
+		return zero, errors.ErrNotImplemented
+	}
+	items, err := scoped.GetPaginatedList(2, 0, nil)
+	if err != nil {
+		return zero, errors.ErrDatabaseIssue
+	}
+	//an ambiguous credential never authenticates
+	if len(items) != 1 {
+		return zero, errors.ErrUnauthorized
+	}
+	return items[0], nil
+}
@@ -0,0 +1,100 @@
+package security
+
+import "strings"
+
+// Vote is the decision of a Voter on an attribute
+type Vote int8
+
+const (
+	// AccessDenied denies the attribute
+	AccessDenied Vote = -1
+	// AccessAbstain is returned by voters not supporting the attribute or the subject
+	AccessAbstain Vote = 0
+	// AccessGranted grants the attribute
+	AccessGranted Vote = 1
+)
+
+const (
+	// IsAuthenticated is granted to every authenticated user
+	IsAuthenticated = "IS_AUTHENTICATED"
+	// PublicAccess is granted to everyone, anonymous users included
+	PublicAccess = "PUBLIC_ACCESS"
+	// DefaultRolePrefix is the prefix of the attributes the RoleVoter votes on
+	DefaultRolePrefix = "ROLE_"
+)
+
+// Voter decides whether a user is granted an attribute on a subject
+// subject is the entity the route operates on, it is nil for collection routes
+// voters return AccessAbstain for the attributes and subjects they do not support
+type Voter interface {
+	Vote(user User, attribute string, subject any) Vote
+}
+
+// VoterFunc adapts a function to the Voter interface
+type VoterFunc func(user User, attribute string, subject any) Vote
+
+// Vote calls the function.
+func (f VoterFunc) Vote(user User, attribute string, subject any) Vote {
+	return f(user, attribute, subject)
+}
+
+// AttributeHolder is an interface for users having attributes, permissions for example
+type AttributeHolder interface {
+	GetAttributes() []string
+}
+
+// RoleVoter votes on the attributes starting with Prefix (ROLE_ by default)
+// users implementing RoleHolder are granted the roles they hold
+type RoleVoter struct {
+	Prefix string
+}
+
+// Vote grants the role if the user holds it.
+func (v RoleVoter) Vote(user User, attribute string, subject any) Vote {
+	prefix := v.Prefix
+	if prefix == "" {
+		prefix = DefaultRolePrefix
+	}
+	if !strings.HasPrefix(attribute, prefix) {
+		return AccessAbstain
+	}
+	if HasRole(user, attribute) {
+		return AccessGranted
+	}
+	return AccessDenied
+}
+
+// AttributeVoter grants the attributes held by users implementing AttributeHolder
+// it abstains otherwise, so that other voters can decide
+type AttributeVoter struct{}
+
+// Vote grants the attribute if the user holds it.
+func (AttributeVoter) Vote(user User, attribute string, subject any) Vote {
+	holder, ok := user.(AttributeHolder)
+	if !ok {
+		return AccessAbstain
+	}
+	for _, a := range holder.GetAttributes() {
+		if a == attribute {
+			return AccessGranted
+		}
+	}
+	return AccessAbstain
+}
+
+// AuthenticatedVoter votes on IsAuthenticated and PublicAccess
+type AuthenticatedVoter struct{}
+
+// Vote grants PublicAccess to everyone and IsAuthenticated to authenticated users.
+func (AuthenticatedVoter) Vote(user User, attribute string, subject any) Vote {
+	switch attribute {
+	case PublicAccess:
+		return AccessGranted
+	case IsAuthenticated:
+		if user != nil {
+			return AccessGranted
+		}
+		return AccessDenied
+	}
+	return AccessAbstain
+}
@@ -1,10 +1,17 @@
 package filter
 
 import (
+	"encoding"
+	"encoding/json"
 	"reflect"
 	"strings"
 )
 
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
 // IsFieldIncluded checks if a struct field should be included based on its groups tags and the provided groups.
 func IsFieldIncluded(field reflect.StructField, groups []string) bool {
 	if len(groups) == 0 {
@@ -37,6 +44,13 @@ func IsStruct(t reflect.Type) bool {
 	return DereferenceTypeIfPointer(t).Kind() == reflect.Struct
 }
 
+// marshalsItself checks if a type has its own encoding, such as time.Time, so that its fields must not be filtered.
+func marshalsItself(t reflect.Type) bool {
+	t = DereferenceTypeIfPointer(t)
+	ptr := reflect.PointerTo(t)
+	return t.Implements(jsonMarshalerType) || ptr.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) || ptr.Implements(textMarshalerType)
+}
+
 // IsList checks if a type is a slice or array, dereferencing pointers if necessary.
 func IsList(t reflect.Type) bool {
 	return DereferenceTypeIfPointer(t).Kind() == reflect.Slice || DereferenceTypeIfPointer(t).Kind() == reflect.Array
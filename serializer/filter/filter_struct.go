@@ -37,7 +37,7 @@ func filterByGroupsStruct[T any](obj T, groups ...string) T {
 			if isFieldExported(field) && IsFieldIncluded(field, groups) && !isAnonymous(field) {
 				fieldValue := value.Field(i)
 
-				if IsStruct(field.Type) {
+				if IsStruct(field.Type) && !marshalsItself(field.Type) {
 					filteredElem := FilterByGroups(fieldValue.Interface(), groups...)
 					newField := reflect.StructField{
 						Name: field.Name,
@@ -0,0 +1,130 @@
+package audit_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/philiphil/restman/audit"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+type Account struct {
+	entity.BaseEntity
+	Email    string `json:"email"`
+	Password string `json:"-" sensitive:"true"`
+	APIKey   string `json:"-"`
+	Balance  int
+	Tags     []string `json:"tags,omitempty"`
+	secret   string
+}
+
+func TestDiff(t *testing.T) {
+	before := Account{BaseEntity: entity.BaseEntity{Id: 1, Name: "a"}, Email: "a@b.c", Password: "old", Balance: 10, secret: "x"}
+	after := before
+	after.Email = "z@b.c"
+	after.Password = "new"
+	after.APIKey = "key"
+	after.Tags = []string{"vip"}
+	after.secret = "y"
+
+	changes := Diff("1", &before, after)
+	fields := map[string]Change{}
+	for _, change := range changes {
+		fields[change.Field] = change
+	}
+	if len(changes) != 4 {
+		t.Fatalf("expected email, password, api key and tags to change, got %+v", changes)
+	}
+	if fields["email"].Before != "a@b.c" || fields["email"].After != "z@b.c" || fields["email"].ID != "1" {
+		t.Fatalf("unexpected email change %+v", fields["email"])
+	}
+	if fields["Password"].Before != Redacted || fields["Password"].After != Redacted {
+		t.Fatalf("sensitive fields should be redacted, got %+v", fields["Password"])
+	}
+	if fields["APIKey"].Before != Redacted || fields["APIKey"].After != Redacted {
+		t.Fatalf("fields hidden from the serializer should be redacted, got %+v", fields["APIKey"])
+	}
+
+	created := Diff("1", nil, &after)
+	for _, change := range created {
+		if change.Before != nil {
+			t.Fatalf("created entities have no previous values, got %+v", change)
+		}
+		if change.Field == "Balance" && change.After != 10 {
+			t.Fatalf("unexpected balance %+v", change)
+		}
+	}
+	//id, name, email, password, api key, balance and tags are set
+	if len(created) != 7 {
+		t.Fatalf("only the set fields of created entities should be listed, got %+v", created)
+	}
+	if deleted := Diff("1", &before, nil); len(deleted) != 5 || deleted[0].After != nil {
+		t.Fatalf("deleted entities should list their set fields, got %+v", deleted)
+	}
+	if Diff("1", nil, nil) != nil || Diff("1", "text", nil) != nil {
+		t.Fatal("only structs can be compared")
+	}
+}
+
+func TestSinks(t *testing.T) {
+	record := Record{
+		Time:      time.Now().UTC().Truncate(time.Second),
+		RequestID: "req",
+		User:      "7",
+		Operation: Update,
+		Resource:  "Account",
+		IDs:       []string{"1"},
+		Changes:   []Change{{ID: "1", Field: "email", Before: "a@b.c", After: "z@b.c"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	file, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs bytes.Buffer
+	var called []Record
+	sink := MultiSink{
+		file,
+		NewSlogSink(slog.New(slog.NewJSONHandler(&logs, nil))),
+		SinkFunc(func(record Record) error {
+			called = append(called, record)
+			return nil
+		}),
+	}
+	if err := sink.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	opened, _ := os.Open(path)
+	defer opened.Close()
+	scanner := bufio.NewScanner(opened)
+	lines := 0
+	for scanner.Scan() {
+		var read Record
+		if err := json.Unmarshal(scanner.Bytes(), &read); err != nil || read.User != "7" || read.Changes[0].After != "z@b.c" || !read.Time.Equal(record.Time) {
+			t.Fatalf("unexpected line %s %v", scanner.Text(), err)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Fatalf("expected one line per record, got %d", lines)
+	}
+	if !strings.Contains(logs.String(), `"msg":"audit"`) || !strings.Contains(logs.String(), `"request_id":"req"`) {
+		t.Fatalf("unexpected logs %s", logs.String())
+	}
+	if len(called) != 2 {
+		t.Fatal("every sink should receive the records")
+	}
+}
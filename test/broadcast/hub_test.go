@@ -0,0 +1,70 @@
+package broadcast_test
+
+import (
+	"testing"
+
+	"github.com/philiphil/restman/audit"
+	. "github.com/philiphil/restman/broadcast"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+type Book struct {
+	entity.BaseEntity
+}
+
+func (b Book) SetId(id any) entity.Entity {
+	b.Id = entity.CastId(id)
+	return b
+}
+
+func book(id int) *Book {
+	return &Book{BaseEntity: entity.BaseEntity{Id: entity.ID(id)}}
+}
+
+func TestHub(t *testing.T) {
+	hub := NewHub[Book](3)
+	hub.SubscriberBuffer = 2
+	live, replay, complete := hub.Subscribe(0)
+	if replay != nil || !complete {
+		t.Fatal("new subscribers have nothing to replay")
+	}
+
+	original := book(1)
+	hub.Publish(audit.Create, "acme", original, book(2))
+	original.Name = "changed"
+	first := <-live.C()
+	if first.ID != 1 || first.EntityID != "1" || first.Tenant != "acme" || first.Operation != audit.Create || first.Object.Name != "" {
+		t.Fatalf("messages should carry a copy of the entity, got %+v", first)
+	}
+	if (<-live.C()).ID != 2 {
+		t.Fatal("messages should be numbered in order")
+	}
+
+	hub.Publish(audit.Update, "", book(1), book(2), book(3))
+	if _, ok := <-live.C(); !ok {
+		t.Fatal("the subscriber should have received the first update")
+	}
+	<-live.C()
+	if _, ok := <-live.C(); ok {
+		t.Fatal("a subscriber lagging behind its buffer should be disconnected")
+	}
+
+	_, replay, complete = hub.Subscribe(3)
+	if len(replay) != 2 || replay[0].ID != 4 || !complete {
+		t.Fatalf("messages after the last id should be replayed, got %+v", replay)
+	}
+	_, replay, complete = hub.Subscribe(1)
+	if len(replay) != 3 || complete {
+		t.Fatalf("messages already out of the buffer should be reported, got %+v %v", replay, complete)
+	}
+	_, replay, complete = hub.Subscribe(42)
+	if len(replay) != 0 || complete {
+		t.Fatalf("an unknown last id should be reported, got %+v %v", replay, complete)
+	}
+	closing, _, _ := hub.Subscribe(0)
+	closing.Close()
+	closing.Close()
+	if _, ok := <-closing.C(); ok {
+		t.Fatal("closed subscriptions should not receive messages")
+	}
+}
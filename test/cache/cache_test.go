@@ -19,6 +19,10 @@ func (m MockEntity) GetId() entity.ID {
 	return m.ID
 }
 
+func (m MockEntity) GetIdentifier() entity.Identifier {
+	return m.GetId()
+}
+
 func (m MockEntity) SetId(test any) entity.Entity {
 	panic(test)
 }
@@ -0,0 +1,67 @@
+package cache_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	. "github.com/philiphil/restman/cache"
+)
+
+func TestBroadcastResponseCache(t *testing.T) {
+	bus := NewInProcessInvalidationBus()
+	nodeA, err := NewBroadcastResponseCache(NewMemoryResponseCache(0), bus)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nodeB, err := NewBroadcastResponseCache(NewMemoryResponseCache(0), bus)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	nodeA.Set("item", []byte("a"), ItemTag("Book", "1"))
+	nodeB.Set("item", []byte("b"), ItemTag("Book", "1"))
+	nodeB.Set("other", []byte("b"), ItemTag("Book", "2"))
+
+	if err := nodeA.Invalidate(ItemTag("Book", "1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := nodeA.Get("item"); ok {
+		t.Fatal("node A should have evicted locally")
+	}
+	if _, ok := nodeB.Get("item"); ok {
+		t.Fatal("node B should have applied the broadcast eviction")
+	}
+	if _, ok := nodeB.Get("other"); !ok {
+		t.Fatal("node B should keep unrelated entries")
+	}
+}
+
+func TestInProcessInvalidationBus_Close(t *testing.T) {
+	bus := NewInProcessInvalidationBus()
+	received := 0
+	bus.Subscribe(func(Invalidation) { received++ })
+	bus.Publish(Invalidation{Tags: []string{"a"}})
+	bus.Close()
+	bus.Publish(Invalidation{Tags: []string{"a"}})
+	if received != 1 {
+		t.Fatalf("expected 1 invalidation, got %d", received)
+	}
+}
+
+func TestRedisInvalidationBus_Publish(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	bus := NewRedisInvalidationBus("localhost:6379", "", 0, "restman:invalidation")
+	bus.Client = client
+
+	invalidation := Invalidation{Origin: "node", Tags: []string{"Book:1"}}
+	data, _ := json.Marshal(invalidation)
+	mock.ExpectPublish("restman:invalidation", data).SetVal(1)
+
+	if err := bus.Publish(invalidation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations were not met: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	. "github.com/philiphil/restman/cache"
+	"github.com/philiphil/restman/format"
+)
+
+func TestResponseCacheKey(t *testing.T) {
+	a := ResponseCacheKey("Book", "/api/book/1?", format.JSON, []string{"read", "admin"}, "public")
+	b := ResponseCacheKey("Book", "/api/book/1?", format.JSON, []string{"admin", "read"}, "public")
+	if a != b {
+		t.Fatalf("groups order should not matter: %s != %s", a, b)
+	}
+	if a == ResponseCacheKey("Book", "/api/book/1?", format.XML, []string{"read", "admin"}, "public") {
+		t.Fatal("format should be part of the key")
+	}
+	if a == ResponseCacheKey("Book", "/api/book/1?", format.JSON, []string{"read", "admin"}, "user:1") {
+		t.Fatal("scope should be part of the key")
+	}
+}
+
+func TestMemoryResponseCache(t *testing.T) {
+	c := NewMemoryResponseCache(0)
+	if _, ok := c.Get("missing"); ok {
+		t.Fatal("expected a miss")
+	}
+
+	c.Set("item", []byte("1"), ItemTag("Book", "1"))
+	c.Set("list", []byte("[1]"), CollectionTag("Book"))
+	c.Set("other", []byte("2"), ItemTag("Book", "2"))
+
+	if data, ok := c.Get("item"); !ok || string(data) != "1" {
+		t.Fatalf("expected a hit, got %q", data)
+	}
+
+	c.Invalidate(ItemTag("Book", "1"), CollectionTag("Book"))
+	if _, ok := c.Get("item"); ok {
+		t.Fatal("item should have been invalidated")
+	}
+	if _, ok := c.Get("list"); ok {
+		t.Fatal("list should have been invalidated")
+	}
+	if _, ok := c.Get("other"); !ok {
+		t.Fatal("other should still be cached")
+	}
+}
+
+func TestMemoryResponseCache_Lifetime(t *testing.T) {
+	c := NewMemoryResponseCache(1)
+	c.Set("item", []byte("1"))
+	if _, ok := c.Get("item"); !ok {
+		t.Fatal("expected a hit")
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if _, ok := c.Get("item"); ok {
+		t.Fatal("entry should have expired")
+	}
+}
+
+func TestRedisResponseCache(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	c := NewRedisResponseCache("localhost:6379", "", 0, 60)
+	c.Client = client
+
+	mock.ExpectSet("restman:response:key", []byte("data"), 60*time.Second).SetVal("OK")
+	mock.ExpectSAdd("restman:response:tag:Book:1", "restman:response:key").SetVal(1)
+	if err := c.Set("key", []byte("data"), "Book:1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mock.ExpectGet("restman:response:key").SetVal("data")
+	if data, ok := c.Get("key"); !ok || string(data) != "data" {
+		t.Fatalf("expected a hit, got %q", data)
+	}
+
+	mock.ExpectSMembers("restman:response:tag:Book:1").SetVal([]string{"restman:response:key"})
+	mock.ExpectDel("restman:response:key", "restman:response:tag:Book:1").SetVal(2)
+	if err := c.Invalidate("Book:1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations were not met: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package event_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/philiphil/restman/audit"
+	. "github.com/philiphil/restman/event"
+	"github.com/philiphil/restman/event/gormoutbox"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestDispatcher(t *testing.T) {
+	dispatcher := NewDispatcher()
+	var all, books, created []string
+	dispatcher.Subscribe(func(e Event) error {
+		all = append(all, e.Name())
+		return errors.New("first")
+	})
+	dispatcher.Subscribe(func(e Event) error {
+		books = append(books, e.Name())
+		return nil
+	}, "Book")
+	dispatcher.Subscribe(func(e Event) error {
+		created = append(created, e.Name())
+		return errors.New("second")
+	}, "Book.create", "Author.create")
+
+	err := dispatcher.Publish(Event{Resource: "Book", Operation: audit.Create})
+	if err == nil || err.Error() != "first\nsecond" {
+		t.Fatalf("the errors of every handler should be returned, got %v", err)
+	}
+	_ = dispatcher.Publish(Event{Resource: "Book", Operation: audit.Update})
+	_ = dispatcher.Publish(Event{Resource: "Author", Operation: audit.Delete})
+	if len(all) != 3 || len(books) != 2 || len(created) != 1 {
+		t.Fatalf("unexpected deliveries %v %v %v", all, books, created)
+	}
+}
+
+func TestChannelBus(t *testing.T) {
+	bus := NewChannelBus(1)
+	if err := bus.Publish(Event{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish(Event{ID: "2"}); err != ErrBusFull {
+		t.Fatalf("a full bus should not block, got %v", err)
+	}
+	bus.Close()
+	if err := bus.Publish(Event{ID: "3"}); err != ErrBusClosed {
+		t.Fatalf("a closed bus should refuse events, got %v", err)
+	}
+	var received []string
+	for e := range bus.Events() {
+		received = append(received, e.ID)
+	}
+	if len(received) != 1 || received[0] != "1" {
+		t.Fatalf("pending events should still be received, got %v", received)
+	}
+}
+
+func TestOutboxRelay(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:outbox?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.AutoMigrate(&gormoutbox.Message{})
+	outbox := gormoutbox.NewOutbox(db)
+	for _, id := range []string{"1", "2", "3"} {
+		if err := outbox.Publish(Event{ID: id, Resource: "Book", Operation: audit.Create, IDs: []string{id}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var relayed []string
+	failing := true
+	relay := gormoutbox.NewRelay(outbox, BusFunc(func(e Event) error {
+		if e.ID == "2" && failing {
+			return errors.New("unavailable")
+		}
+		relayed = append(relayed, e.ID)
+		return nil
+	}))
+	relay.BatchSize = 2
+	relay.MaxAttempts = 2
+
+	if published, err := relay.RelayPending(t.Context()); published != 2 || err == nil {
+		t.Fatalf("the relay should skip the failing message, got %d %v", published, err)
+	}
+	pending, _ := outbox.Pending(t.Context(), 10)
+	if len(pending) != 1 || pending[0].EventID != "2" || pending[0].Attempts != 1 || pending[0].LastError != "unavailable" {
+		t.Fatalf("the failure should be recorded, got %+v", pending)
+	}
+
+	if published, err := relay.RelayPending(t.Context()); published != 0 || err == nil {
+		t.Fatalf("the failing message should fail again, got %d %v", published, err)
+	}
+	if pending, _ = outbox.Pending(t.Context(), 10); len(pending) != 0 {
+		t.Fatalf("the failing message should be parked, got %+v", pending)
+	}
+	parked, _ := outbox.Parked(t.Context(), 10)
+	if len(parked) != 1 || parked[0].EventID != "2" || parked[0].Attempts != 2 {
+		t.Fatalf("unexpected parked messages %+v", parked)
+	}
+
+	failing = false
+	db.Model(&gormoutbox.Message{}).Where("event_id = ?", "2").Update("parked_at", nil)
+	if published, err := relay.RelayPending(t.Context()); published != 1 || err != nil {
+		t.Fatalf("the unparked message should be relayed, got %d %v", published, err)
+	}
+	if len(relayed) != 3 || relayed[0] != "1" || relayed[1] != "3" || relayed[2] != "2" {
+		t.Fatalf("unexpected relayed messages %v", relayed)
+	}
+	if pending, _ = outbox.Pending(t.Context(), 10); len(pending) != 0 {
+		t.Fatalf("every message should be published, got %+v", pending)
+	}
+}
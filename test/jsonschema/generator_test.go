@@ -0,0 +1,114 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/philiphil/restman/jsonschema"
+)
+
+type Author struct {
+	Name string `json:"name" groups:"read"`
+}
+
+type Book struct {
+	Title     string    `json:"title" groups:"read,write"`
+	Secret    string    `json:"secret" groups:"admin"`
+	Pages     uint      `json:"pages,omitempty" groups:"read"`
+	Published time.Time `json:"published" groups:"read"`
+	Author    *Author   `json:"author" groups:"read"`
+	Related   []Book    `json:"related,omitempty" groups:"read"`
+	Cover     []byte    `json:"cover" groups:"read"`
+	Ignored   string    `json:"-"`
+}
+
+func TestGenerator(t *testing.T) {
+	g := jsonschema.NewGenerator("#/$defs/")
+	ref := g.Schema(reflect.TypeFor[Book](), jsonschema.Output, "read")
+	if ref.Ref != "#/$defs/Book-read" {
+		t.Fatalf("unexpected reference %s", ref.Ref)
+	}
+	book := g.Definitions["Book-read"]
+	if _, ok := book.Properties["secret"]; ok {
+		t.Error("expected the admin field to be excluded")
+	}
+	if _, ok := book.Properties["Ignored"]; ok {
+		t.Error("expected the ignored field to be excluded")
+	}
+	if book.Properties["published"].Format != "date-time" || book.Properties["cover"].ContentEncoding != "base64" {
+		t.Errorf("unexpected formats %+v %+v", book.Properties["published"], book.Properties["cover"])
+	}
+	if *book.Properties["pages"].Minimum != 0 {
+		t.Error("expected unsigned integers to be positive")
+	}
+	if author := book.Properties["author"]; len(author.OneOf) != 2 || author.OneOf[0].Ref != "#/$defs/Author-read" {
+		t.Errorf("expected a nullable author reference, got %+v", author)
+	}
+	if book.Properties["related"].Items.Ref != "#/$defs/Book-read" {
+		t.Error("expected recursive types to reference their definition")
+	}
+	if !slices.Contains(book.Required, "title") || slices.Contains(book.Required, "pages") {
+		t.Errorf("unexpected required fields %v", book.Required)
+	}
+
+	g.Schema(reflect.TypeFor[Book](), jsonschema.Input, "write")
+	input := g.Definitions["BookInput-write"]
+	if len(input.Properties) != 1 || input.Required != nil {
+		t.Errorf("unexpected input schema %+v", input)
+	}
+
+	data, _ := json.Marshal(jsonschema.Nullable(&jsonschema.Schema{Type: jsonschema.Types{"string"}}))
+	if string(data) != `{"type":["string","null"]}` {
+		t.Errorf("unexpected nullable encoding %s", data)
+	}
+}
+
+type Signup struct {
+	Email    string   `json:"email" validate:"required,email"`
+	Password string   `json:"password" binding:"required,min=8,max=64"`
+	Age      *int     `json:"age,omitempty" validate:"omitempty,gte=18,lt=130"`
+	Plan     string   `json:"plan" validate:"oneof=free 'pro plus'"`
+	Tags     []string `json:"tags" validate:"max=5,unique,dive,alphanum,max=10"`
+	Referrer string   `json:"referrer,omitempty" validate:"startswith=ref-,endswith=!" binding:"alphanum"`
+	Seats    int      `json:"seats,string" validate:"required,min=1,max=9"`
+}
+
+func TestGenerateConstraints(t *testing.T) {
+	schema := jsonschema.Generate(reflect.TypeFor[Signup](), jsonschema.Input)
+	if schema.Schema != jsonschema.Draft || schema.Ref != "#/$defs/SignupInput" {
+		t.Fatalf("unexpected root %+v", schema)
+	}
+	signup := schema.Defs["SignupInput"]
+	if signup.Required != nil {
+		t.Errorf("input fields should not be required, got %v", signup.Required)
+	}
+	output := jsonschema.Generate(reflect.TypeFor[Signup](), jsonschema.Output).Defs["Signup"]
+	if slices.Contains(output.Required, "age") || !slices.Contains(output.Required, "seats") {
+		t.Errorf("unexpected required output fields %v", output.Required)
+	}
+	if signup.Properties["email"].Format != "email" {
+		t.Error("expected the email format")
+	}
+	if password := signup.Properties["password"]; *password.MinLength != 8 || *password.MaxLength != 64 {
+		t.Errorf("unexpected password bounds %+v", password)
+	}
+	if age := signup.Properties["age"]; *age.Minimum != 18 || *age.ExclusiveMaximum != 130 || !slices.Equal(age.Type, jsonschema.Types{"integer", "null"}) {
+		t.Errorf("unexpected age schema %+v", age)
+	}
+	if plan := signup.Properties["plan"]; !slices.Equal(plan.Enum, []any{"free", "pro plus"}) {
+		t.Errorf("unexpected plan enum %v", plan.Enum)
+	}
+	tags := signup.Properties["tags"]
+	if *tags.MaxItems != 5 || !tags.UniqueItems || tags.Items.Pattern == "" || *tags.Items.MaxLength != 10 {
+		t.Errorf("unexpected tags schema %+v %+v", tags, tags.Items)
+	}
+	if referrer := signup.Properties["referrer"]; referrer.Pattern != `^(?=[\s\S]*?(?:^ref-))(?=[\s\S]*?(?:!$))(?=[\s\S]*?(?:^[a-zA-Z0-9]+$))` {
+		t.Errorf("every pattern should be kept, got %s", referrer.Pattern)
+	}
+	if seats := signup.Properties["seats"]; !slices.Equal(seats.Type, jsonschema.Types{"string"}) || seats.Minimum != nil || seats.Maximum != nil {
+		t.Errorf("the bounds of a string encoded number should not apply to the string, got %+v", seats)
+	}
+}
@@ -0,0 +1,31 @@
+package orm_test
+
+import (
+	"testing"
+
+	. "github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+)
+
+type conditioned struct {
+	entity.BaseEntity
+	TenantID string `json:"tenant"`
+	Private  bool   `bson:"hidden"`
+}
+
+func TestCondition_Matches(t *testing.T) {
+	object := conditioned{BaseEntity: entity.BaseEntity{Id: 7, Name: "a"}, TenantID: "acme", Private: true}
+	for condition, expected := range map[*Condition]bool{
+		{Field: "TenantID", Operator: OperatorEqual, Value: "acme"}:       true,
+		{Field: "tenant_id", Operator: OperatorEqual, Value: "other"}:     false,
+		{Field: "tenant", Operator: OperatorNotEqual, Value: "other"}:     true,
+		{Field: "hidden", Operator: OperatorEqual, Value: true}:           true,
+		{Field: "Id", Operator: OperatorIn, Value: []int{1, 7}}:           true,
+		{Field: "name", Operator: OperatorIn, Value: []string{"b"}}:       false,
+		{Field: "unknown", Operator: OperatorNotEqual, Value: "anything"}: false,
+	} {
+		if condition.Matches(&object) != expected {
+			t.Fatalf("unexpected match of %+v", *condition)
+		}
+	}
+}
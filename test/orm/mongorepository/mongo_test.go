@@ -39,6 +39,10 @@ func (p Product) GetId() entity.ID {
 	return entity.ID(p.ID)
 }
 
+func (p Product) GetIdentifier() entity.Identifier {
+	return p.GetId()
+}
+
 type ProductMongo struct {
 	ID          uint   `bson:"_id"`
 	Name        string `bson:"name"`
@@ -143,7 +147,7 @@ func TestMongoRepository_FindByID(t *testing.T) {
 	repository := mongorepository.NewRepository[ProductMongo, Product](collection)
 	ctx := context.Background()
 
-	product, err := repository.FindByID(ctx, 8)
+	product, err := repository.FindByID(ctx, entity.ID(8))
 	if err != nil {
 		t.Error(err)
 	}
@@ -170,7 +174,7 @@ func TestMongoRepository_Update(t *testing.T) {
 	repository := mongorepository.NewRepository[ProductMongo, Product](collection)
 	ctx := context.Background()
 
-	product, err := repository.FindByID(ctx, 8)
+	product, err := repository.FindByID(ctx, entity.ID(8))
 	if err != nil {
 		t.Error(err)
 	}
@@ -180,7 +184,7 @@ func TestMongoRepository_Update(t *testing.T) {
 		t.Error(err)
 	}
 
-	product2, err := repository.FindByID(ctx, 8)
+	product2, err := repository.FindByID(ctx, entity.ID(8))
 	if err != nil {
 		t.Error(err)
 	}
@@ -194,12 +198,12 @@ func TestMongoRepository_DeleteByID(t *testing.T) {
 	repository := mongorepository.NewRepository[ProductMongo, Product](collection)
 	ctx := context.Background()
 
-	err := repository.DeleteByID(ctx, 8)
+	err := repository.DeleteByID(ctx, entity.ID(8))
 	if err != nil {
 		t.Error(err)
 	}
 
-	_, err = repository.FindByID(ctx, 8)
+	_, err = repository.FindByID(ctx, entity.ID(8))
 	if err == nil {
 		t.Error("supposed to be deleted")
 	}
@@ -516,7 +520,7 @@ func TestMongoRepository_FindByIDs(t *testing.T) {
 	repository := mongorepository.NewRepository[ProductMongo, Product](collection)
 	ctx := context.Background()
 
-	ids := []entity.ID{1, 2, 3}
+	ids := []entity.Identifier{entity.ID(1), entity.ID(2), entity.ID(3)}
 	products, err := repository.FindByIDs(ctx, ids)
 	if err != nil {
 		t.Error(err)
@@ -553,3 +557,67 @@ func TestMongoRepository_Ilike(t *testing.T) {
 		t.Errorf("expected at least 2, got %d", len(many))
 	}
 }
+
+func TestMongoRepository_SoftDelete(t *testing.T) {
+	collection := getCollection()
+	collection.Drop(context.Background())
+	repository := mongorepository.NewRepository[ProductMongo, Product](collection).EnableSoftDelete("")
+	ctx := context.Background()
+
+	products := []*Product{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	if err := repository.Create(products); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.Delete(products[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if nb, _ := repository.Count(); nb != 1 {
+		t.Errorf("expected 1 live product, got %d", nb)
+	}
+	if nb, _ := repository.CountDeleted(); nb != 1 {
+		t.Errorf("expected 1 deleted product, got %d", nb)
+	}
+	if _, err := repository.FindByID(ctx, entity.ID(1)); err == nil {
+		t.Error("deleted product should not be found")
+	}
+
+	if err := repository.Restore(products[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.FindByID(ctx, entity.ID(1)); err != nil {
+		t.Errorf("restored product should be found: %v", err)
+	}
+
+	if err := repository.Purge(products); err != nil {
+		t.Fatal(err)
+	}
+	if nb, _ := collection.CountDocuments(ctx, map[string]any{}); nb != 0 {
+		t.Errorf("purged products should be gone, got %d", nb)
+	}
+}
+
+func TestMongoRepository_UpsertSoftDeleted(t *testing.T) {
+	collection := getCollection()
+	collection.Drop(context.Background())
+	repository := mongorepository.NewRepository[ProductMongo, Product](collection).EnableSoftDelete("")
+	ctx := context.Background()
+
+	product := &Product{ID: 1, Name: "a"}
+	if err := repository.Create([]*Product{product}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.Delete([]*Product{product}); err != nil {
+		t.Fatal(err)
+	}
+	product.Name = "b"
+	if err := repository.Upsert(ctx, product); err != nil {
+		t.Fatalf("upserting a soft deleted product should replace it: %v", err)
+	}
+	found, err := repository.FindByID(ctx, entity.ID(1))
+	if err != nil || found.Name != "b" {
+		t.Errorf("expected the upserted product, got %v %v", found, err)
+	}
+	if nb, _ := collection.CountDocuments(ctx, map[string]any{}); nb != 1 {
+		t.Errorf("expected a single document, got %d", nb)
+	}
+}
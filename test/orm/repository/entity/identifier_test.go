@@ -0,0 +1,171 @@
+package entity_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/philiphil/restman/orm/entity"
+)
+
+func TestParseId(t *testing.T) {
+	if id, err := ParseId("42"); err != nil || id != 42 {
+		t.Errorf("ParseId() = %v, %v, want 42", id, err)
+	}
+	for _, invalid := range []string{"", "abc", "-1", "1.5"} {
+		if _, err := ParseId(invalid); err == nil {
+			t.Errorf("ParseId(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestUUID(t *testing.T) {
+	u := NewUUID()
+	if u.IsNull() {
+		t.Fatal("NewUUID() should not be null")
+	}
+	if u[6]>>4 != 4 {
+		t.Errorf("NewUUID() should be a version 4 UUID, got %s", u)
+	}
+	parsed, err := ParseUUID(u.String())
+	if err != nil || parsed != u {
+		t.Errorf("ParseUUID(%s) = %s, %v", u, parsed, err)
+	}
+	parsed, err = ParseUUID(strings.ReplaceAll(strings.ToUpper(u.String()), "-", ""))
+	if err != nil || parsed != u {
+		t.Errorf("ParseUUID() should accept the form without dashes, got %s, %v", parsed, err)
+	}
+	for _, invalid := range []string{"", "42", "0123456789abcdef0123456789abcdefxx", "01234567-89ab-cdef-0123-456789abcdeg"} {
+		if _, err := ParseUUID(invalid); err == nil {
+			t.Errorf("ParseUUID(%q) should fail", invalid)
+		}
+	}
+	if got := (UUID{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}).String(); got != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Errorf("UUID.String() = %s", got)
+	}
+}
+
+func TestULID(t *testing.T) {
+	at := time.UnixMilli(1700000000123)
+	u := NewULIDAt(at)
+	if !u.Time().Equal(at) {
+		t.Errorf("ULID.Time() = %v, want %v", u.Time(), at)
+	}
+	if len(u.String()) != 26 {
+		t.Fatalf("ULID.String() = %s, want 26 characters", u)
+	}
+	parsed, err := ParseULID(strings.ToLower(u.String()))
+	if err != nil || parsed != u {
+		t.Errorf("ParseULID(%s) = %s, %v", u, parsed, err)
+	}
+	later := NewULIDAt(at.Add(time.Millisecond))
+	if later.String() <= u.String() {
+		t.Errorf("ULIDs should sort by time, %s <= %s", later, u)
+	}
+	if got := (ULID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}).String(); got != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("ULID.String() = %s", got)
+	}
+	for _, invalid := range []string{"", "42", "8ZZZZZZZZZZZZZZZZZZZZZZZZZ", "0123456789ABCDEFGHJKMNPQRU"} {
+		if _, err := ParseULID(invalid); err == nil {
+			t.Errorf("ParseULID(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestStringID(t *testing.T) {
+	id, err := StringID("").Parse("my-slug")
+	if err != nil || id != StringID("my-slug") {
+		t.Errorf("StringID.Parse() = %v, %v", id, err)
+	}
+	if _, err := StringID("").Parse(""); err == nil {
+		t.Error("StringID.Parse() should refuse an empty key")
+	}
+}
+
+func TestCastAs(t *testing.T) {
+	u := NewUUID()
+	if got := CastAs[UUID](u.String()); got != u {
+		t.Errorf("CastAs[UUID](string) = %s, want %s", got, u)
+	}
+	if got := CastAs[UUID](u); got != u {
+		t.Errorf("CastAs[UUID](UUID) = %s, want %s", got, u)
+	}
+	if got := CastAs[UUID]("not a uuid"); !got.IsNull() {
+		t.Errorf("CastAs[UUID]() should return the null UUID, got %s", got)
+	}
+	if got := CastAs[ID](7); got != 7 {
+		t.Errorf("CastAs[ID](int) = %v, want 7", got)
+	}
+}
+
+func TestIdentifier_JSON(t *testing.T) {
+	type keyed struct {
+		Id UUID `json:"id"`
+	}
+	in := keyed{Id: NewUUID()}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"id":"`+in.Id.String()+`"}` {
+		t.Errorf("json.Marshal() = %s", data)
+	}
+	var out keyed
+	if err := json.Unmarshal(data, &out); err != nil || out != in {
+		t.Errorf("json.Unmarshal() = %v, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"id":"nope"}`), &out); err == nil {
+		t.Error("json.Unmarshal() should refuse an invalid UUID")
+	}
+}
+
+type lineKey struct{}
+
+func (lineKey) Parts() []KeyPart {
+	return []KeyPart{{Name: "order_id", Type: ID(0)}, {Name: "code", Type: StringID("")}}
+}
+
+func TestCompositeID(t *testing.T) {
+	key := NewCompositeID[lineKey](ID(3), StringID("a:b,c"))
+	if key.String() != "3:a%3Ab%2Cc" {
+		t.Errorf("CompositeID.String() = %s", key.String())
+	}
+	parsed, err := key.Parse(key.String())
+	if err != nil || parsed != key {
+		t.Fatalf("CompositeID.Parse() = %v, %v, want %v", parsed, err, key)
+	}
+	if parts := parsed.(CompositeID[lineKey]).Parts(); parts[0] != ID(3) || parts[1] != StringID("a:b,c") {
+		t.Errorf("CompositeID.Parts() = %v", parts)
+	}
+	if keys := key.Keys(); len(keys) != 2 || keys[0] != "order_id" || keys[1] != "code" {
+		t.Errorf("CompositeID.Keys() = %v", keys)
+	}
+	if !(CompositeID[lineKey]{}).IsNull() || key.IsNull() {
+		t.Error("only the zero CompositeID should be null")
+	}
+	for _, invalid := range []string{"", "3", "x:a", "3:a:b"} {
+		if _, err := key.Parse(invalid); err == nil {
+			t.Errorf("CompositeID.Parse(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestCompositeID_WrongParts(t *testing.T) {
+	if _, err := MakeCompositeID[lineKey](ID(3)); err == nil {
+		t.Error("MakeCompositeID() should reject a missing part")
+	}
+	if _, err := MakeCompositeID[lineKey](ID(3), StringID("a")); err != nil {
+		t.Errorf("MakeCompositeID() = %v", err)
+	}
+	invalid := NewCompositeID[lineKey](ID(3), StringID("a"), StringID("b"))
+	if invalid.IsNull() {
+		t.Error("an invalid CompositeID should not be null")
+	}
+	if _, err := invalid.Parse(invalid.String()); err == nil {
+		t.Error("an invalid CompositeID should not parse back")
+	}
+	if parts := invalid.Parts(); len(parts) != 2 || !parts[0].IsNull() {
+		t.Errorf("CompositeID.Parts() = %v", parts)
+	}
+}
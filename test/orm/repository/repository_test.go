@@ -32,6 +32,10 @@ func (p Product) GetId() entity.ID {
 	return entity.ID(p.ID)
 }
 
+func (p Product) GetIdentifier() entity.Identifier {
+	return p.GetId()
+}
+
 // ProductGorm is DTO used to map Product entity to database
 type ProductGorm struct {
 	ID          uint   `orm:"primaryKey;column:id"`
@@ -120,7 +124,7 @@ func TestGormRepository_FindByID(t *testing.T) {
 	repository := gormrepository.NewRepository[ProductGorm, Product](db)
 	ctx := context.Background()
 
-	_, err := repository.FindByID(ctx, 8)
+	_, err := repository.FindByID(ctx, entity.ID(8))
 
 	if err != nil {
 		t.Error(err)
@@ -146,7 +150,7 @@ func TestGormRepository_Update(t *testing.T) {
 	repository := gormrepository.NewRepository[ProductGorm, Product](db)
 	ctx := context.Background()
 
-	product, err := repository.FindByID(ctx, 8)
+	product, err := repository.FindByID(ctx, entity.ID(8))
 	if err != nil {
 		t.Error(err)
 	}
@@ -155,7 +159,7 @@ func TestGormRepository_Update(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	product2, err := repository.FindByID(ctx, 8)
+	product2, err := repository.FindByID(ctx, entity.ID(8))
 	if err != nil {
 		t.Error(err)
 	}
@@ -168,11 +172,11 @@ func TestGormRepository_DeleteByID(t *testing.T) {
 	db, _ := getDB()
 	repository := gormrepository.NewRepository[ProductGorm, Product](db)
 	ctx := context.Background()
-	err := repository.DeleteByID(ctx, 8)
+	err := repository.DeleteByID(ctx, entity.ID(8))
 	if err != nil {
 		t.Error(err)
 	}
-	_, err = repository.FindByID(ctx, 8)
+	_, err = repository.FindByID(ctx, entity.ID(8))
 	if err == nil {
 		t.Error("supposed to be deleted")
 	}
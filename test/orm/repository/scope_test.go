@@ -0,0 +1,68 @@
+package gormrepository_test
+
+import (
+	"testing"
+
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+)
+
+// Ticket is a domain entity owned by a tenant
+type Ticket struct {
+	ID     uint
+	Tenant string
+}
+
+func (t Ticket) SetId(a any) entity.Entity {
+	t.ID = uint(entity.CastId(a))
+	return t
+}
+
+func (t Ticket) GetIdentifier() entity.Identifier {
+	return entity.ID(t.ID)
+}
+
+func (t Ticket) ToEntity() Ticket {
+	return t
+}
+
+func (t Ticket) FromEntity(ticket Ticket) any {
+	return ticket
+}
+
+func TestGormRepository_Scoped(t *testing.T) {
+	db, _ := getDB()
+	db.AutoMigrate(&Ticket{})
+	db.Exec("DELETE FROM tickets")
+	repository := gormrepository.NewRepository[Ticket](db)
+	if err := repository.Create([]*Ticket{{ID: 1, Tenant: "acme"}, {ID: 2, Tenant: "acme"}, {ID: 3, Tenant: "globex"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	acme := repository.Scoped(orm.Where("Tenant", "acme"))
+	if nb, _ := acme.Count(); nb != 2 {
+		t.Errorf("expected 2 tickets in scope, got %d", nb)
+	}
+	if list, _ := acme.List(-1, -1, nil); len(list) != 2 {
+		t.Errorf("expected 2 listed tickets, got %d", len(list))
+	}
+	if found, _ := acme.Read([]entity.Identifier{entity.ID(2), entity.ID(3)}); len(found) != 1 {
+		t.Error("a ticket out of scope should not be read")
+	}
+	if err := acme.Update([]*Ticket{{ID: 3, Tenant: "acme"}}); err != errors.OutOfScope {
+		t.Errorf("updating a ticket out of scope should fail, got %v", err)
+	}
+	if err := acme.Delete([]*Ticket{{ID: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if nb, _ := repository.Count(); nb != 3 {
+		t.Errorf("a ticket out of scope should not be deleted, got %d tickets", nb)
+	}
+
+	both := repository.Scoped(orm.WhereIn("tenant", []string{"acme", "globex"}), orm.Condition{Field: "id", Operator: orm.OperatorNotEqual, Value: 1})
+	if nb, _ := both.Count(); nb != 2 {
+		t.Errorf("expected 2 tickets in scope, got %d", nb)
+	}
+}
@@ -0,0 +1,96 @@
+package gormrepository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"gorm.io/gorm"
+)
+
+type Note struct {
+	ID    uint
+	Title string
+}
+
+func (n Note) SetId(a any) entity.Entity {
+	n.ID = uint(entity.CastId(a))
+	return n
+}
+
+func (n Note) GetId() entity.ID {
+	return entity.ID(n.ID)
+}
+
+func (n Note) GetIdentifier() entity.Identifier {
+	return n.GetId()
+}
+
+type NoteGorm struct {
+	ID        uint
+	Title     string
+	DeletedAt gorm.DeletedAt
+}
+
+func (g NoteGorm) ToEntity() Note {
+	return Note{ID: g.ID, Title: g.Title}
+}
+
+func (g NoteGorm) FromEntity(note Note) any {
+	return NoteGorm{ID: note.ID, Title: note.Title}
+}
+
+func TestGormRepository_SoftDelete(t *testing.T) {
+	db, _ := getDB()
+	db.AutoMigrate(&NoteGorm{})
+	repository := gormrepository.NewRepository[NoteGorm, Note](db)
+	ctx := context.Background()
+
+	notes := []*Note{{ID: 1, Title: "a"}, {ID: 2, Title: "b"}}
+	if err := repository.Create(notes); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.Delete(notes[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if nb, _ := repository.Count(); nb != 1 {
+		t.Errorf("expected 1 live note, got %d", nb)
+	}
+	if nb, _ := repository.CountDeleted(); nb != 1 {
+		t.Errorf("expected 1 deleted note, got %d", nb)
+	}
+	deleted, err := repository.ListDeleted(-1, -1, nil)
+	if err != nil || len(deleted) != 1 || deleted[0].ID != 1 {
+		t.Fatalf("unexpected trash %v %v", deleted, err)
+	}
+	if found, _ := repository.ReadDeleted([]entity.Identifier{entity.ID(1), entity.ID(2)}); len(found) != 1 {
+		t.Errorf("live notes must not be read as deleted, got %d", len(found))
+	}
+
+	if err := repository.Restore(notes[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.FindByID(ctx, entity.ID(1)); err != nil {
+		t.Errorf("restored note should be found: %v", err)
+	}
+
+	if err := repository.Purge(notes); err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	db.Unscoped().Model(&NoteGorm{}).Count(&total)
+	if total != 0 {
+		t.Errorf("purged notes should be gone, got %d", total)
+	}
+}
+
+func TestGormRepository_SoftDelete_Unsupported(t *testing.T) {
+	db, _ := getDB()
+	repository := gormrepository.NewRepository[ProductGorm, Product](db)
+	if _, err := repository.CountDeleted(); err != errors.SoftDeleteUnsupported {
+		t.Errorf("expected SoftDeleteUnsupported, got %v", err)
+	}
+}
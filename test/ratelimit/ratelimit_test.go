@@ -0,0 +1,122 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	. "github.com/philiphil/restman/ratelimit"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	clock := &clock{now: time.Unix(1000, 0)}
+	store := NewMemoryStore()
+	store.Now = clock.Now
+	policy := TokenBucket(10, 10*time.Second)
+
+	result, _ := store.Take("alice", policy, 8)
+	if !result.Allowed || result.Remaining != 2 || result.Reset != 8*time.Second {
+		t.Fatalf("a burst within the bucket should be allowed, got %+v", result)
+	}
+	result, _ = store.Take("alice", policy, 3)
+	if result.Allowed || result.RetryAfter != time.Second {
+		t.Fatalf("the bucket should be short of one token for a second, got %+v", result)
+	}
+	if result, _ := store.Take("bob", policy, 3); !result.Allowed {
+		t.Fatal("budgets should be kept per key")
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	if result, _ := store.Take("alice", policy, 3); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("the bucket should have been refilled, got %+v", result)
+	}
+	clock.now = clock.now.Add(time.Hour)
+	if result, _ := store.Take("alice", policy, 11); result.Allowed || result.RetryAfter != 10*time.Second || result.Remaining != 10 {
+		t.Fatalf("a cost above the limit should never be allowed, got %+v", result)
+	}
+}
+
+func TestMemoryStore_SlidingWindow(t *testing.T) {
+	clock := &clock{now: time.Unix(1000, 0)}
+	store := NewMemoryStore()
+	store.Now = clock.Now
+	policy := SlidingWindow(10, 10*time.Second)
+
+	for i := 0; i < 10; i++ {
+		if result, _ := store.Take("alice", policy, 1); !result.Allowed || result.Remaining != 9-i {
+			t.Fatalf("request %d should be allowed, got %+v", i, result)
+		}
+	}
+	result, _ := store.Take("alice", policy, 1)
+	if result.Allowed || result.Reset != 10*time.Second || result.RetryAfter != 11*time.Second {
+		t.Fatalf("the window should be exhausted, got %+v", result)
+	}
+
+	//half way through the next window, half of the previous one still counts
+	clock.now = clock.now.Add(15 * time.Second)
+	result, _ = store.Take("alice", policy, 5)
+	if !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("half of the budget should be available, got %+v", result)
+	}
+	result, _ = store.Take("alice", policy, 1)
+	if result.Allowed || result.RetryAfter != time.Second {
+		t.Fatalf("the previous window should fade enough in a second, got %+v", result)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if result, _ := store.Take("alice", policy, 10); !result.Allowed {
+		t.Fatalf("an old window should not count, got %+v", result)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), SlidingWindow(1, time.Minute), Policy{}, TokenBucket(5, time.Minute))
+	if limiter.Policy(BatchBudget).Limit != 5 || limiter.Policy(ReadBudget).Limit != 1 {
+		t.Fatal("the limiter should return the policy of each budget")
+	}
+	for i := 0; i < 3; i++ {
+		if result, _ := limiter.Take("alice", limiter.Policy(WriteBudget), 100); !result.Allowed {
+			t.Fatal("an unlimited policy should always allow")
+		}
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := NewRedisStore("localhost:6379", "", 0)
+	store.Client = client
+	store.Now = func() time.Time { return time.UnixMilli(1_000_004_000) }
+
+	policy := SlidingWindow(10, 10*time.Second)
+	mock.Regexp().ExpectEval(".*", []string{"restman:ratelimit:{alice}:100000", "restman:ratelimit:{alice}:99999"}, 10, int64(10000), int64(4000), 2).
+		SetVal([]interface{}{int64(0), int64(10), int64(5)})
+	result, err := store.Take("alice", policy, 2)
+	if err != nil || result.Allowed || result.Remaining != 0 || result.Reset != 6*time.Second || result.RetryAfter != 3*time.Second {
+		t.Fatalf("unexpected sliding window result %+v %v", result, err)
+	}
+
+	policy = TokenBucket(10, 10*time.Second)
+	mock.Regexp().ExpectEval(".*", []string{"restman:ratelimit:bob"}, 10, int64(10000), int64(1_000_004_000), 1).
+		SetVal([]interface{}{int64(1), "6.5"})
+	result, err = store.Take("bob", policy, 1)
+	if err != nil || !result.Allowed || result.Remaining != 6 || result.Reset != 3500*time.Millisecond {
+		t.Fatalf("unexpected token bucket result %+v %v", result, err)
+	}
+
+	mock.Regexp().ExpectEval(".*", []string{"restman:ratelimit:bob"}, 10, int64(10000), int64(1_000_004_000), 1).
+		SetVal([]interface{}{"unexpected"})
+	if _, err := store.Take("bob", policy, 1); err == nil {
+		t.Fatal("an unexpected reply should be an error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,58 @@
+package revision_test
+
+import (
+	"testing"
+
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/gormrepository"
+	. "github.com/philiphil/restman/revision"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestORMStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:revisions?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.AutoMigrate(&Revision{})
+	store := NewORMStore(orm.NewORM(gormrepository.NewRepository[Revision](db)))
+
+	for _, revision := range []Revision{
+		{Resource: "Book", EntityID: "1", Operation: "create", Snapshot: `{"title":"a"}`},
+		{Resource: "Book", EntityID: "2", Operation: "create"},
+		{Resource: "Author", EntityID: "1", Operation: "create"},
+		{Resource: "Book", EntityID: "1", Operation: "update", Snapshot: `{"title":"b"}`},
+	} {
+		if err := store.Save(&revision); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revisions, err := store.List("Book", "1")
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("only the revisions of the entity should be listed, got %+v %v", revisions, err)
+	}
+	if revisions[0].Number != 1 || revisions[1].Number != 2 || revisions[1].Operation != "update" {
+		t.Fatalf("revisions should be numbered per entity, oldest first, got %+v", revisions)
+	}
+	if others, _ := store.List("Author", "1"); len(others) != 1 || others[0].Number != 1 {
+		t.Fatalf("resources should be numbered apart, got %+v", others)
+	}
+
+	found, err := Find(store, "Book", "1", 2)
+	if err != nil || found == nil || found.Snapshot != `{"title":"b"}` {
+		t.Fatalf("unexpected revision %+v %v", found, err)
+	}
+	if found, _ = Find(store, "Book", "1", 3); found != nil {
+		t.Fatal("unknown revisions should not be found")
+	}
+
+	//another process numbering the same revision is rejected by the database
+	if err := store.ORM.Create(&Revision{Resource: "Book", EntityID: "1", Number: 2}); err == nil {
+		t.Fatal("revision numbers should be unique per entity")
+	}
+}
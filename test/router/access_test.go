@@ -0,0 +1,127 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+type VotedTest struct {
+	entity.BaseEntity
+}
+
+func (e VotedTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+
+func (t VotedTest) ToEntity() VotedTest {
+	return t
+}
+
+func (t VotedTest) FromEntity(entity VotedTest) any {
+	return entity
+}
+
+func TestApiRouter_Security(t *testing.T) {
+	getDB().AutoMigrate(&VotedTest{})
+	getDB().Exec("DELETE FROM voted_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[VotedTest](getDB()))
+	routes := route.DefaultApiRoutes()
+	routes[route.GetList] = route.NewRoute(route.GetList, configuration.Security("PUBLIC_ACCESS"))
+	routes[route.Post] = route.NewRoute(route.Post, configuration.Security("ROLE_WRITER"))
+	routes[route.Delete] = route.NewRoute(route.Delete, configuration.Security("ROLE_ADMIN or VOTED_DELETE"))
+	test_ := NewApiRouter(*repo, routes, configuration.Security("IS_AUTHENTICATED"))
+	test_.AddFirewall(RoleFirewall{})
+	test_.AddVoter(security.VoterFunc(func(user security.User, attribute string, subject any) security.Vote {
+		if attribute != "VOTED_DELETE" {
+			return security.AccessAbstain
+		}
+		if voted, ok := subject.(VotedTest); ok && voted.Name != "locked" {
+			return security.AccessGranted
+		}
+		return security.AccessDenied
+	}))
+	test_.AllowRoutes(r)
+
+	serve := func(method string, url string, user string, roles string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if user != "" {
+			req.Header.Set("Authorization", user)
+		}
+		if roles != "" {
+			req.Header.Set("X-Roles", roles)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("POST", "/api/voted_test", "1", "", `{"id":1}`); w.Code != http.StatusForbidden {
+		t.Fatalf("creating requires ROLE_WRITER, got %d", w.Code)
+	}
+	if w := serve("POST", "/api/voted_test", "", "", `{"id":1}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous users should be asked to authenticate, got %d", w.Code)
+	}
+	if w := serve("POST", "/api/voted_test", "1", "ROLE_WRITER", `{"id":1,"name":"locked"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create failed %d %s", w.Code, w.Body.String())
+	}
+	serve("POST", "/api/voted_test", "1", "ROLE_WRITER", `{"id":2,"name":"open"}`)
+
+	if w := serve("GET", "/api/voted_test", "", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("the list is public, got %d", w.Code)
+	}
+	if w := serve("GET", "/api/voted_test/1", "", "", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("the router wide expression should apply, got %d", w.Code)
+	}
+	if w := serve("GET", "/api/voted_test/1", "2", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	if w := serve("DELETE", "/api/voted_test/1", "2", "", ""); w.Code != http.StatusForbidden {
+		t.Fatalf("the voter should deny deleting a locked entity, got %d", w.Code)
+	}
+	if w := serve("DELETE", "/api/voted_test/2", "2", "", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("the voter should grant deleting an open entity, got %d", w.Code)
+	}
+	if w := serve("DELETE", "/api/voted_test/1", "2", "ROLE_ADMIN", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("admins delete everything, got %d", w.Code)
+	}
+}
+
+func TestApiRouter_SecurityMalformed(t *testing.T) {
+	repo := orm.NewORM(gormrepository.NewRepository[VotedTest](getDB()))
+	mustPanic := func(name string, build func()) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("a malformed %s expression should panic", name)
+			}
+		}()
+		build()
+	}
+	mustPanic("router wide", func() {
+		NewApiRouter(*repo, route.DefaultApiRoutes(), configuration.Security("ROLE_ADMIN or"))
+	})
+	mustPanic("route", func() {
+		routes := route.DefaultApiRoutes()
+		routes[route.Delete] = route.NewRoute(route.Delete, configuration.Security("(ROLE_ADMIN"))
+		NewApiRouter(*repo, routes)
+	})
+	mustPanic("late route", func() {
+		test_ := NewApiRouter(*repo, route.DefaultApiRoutes())
+		test_.Routes[route.Put] = route.NewRoute(route.Put, configuration.Security("ROLE_ADMIN and and ROLE_EDITOR"))
+		test_.AllowRoutes(SetupRouter())
+	})
+}
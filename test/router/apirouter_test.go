@@ -96,6 +96,9 @@ type AnonymousUser struct {
 func (u AnonymousUser) GetId() entity.ID {
 	return 0
 }
+func (u AnonymousUser) GetIdentifier() entity.Identifier {
+	return u.GetId()
+}
 func (u AnonymousUser) SetId(id any) entity.Entity {
 	return u
 }
@@ -0,0 +1,92 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/audit"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+)
+
+func TestApiRouter_Audit(t *testing.T) {
+	getDB().AutoMigrate(&VotedTest{}, &audit.Entry{})
+	getDB().Exec("DELETE FROM voted_tests")
+	getDB().Exec("DELETE FROM audit_entries")
+	r := SetupRouter()
+
+	entries := orm.NewORM(gormrepository.NewRepository[audit.Entry](getDB()))
+	var records []audit.Record
+	repo := orm.NewORM(gormrepository.NewRepository[VotedTest](getDB()))
+	test_ := NewApiRouter(*repo, route.AllApiRoutes(), configuration.RouteName("audit_test"))
+	test_.AddFirewall(RoleFirewall{})
+	test_.SetAuditSink(audit.MultiSink{
+		audit.NewORMSink(entries),
+		audit.SinkFunc(func(record audit.Record) error {
+			records = append(records, record)
+			return nil
+		}),
+	})
+	test_.AllowRoutes(r)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "7")
+		req.Header.Set(audit.RequestIDHeader, "request-"+method)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("POST", "/api/audit_test", `[{"id":1,"name":"first"},{"id":2,"name":"second"}]`); w.Code != http.StatusCreated {
+		t.Fatalf("batch post failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("PATCH", "/api/audit_test/1", `{"name":"renamed"}`); w.Code != http.StatusOK {
+		t.Fatalf("patch failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/audit_test/1", ""); w.Code != http.StatusOK {
+		t.Fatalf("get failed %d", w.Code)
+	}
+	if w := serve("DELETE", "/api/audit_test?ids=1,2", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("batch delete failed %d", w.Code)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("every write and only writes should be audited, got %+v", records)
+	}
+	created, patched, deleted := records[0], records[1], records[2]
+	if created.Operation != audit.Create || !created.Batch || created.User != "7" || created.RequestID != "request-POST" || strings.Join(created.IDs, ",") != "1,2" {
+		t.Fatalf("unexpected creation record %+v", created)
+	}
+	if patched.Operation != audit.Update || patched.Batch {
+		t.Fatalf("unexpected patch record %+v", patched)
+	}
+	renamed := false
+	for _, change := range patched.Changes {
+		renamed = renamed || change.Field == "name" && change.Before == "first" && change.After == "renamed"
+		if change.Field == "id" || change.Field == "description" {
+			t.Fatalf("unchanged fields should not be listed, got %+v", change)
+		}
+	}
+	if !renamed {
+		t.Fatalf("the patch should be diffed, got %+v", patched.Changes)
+	}
+	if deleted.Operation != audit.Delete || strings.Join(deleted.IDs, ",") != "1,2" || len(deleted.Changes) == 0 || deleted.Changes[0].After != nil {
+		t.Fatalf("unexpected deletion record %+v", deleted)
+	}
+
+	stored, err := entries.GetAll(nil)
+	if err != nil || len(stored) != 3 {
+		t.Fatalf("the records should be stored, got %d %v", len(stored), err)
+	}
+	record, err := stored[1].Record()
+	if err != nil || record.Resource != "VotedTest" || record.RequestID != "request-PATCH" || len(record.Changes) != len(patched.Changes) {
+		t.Fatalf("unexpected stored record %+v %v", record, err)
+	}
+}
@@ -0,0 +1,111 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+)
+
+type OrderLineKey struct{}
+
+func (OrderLineKey) Parts() []entity.KeyPart {
+	return []entity.KeyPart{{Name: "order_id", Type: entity.ID(0)}, {Name: "line_no", Type: entity.ID(0)}}
+}
+
+type OrderLine struct {
+	OrderId entity.ID `json:"order_id" gorm:"primaryKey;autoIncrement:false"`
+	LineNo  entity.ID `json:"line_no" gorm:"primaryKey;autoIncrement:false"`
+	Product string    `json:"product"`
+}
+
+func (l OrderLine) GetIdentifier() entity.Identifier {
+	return entity.NewCompositeID[OrderLineKey](l.OrderId, l.LineNo)
+}
+
+func (l OrderLine) SetId(id any) entity.Entity {
+	key := entity.CastAs[entity.CompositeID[OrderLineKey]](id)
+	l.OrderId, l.LineNo = key.Part(0).(entity.ID), key.Part(1).(entity.ID)
+	return l
+}
+
+func (t OrderLine) ToEntity() OrderLine {
+	return t
+}
+
+func (t OrderLine) FromEntity(entity OrderLine) any {
+	return entity
+}
+
+func TestApiRouter_CompositeIdentifier(t *testing.T) {
+	getDB().AutoMigrate(&OrderLine{})
+	getDB().Exec("DELETE FROM order_lines")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[OrderLine](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes())
+	test_.Routes[route.BatchGet] = route.Route{RouteType: route.BatchGet}
+	test_.AllowRoutes(r)
+
+	if test_.ItemPath() != "/:order_id/:line_no" {
+		t.Fatalf("unexpected item path %s", test_.ItemPath())
+	}
+	repo.Create(&OrderLine{1, 1, "apple"}, &OrderLine{1, 2, "pear"}, &OrderLine{2, 1, "plum"})
+
+	serve := func(method string, url string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("GET", "/api/order_line/1/2", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "pear") {
+		t.Fatalf("get failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/order_line/2/2", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown line should not be found, got %d", w.Code)
+	}
+	if w := serve("GET", "/api/order_line/1/x", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed line should be a bad request, got %d", w.Code)
+	}
+
+	w = serve("GET", "/api/order_line?ids=1:1,2:1", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "apple") || !strings.Contains(w.Body.String(), "plum") || strings.Contains(w.Body.String(), "pear") {
+		t.Fatalf("batch get failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/order_line?ids=1:1,2", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("incomplete composite id should be a bad request, got %d", w.Code)
+	}
+
+	if w := serve("PATCH", "/api/order_line/1/1", `{"product":"banana"}`); w.Code != http.StatusOK {
+		t.Fatalf("patch failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("PUT", "/api/order_line/3/1", `{"product":"cherry"}`); w.Code != http.StatusOK {
+		t.Fatalf("put failed %d %s", w.Code, w.Body.String())
+	}
+	line, err := repo.GetByID("3:1")
+	if err != nil || line.Product != "cherry" {
+		t.Fatalf("put should create line 3:1, got %v %v", line, err)
+	}
+	if line, _ := repo.GetByID("1:1"); line == nil || line.Product != "banana" {
+		t.Fatalf("patch should update line 1:1 only, got %v", line)
+	}
+
+	if w := serve("DELETE", "/api/order_line/1/2", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete failed %d", w.Code)
+	}
+	if count, _ := repo.Count(); count != 3 {
+		t.Errorf("expected 3 lines left, got %d", count)
+	}
+	if _, err := repo.GetByID("1:1"); err != nil {
+		t.Errorf("deleting 1:2 should keep 1:1, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+type CredentialUser struct {
+	entity.BaseEntity
+	Username     string
+	PasswordHash string
+	KeyHash      string
+}
+
+func (u CredentialUser) SetId(id any) entity.Entity {
+	u.Id = entity.CastId(id)
+	return u
+}
+
+func (u CredentialUser) ToEntity() CredentialUser {
+	return u
+}
+
+func (u CredentialUser) FromEntity(entity CredentialUser) any {
+	return entity
+}
+
+func (u CredentialUser) GetPasswordHash() string {
+	return u.PasswordHash
+}
+
+func (u CredentialUser) GetAPIKey() security.APIKey {
+	return security.APIKey{Hash: u.KeyHash, User: u, Scopes: []string{"credential_test"}}
+}
+
+func TestApiRouter_CredentialFirewalls(t *testing.T) {
+	getDB().AutoMigrate(&CredentialUser{}, &VotedTest{})
+	getDB().Exec("DELETE FROM credential_users")
+	r := SetupRouter()
+
+	users := orm.NewORM(gormrepository.NewRepository[CredentialUser](getDB()))
+	hash, _ := security.BcryptHasher{Cost: 4}.Hash("s3cret")
+	key, keyHash, _ := security.GenerateAPIKey("rm_")
+	users.Create(&CredentialUser{BaseEntity: entity.BaseEntity{Id: 1}, Username: "alice", PasswordHash: hash, KeyHash: keyHash})
+
+	repo := orm.NewORM(gormrepository.NewRepository[VotedTest](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes(), configuration.RouteName("credential_test"), configuration.Security("IS_AUTHENTICATED"))
+	test_.AddFirewall(
+		security.NewAPIKeyFirewall(security.NewORMAPIKeyProvider(users, "key_hash"), "credential_test"),
+		security.NewBasicFirewall(security.NewORMUserProvider(users, "username"), "restman"),
+	)
+	test_.AllowRoutes(r)
+
+	serve := func(setup func(*http.Request)) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/credential_test", nil)
+		setup(req)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve(func(*http.Request) {}); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `Basic realm="restman", charset="UTF-8"` {
+		t.Fatalf("anonymous users should be challenged, got %d %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+	if w := serve(func(req *http.Request) { req.SetBasicAuth("alice", "s3cret") }); w.Code != http.StatusOK {
+		t.Fatalf("alice should be authenticated with Basic, got %d %s", w.Code, w.Body.String())
+	}
+	w := serve(func(req *http.Request) { req.SetBasicAuth("alice", "wrong") })
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("a wrong password should be challenged, got %d %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+	if w := serve(func(req *http.Request) { req.Header.Set(security.DefaultAPIKeyHeader, key) }); w.Code != http.StatusOK {
+		t.Fatalf("alice should be authenticated with the API key, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve(func(req *http.Request) { req.Header.Set(security.DefaultAPIKeyHeader, keyHash) }); w.Code != http.StatusUnauthorized {
+		t.Fatalf("the stored hash is not a valid key, got %d", w.Code)
+	}
+	if w := serve(func(req *http.Request) { req.SetBasicAuth("alice", "s3cret") }); w.Header().Get("WWW-Authenticate") != "" {
+		t.Fatalf("authenticated requests should not be challenged, got %q", w.Header().Get("WWW-Authenticate"))
+	}
+}
@@ -0,0 +1,150 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+type StampedTest struct {
+	entity.BaseEntity
+	CreatedBy string
+}
+
+func (e StampedTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+
+func (t StampedTest) ToEntity() StampedTest {
+	return t
+}
+
+func (t StampedTest) FromEntity(entity StampedTest) any {
+	return entity
+}
+
+// BeforeCreate stamps the entity with the user of the request
+func (t *StampedTest) BeforeCreate(tx *gorm.DB) error {
+	if user, ok := security.CurrentUser(tx.Statement.Context); ok {
+		t.CreatedBy = user.GetIdentifier().String()
+	}
+	return nil
+}
+
+// CountingFirewall authenticates like TestFirewall and counts its calls
+type CountingFirewall struct {
+	Calls *int
+}
+
+func (f CountingFirewall) GetUser(c *gin.Context) (security.User, error) {
+	*f.Calls++
+	return TestFirewall{}.GetUser(c)
+}
+
+func TestApiRouter_CurrentUser(t *testing.T) {
+	getDB().AutoMigrate(&StampedTest{})
+	getDB().Exec("DELETE FROM stamped_tests")
+	r := SetupRouter()
+
+	calls := 0
+	repo := orm.NewORM(gormrepository.NewRepository[StampedTest](getDB()))
+	routes := route.DefaultApiRoutes()
+	routes[route.BatchPost] = route.NewRoute(route.BatchPost, configuration.Security("IS_AUTHENTICATED"))
+	test_ := NewApiRouter(*repo, routes, configuration.RouteName("stamped_test"))
+	test_.AddFirewall(CountingFirewall{Calls: &calls})
+	test_.AllowRoutes(r)
+	r.GET("/api/whoami", test_.Authenticate, func(c *gin.Context) {
+		user, ok := security.CurrentUser(c)
+		if !ok {
+			c.String(http.StatusOK, "anonymous")
+			return
+		}
+		c.String(http.StatusOK, user.GetIdentifier().String())
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/stamped_test", strings.NewReader(`[{"id":1},{"id":2}]`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "7")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("batch post failed %d %s", w.Code, w.Body.String())
+	}
+	if calls != 1 {
+		t.Fatalf("the firewall should run once per request, ran %d times", calls)
+	}
+	stamped, _ := repo.GetByID(2)
+	if stamped == nil || stamped.CreatedBy != "7" {
+		t.Fatalf("the hook should see the current user, got %+v", stamped)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/whoami", nil)
+	req.Header.Set("Authorization", "7")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "7" {
+		t.Fatalf("custom operations should get the current user, got %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/whoami", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("blocking firewall errors should abort custom operations, got %d", w.Code)
+	}
+}
+
+func TestApiRouter_FirewallsPerRouter(t *testing.T) {
+	r := SetupRouter()
+	calls := 0
+	repo := orm.NewORM(gormrepository.NewRepository[StampedTest](getDB()))
+	open := NewApiRouter(*repo, route.DefaultApiRoutes())
+	locked := NewApiRouter(*repo, route.DefaultApiRoutes())
+	locked.AddFirewall(CountingFirewall{Calls: &calls})
+	r.GET("/api/both", open.Authenticate, locked.Authenticate, func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/both", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized || calls != 1 {
+		t.Fatalf("the firewalls of every router should run, got %d after %d calls", w.Code, calls)
+	}
+}
+
+func TestApiRouter_AuthenticationRequired(t *testing.T) {
+	getDB().AutoMigrate(&StampedTest{})
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[StampedTest](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes(), configuration.RouteName("required_test"), configuration.AuthenticationRequired(true))
+	test_.AddFirewall(RoleFirewall{})
+	test_.AllowRoutes(r)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/required_test", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous users should be rejected, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/required_test", nil)
+	req.Header.Set("Authorization", "1")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("authenticated users should be accepted, got %d", w.Code)
+	}
+}
@@ -0,0 +1,48 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/openapi"
+	. "github.com/philiphil/restman/router"
+)
+
+func TestDocsUI(t *testing.T) {
+	docs := NewOpenAPI(openapi.Info{Title: "Docs <API>", Version: "1.0.0"})
+	ui := NewDocsUI(docs)
+	r := SetupRouter()
+	docs.AllowRoutes(r)
+	ui.AllowRoutes(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/docs", nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("unexpected page response %d %v", w.Code, w.Header())
+	}
+	page := w.Body.String()
+	if !strings.Contains(page, `data-spec="/api/openapi.json"`) || !strings.Contains(page, "Docs &lt;API&gt;") {
+		t.Errorf("unexpected page %s", page)
+	}
+	if strings.Contains(page, "http://") || strings.Contains(page, "https://") {
+		t.Error("expected no external asset")
+	}
+
+	for _, asset := range []string{"/api/docs/docs.js", "/api/docs/docs.css"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", asset, nil))
+		if w.Code != http.StatusOK || w.Body.Len() == 0 {
+			t.Errorf("unexpected asset response %s %d", asset, w.Code)
+		}
+	}
+
+	disabled := NewDocsUI(docs)
+	disabled.Disabled = true
+	r = SetupRouter()
+	disabled.AllowRoutes(r)
+	if len(r.Routes()) != 0 {
+		t.Errorf("expected no route when disabled, got %v", r.Routes())
+	}
+}
@@ -0,0 +1,109 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/audit"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/event"
+	"github.com/philiphil/restman/event/gormoutbox"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+)
+
+func TestApiRouter_Events(t *testing.T) {
+	getDB().AutoMigrate(&FieldSecurityTest{})
+	getDB().Exec("DELETE FROM field_security_tests")
+	r := SetupRouter()
+
+	var events []event.Event
+	dispatcher := event.NewDispatcher()
+	dispatcher.Subscribe(func(e event.Event) error {
+		events = append(events, e)
+		return nil
+	}, "FieldSecurityTest")
+	repo := orm.NewORM(gormrepository.NewRepository[FieldSecurityTest](getDB()))
+	test_ := NewApiRouter(*repo, route.AllApiRoutes(), configuration.RouteName("event_test"))
+	test_.AddFirewall(RoleFirewall{})
+	test_.SetEventBus(dispatcher, "read")
+	test_.AllowRoutes(r)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "7")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("POST", "/api/event_test", `[{"id":1,"title":"first","email":"a@b.c"},{"id":2,"title":"second"}]`); w.Code != http.StatusCreated {
+		t.Fatalf("batch post failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/event_test/1", ""); w.Code != http.StatusOK {
+		t.Fatalf("get failed %d", w.Code)
+	}
+	if w := serve("DELETE", "/api/event_test/2", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete failed %d", w.Code)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("every write and only writes should emit an event, got %+v", events)
+	}
+	created, deleted := events[0], events[1]
+	if created.Name() != "FieldSecurityTest.create" || !created.Batch || created.User != "7" || strings.Join(created.IDs, ",") != "1,2" || created.ID == "" {
+		t.Fatalf("unexpected creation event %+v", created)
+	}
+	entities, err := event.Decode[FieldSecurityTest](created)
+	if err != nil || len(entities) != 2 || entities[0].Title != "first" || entities[0].Email != "" {
+		t.Fatalf("the payload should be serialized with the groups of the bus, got %s %v", created.Payload, err)
+	}
+	if deleted.Operation != audit.Delete || strings.Join(deleted.IDs, ",") != "2" || !strings.Contains(string(deleted.Payload), "second") {
+		t.Fatalf("deletions should carry the deleted entities, got %+v", deleted)
+	}
+}
+
+func TestApiRouter_EventOutbox(t *testing.T) {
+	getDB().AutoMigrate(&VotedTest{}, &gormoutbox.Message{})
+	getDB().Exec("DELETE FROM voted_tests")
+	getDB().Exec("DELETE FROM outbox_messages")
+	r := SetupRouter()
+
+	outbox := gormoutbox.NewOutbox(getDB())
+	repo := orm.NewORM(gormrepository.NewRepository[VotedTest](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes(), configuration.RouteName("outbox_test"))
+	test_.SetEventBus(outbox)
+	test_.AllowRoutes(r)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("POST", "/api/outbox_test", `{"id":1,"name":"first"}`); w.Code != http.StatusCreated {
+		t.Fatalf("post failed %d %s", w.Code, w.Body.String())
+	}
+	pending, err := outbox.Pending(t.Context(), 10)
+	if err != nil || len(pending) != 1 || pending[0].Name != "VotedTest.create" {
+		t.Fatalf("the event should be stored with the write, got %+v %v", pending, err)
+	}
+
+	//without its outbox table the write cannot be committed with its event
+	getDB().Migrator().DropTable(&gormoutbox.Message{})
+	if w := serve("PATCH", "/api/outbox_test/1", `{"name":"second"}`); w.Code == http.StatusOK {
+		t.Fatal("the write should fail with its event")
+	}
+	getDB().AutoMigrate(&gormoutbox.Message{})
+	object, err := repo.GetByID(1)
+	if err != nil || object.Name != "first" {
+		t.Fatalf("the write should be rolled back, got %+v %v", object, err)
+	}
+}
@@ -0,0 +1,107 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+type FieldSecurityTest struct {
+	entity.BaseEntity
+	Title string `json:"title" groups:"read,write"`
+	Email string `json:"email" groups:"admin"`
+	Role  string `json:"role" groups:"admin_write"`
+}
+
+func (e FieldSecurityTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+
+func (t FieldSecurityTest) ToEntity() FieldSecurityTest {
+	return t
+}
+
+func (t FieldSecurityTest) FromEntity(entity FieldSecurityTest) any {
+	return entity
+}
+
+func TestApiRouter_GroupResolver(t *testing.T) {
+	getDB().AutoMigrate(&FieldSecurityTest{})
+	getDB().Exec("DELETE FROM field_security_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[FieldSecurityTest](getDB()))
+	routes := route.DefaultApiRoutes()
+	routes[route.BatchPut] = route.Route{RouteType: route.BatchPut}
+	test_ := NewApiRouter(*repo, routes,
+		configuration.InputSerializationGroups("write"),
+		configuration.OutputSerializationGroups("read"),
+		configuration.OutputSerializationGroupOverwriteClientControl(true),
+	)
+	test_.AddFirewall(RoleFirewall{})
+	test_.SetGroupResolver(func(c *gin.Context, context GroupContext) []string {
+		if !security.HasRole(context.User, "ROLE_ADMIN") {
+			return context.Groups
+		}
+		if context.Input {
+			return append(context.Groups, "admin_write")
+		}
+		return append(context.Groups, "admin")
+	})
+	test_.AllowRoutes(r)
+
+	repo.Create(&FieldSecurityTest{BaseEntity: entity.BaseEntity{Id: 1}, Title: "t", Email: "a@b.c", Role: "user"})
+
+	serve := func(method string, url string, roles string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "1")
+		if roles != "" {
+			req.Header.Set("X-Roles", roles)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("GET", "/api/field_security_test/1", "", ""); w.Code != http.StatusOK || strings.Contains(w.Body.String(), "a@b.c") {
+		t.Fatalf("users should not see the email, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/field_security_test/1", "ROLE_ADMIN", ""); !strings.Contains(w.Body.String(), "a@b.c") {
+		t.Fatalf("admins should see the email, got %s", w.Body.String())
+	}
+	if w := serve("GET", "/api/field_security_test/1?groupOverwrite=admin", "", ""); strings.Contains(w.Body.String(), "a@b.c") {
+		t.Fatalf("the overwrite should not widen the groups, got %s", w.Body.String())
+	}
+	if w := serve("GET", "/api/field_security_test/1?groupOverwrite=admin", "ROLE_ADMIN", ""); strings.Contains(w.Body.String(), `"title"`) || !strings.Contains(w.Body.String(), "a@b.c") {
+		t.Fatalf("the overwrite should narrow the groups, got %s", w.Body.String())
+	}
+
+	if w := serve("PATCH", "/api/field_security_test/1", "", `{"title":"new","role":"admin"}`); w.Code != http.StatusOK {
+		t.Fatalf("patch failed %d", w.Code)
+	}
+	if object, _ := repo.GetByID(1); object.Title != "new" || object.Role != "user" {
+		t.Fatalf("users should not write the role, got %+v", object)
+	}
+	if w := serve("PUT", "/api/field_security_test", "", `[{"id":1,"title":"batch","role":"admin"}]`); w.Code >= 300 {
+		t.Fatalf("batch put failed %d", w.Code)
+	}
+	if object, _ := repo.GetByID(1); object.Title != "batch" || object.Role != "user" {
+		t.Fatalf("batch writes should respect the input groups, got %+v", object)
+	}
+	serve("PATCH", "/api/field_security_test/1", "ROLE_ADMIN", `{"role":"admin"}`)
+	if object, _ := repo.GetByID(1); object.Role != "admin" {
+		t.Fatalf("admins should write the role, got %+v", object)
+	}
+}
@@ -0,0 +1,134 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"gorm.io/gorm"
+)
+
+type UuidTest struct {
+	Id   entity.UUID `json:"id" gorm:"primaryKey;type:text"`
+	Name string      `json:"name"`
+}
+
+func (e UuidTest) GetIdentifier() entity.Identifier {
+	return e.Id
+}
+
+func (e UuidTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastAs[entity.UUID](id)
+	return e
+}
+
+func (e *UuidTest) BeforeCreate(tx *gorm.DB) error {
+	if e.Id.IsNull() {
+		e.Id = entity.NewUUID()
+	}
+	return nil
+}
+
+func (t UuidTest) ToEntity() UuidTest {
+	return t
+}
+
+func (t UuidTest) FromEntity(entity UuidTest) any {
+	return entity
+}
+
+func TestApiRouter_UuidIdentifier(t *testing.T) {
+	getDB().AutoMigrate(&UuidTest{})
+	getDB().Exec("DELETE FROM uuid_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[UuidTest](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes())
+	test_.Routes[route.BatchGet] = route.Route{RouteType: route.BatchGet}
+	test_.AllowRoutes(r)
+
+	first, second := UuidTest{Name: "first"}, UuidTest{Name: "second"}
+	if err := repo.Create(&first, &second); err != nil {
+		t.Fatal(err)
+	}
+	if first.Id.IsNull() || first.Id == second.Id {
+		t.Fatalf("ids should have been generated, got %s and %s", first.Id, second.Id)
+	}
+
+	serve := func(method string, url string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("GET", "/api/uuid_test/"+first.Id.String(), "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), first.Id.String()) {
+		t.Fatalf("get failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/uuid_test/"+entity.NewUUID().String(), ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown uuid should not be found, got %d", w.Code)
+	}
+	if w := serve("GET", "/api/uuid_test/42", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed uuid should be a bad request, got %d", w.Code)
+	}
+
+	w = serve("GET", "/api/uuid_test?ids="+first.Id.String()+","+second.Id.String(), "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "second") {
+		t.Fatalf("batch get failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/uuid_test?ids="+first.Id.String()+",nope", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed batch uuid should be a bad request, got %d", w.Code)
+	}
+
+	if w := serve("PATCH", "/api/uuid_test/"+first.Id.String(), `{"name":"patched"}`); w.Code != http.StatusOK {
+		t.Fatalf("patch failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("PUT", "/api/uuid_test/nope", `{"name":"put"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("put with a malformed uuid should be a bad request, got %d", w.Code)
+	}
+	third := entity.NewUUID()
+	if w := serve("PUT", "/api/uuid_test/"+third.String(), `{"name":"third"}`); w.Code != http.StatusOK {
+		t.Fatalf("put failed %d %s", w.Code, w.Body.String())
+	}
+	if found, err := repo.GetByID(third); err != nil || found.Name != "third" {
+		t.Fatalf("put should create the entity under its uuid, got %v %v", found, err)
+	}
+
+	if w := serve("DELETE", "/api/uuid_test/"+first.Id.String(), ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete failed %d", w.Code)
+	}
+	if count, _ := repo.Count(); count != 2 {
+		t.Errorf("expected 2 entities left, got %d", count)
+	}
+}
+
+func TestApiRouter_InvalidNumericIdentifier(t *testing.T) {
+	getDB().AutoMigrate(&Test{})
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[Test](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes())
+	test_.Routes[route.BatchGet] = route.Route{RouteType: route.BatchGet}
+	test_.AllowRoutes(r)
+
+	for _, request := range []struct{ method, url string }{
+		{"GET", "/api/test/abc"},
+		{"DELETE", "/api/test/abc"},
+		{"GET", "/api/test?ids=1,abc"},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(request.method, request.url, nil)
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s %s should be a bad request, got %d", request.method, request.url, w.Code)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/openapi"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+func TestOpenAPI(t *testing.T) {
+	resourceRouter := NewApiRouter(
+		*orm.NewORM(gormrepository.NewRepository[Resource](getDB())),
+		route.DefaultApiRoutes(),
+		configuration.OutputSerializationGroups("read"),
+	)
+	resourceRouter.AddSubresource(NewApiRouter(
+		*orm.NewORM(gormrepository.NewRepository[SubResource](getDB())),
+		map[route.RouteType]route.Route{
+			route.Get:     route.NewRoute(route.Get),
+			route.GetList: route.NewRoute(route.GetList),
+		},
+	))
+	testRouter := NewApiRouter(
+		*orm.NewORM(gormrepository.NewRepository[Test](getDB())),
+		map[route.RouteType]route.Route{
+			route.GetList:   route.NewRoute(route.GetList),
+			route.BatchGet:  route.NewRoute(route.BatchGet),
+			route.Post:      route.NewRoute(route.Post),
+			route.BatchPost: route.NewRoute(route.BatchPost),
+			route.Revision:  route.NewRoute(route.Revision),
+		},
+		configuration.Pagination(true),
+	)
+	testRouter.Firewalls = append(testRouter.Firewalls, security.NewAPIKeyFirewall(nil), &security.APIKeyFirewall{Header: "X-Partner-Key"})
+
+	docs := NewOpenAPI(openapi.Info{Title: "Test API", Version: "1.0.0"}, resourceRouter)
+	docs.Register(testRouter)
+	r := SetupRouter()
+	docs.AllowRoutes(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/openapi", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != openapi.Version || doc.Info.Title != "Test API" {
+		t.Errorf("unexpected header %s %v", doc.OpenAPI, doc.Info)
+	}
+
+	item := doc.Paths["/api/resource/{id}"]
+	if item == nil || item.Get == nil || item.Put == nil || item.Patch == nil || item.Delete == nil {
+		t.Fatalf("missing item operations %+v", item)
+	}
+	if item.Get.OperationID != "resource_get" || item.Get.Parameters[0].Name != "id" || item.Get.Parameters[0].In != "path" {
+		t.Errorf("unexpected get operation %+v", item.Get)
+	}
+	if ref := item.Get.Responses["200"].Content["application/json"].Schema.Ref; ref != openapi.SchemaRefPrefix+"Resource-read" {
+		t.Errorf("unexpected response schema %s", ref)
+	}
+	if _, ok := doc.Components.Schemas["Resource-read"]; !ok {
+		t.Error("expected the output schema in the components")
+	}
+	if ref := item.Put.RequestBody.Content["application/json"].Schema.Ref; ref != openapi.SchemaRefPrefix+"ResourceInput" {
+		t.Errorf("unexpected request schema %s", ref)
+	}
+	if _, ok := item.Get.Responses["404"]; !ok {
+		t.Error("expected a not found response")
+	}
+	if item.Get.Security != nil {
+		t.Error("expected no security without firewall")
+	}
+
+	sub := doc.Paths["/api/resource/{id}/sub_resource/{sub_resource_id}"]
+	if sub == nil || sub.Get == nil || sub.Put != nil {
+		t.Fatalf("unexpected subresource operations %+v", sub)
+	}
+	if sub.Get.OperationID != "resource_sub_resource_get" || len(sub.Get.Parameters) != 2 {
+		t.Errorf("unexpected subresource operation %+v", sub.Get)
+	}
+
+	list := doc.Paths["/api/test"]
+	if list == nil || list.Get == nil || list.Post == nil {
+		t.Fatalf("missing collection operations %+v", list)
+	}
+	names := map[string]bool{}
+	for _, parameter := range list.Get.Parameters {
+		names[parameter.Name] = true
+	}
+	for _, name := range []string{"page", "itemsPerPage", "sort", "ids"} {
+		if !names[name] {
+			t.Errorf("expected the %s parameter", name)
+		}
+	}
+	if len(list.Post.RequestBody.Content["application/json"].Schema.OneOf) != 2 {
+		t.Error("expected a single entity or a list to be created")
+	}
+	if _, ok := list.Get.Responses["200"].Content["application/ld+json"].Schema.Properties["hydra:member"]; !ok {
+		t.Error("expected a hydra collection")
+	}
+	if len(list.Get.Security) != 3 || list.Get.Security[0]["apiKeyAuth"] == nil || list.Get.Security[1]["apiKeyAuth_X-Partner-Key"] == nil || len(list.Get.Security[2]) != 0 {
+		t.Errorf("unexpected security %v", list.Get.Security)
+	}
+	if scheme := doc.Components.SecuritySchemes["apiKeyAuth"]; scheme == nil || scheme.Name != security.DefaultAPIKeyHeader {
+		t.Errorf("unexpected security scheme %+v", scheme)
+	}
+	if scheme := doc.Components.SecuritySchemes["apiKeyAuth_X-Partner-Key"]; scheme == nil || scheme.Name != "X-Partner-Key" {
+		t.Errorf("firewalls with different headers should get their own scheme, got %+v", scheme)
+	}
+
+	revision := doc.Paths["/api/test/{id}/revisions/{rev}"]
+	if revision == nil || revision.Get == nil || revision.Get.Parameters[1].Schema.Type[0] != "integer" {
+		t.Fatalf("unexpected revision operation %+v", revision)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/openapi.yaml", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/yaml" || !strings.Contains(w.Body.String(), "openapi: 3.1.0") {
+		t.Errorf("unexpected yaml response %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/openapi", nil)
+	req.Header.Set("Accept", "application/yaml")
+	r.ServeHTTP(w, req)
+	if w.Header().Get("Content-Type") != "application/yaml" {
+		t.Errorf("expected yaml to be negotiated, got %s", w.Header().Get("Content-Type"))
+	}
+}
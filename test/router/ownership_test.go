@@ -0,0 +1,152 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+type OwnedTest struct {
+	entity.BaseEntity
+	OwnerId entity.ID `json:"owner_id" security:"owner"`
+}
+
+func (e OwnedTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+
+func (t OwnedTest) ToEntity() OwnedTest {
+	return t
+}
+
+func (t OwnedTest) FromEntity(entity OwnedTest) any {
+	return entity
+}
+
+type RoleUser struct {
+	TestUser
+	Roles []string
+}
+
+func (u RoleUser) GetRoles() []string {
+	return u.Roles
+}
+
+// RoleFirewall authenticates like TestFirewall and reads the roles of the user from the X-Roles header
+type RoleFirewall struct{}
+
+func (f RoleFirewall) GetUser(c *gin.Context) (security.User, error) {
+	token := c.GetHeader("Authorization")
+	if token == "" {
+		return nil, errors.ApiError{Code: http.StatusUnauthorized, Message: "unauthorized", Blocking: false}
+	}
+	user := RoleUser{TestUser: TestUser{}.SetId(token).(TestUser)}
+	if roles := c.GetHeader("X-Roles"); roles != "" {
+		user.Roles = strings.Split(roles, ",")
+	}
+	return user, nil
+}
+
+func TestApiRouter_Ownership(t *testing.T) {
+	getDB().AutoMigrate(&OwnedTest{})
+	getDB().Exec("DELETE FROM owned_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[OwnedTest](getDB()))
+	routes := route.DefaultApiRoutes()
+	routes[route.BatchGet] = route.Route{RouteType: route.BatchGet}
+	test_ := NewApiRouter(*repo, routes)
+	test_.AddFirewall(RoleFirewall{})
+	test_.RequireOwnership(security.Ownership{Roles: []string{"admin"}})
+	test_.AllowRoutes(r)
+
+	serve := func(method string, url string, user string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if user == "admin" {
+			req.Header.Set("Authorization", "3")
+			req.Header.Set("X-Roles", "admin")
+		} else if user != "" {
+			req.Header.Set("Authorization", user)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("POST", "/api/owned_test", "1", `{"id":1,"name":"mine"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("POST", "/api/owned_test", "2", `{"id":2,"name":"theirs","owner_id":1}`); w.Code != http.StatusCreated {
+		t.Fatalf("create failed %d %s", w.Code, w.Body.String())
+	}
+	if object, _ := repo.GetByID(2); object.OwnerId != 2 {
+		t.Fatalf("the user should be stamped as owner, got %d", object.OwnerId)
+	}
+	if w := serve("POST", "/api/owned_test", "", `{"id":3}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous users cannot own entities, got %d", w.Code)
+	}
+
+	w := serve("GET", "/api/owned_test", "1", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"mine"`) || strings.Contains(w.Body.String(), `"theirs"`) {
+		t.Fatalf("unexpected list %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/owned_test/2", "1", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("someone else's entity should not be found, got %d", w.Code)
+	}
+	if w := serve("GET", "/api/owned_test?ids=1,2", "1", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("someone else's entity should not be found in batch, got %d", w.Code)
+	}
+	if w := serve("PUT", "/api/owned_test/2", "1", `{"name":"stolen"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("writing someone else's entity should be forbidden, got %d", w.Code)
+	}
+	if w := serve("PATCH", "/api/owned_test/1", "1", `{"owner_id":2}`); w.Code != http.StatusOK {
+		t.Fatalf("patch failed %d", w.Code)
+	}
+	if object, _ := repo.GetByID(1); object.OwnerId != 1 {
+		t.Fatalf("owners cannot give their entities away, got %d", object.OwnerId)
+	}
+	if w := serve("GET", "/api/owned_test", "", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous users own nothing, got %d", w.Code)
+	}
+
+	w = serve("GET", "/api/owned_test", "admin", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"mine"`) || !strings.Contains(w.Body.String(), `"theirs"`) {
+		t.Fatalf("admins should see every entity, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("PATCH", "/api/owned_test/2", "admin", `{"name":"moderated"}`); w.Code != http.StatusOK {
+		t.Fatalf("admins should write every entity, got %d", w.Code)
+	}
+	if object, _ := repo.GetByID(2); object.OwnerId != 2 || object.Name != "moderated" {
+		t.Fatalf("admins should keep the owner, got %+v", object)
+	}
+
+	//rows stored without owner cannot be claimed
+	unowned := OwnedTest{}
+	unowned.Id = 4
+	unowned.Name = "unowned"
+	repo.Create(&unowned)
+	if w := serve("PUT", "/api/owned_test/4", "1", `{"name":"claimed"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("entities without owner should not be writable, got %d", w.Code)
+	}
+	if w := serve("DELETE", "/api/owned_test/4", "1", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("entities without owner should not be found, got %d", w.Code)
+	}
+	if object, _ := repo.GetByID(4); object.OwnerId != 0 || object.Name != "unowned" {
+		t.Fatalf("entities without owner should be left untouched, got %+v", object)
+	}
+	if w := serve("PATCH", "/api/owned_test/4", "admin", `{"name":"moderated"}`); w.Code != http.StatusOK {
+		t.Fatalf("admins should write entities without owner, got %d", w.Code)
+	}
+}
@@ -0,0 +1,113 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/ratelimit"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+)
+
+func TestApiRouter_RateLimit(t *testing.T) {
+	getDB().AutoMigrate(&VotedTest{})
+	getDB().Exec("DELETE FROM voted_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[VotedTest](getDB()))
+	for i := 1; i <= 3; i++ {
+		repo.Create(&VotedTest{BaseEntity: entity.BaseEntity{Id: entity.ID(i)}})
+	}
+	routes := route.AllApiRoutes()
+	routes[route.Delete] = route.NewRoute(route.Delete, configuration.RateLimit(1, 60))
+	test_ := NewApiRouter(*repo, routes, configuration.RouteName("rate_test"))
+	test_.AddFirewall(RoleFirewall{})
+	test_.SetRateLimiter(ratelimit.NewLimiter(ratelimit.NewMemoryStore(),
+		ratelimit.SlidingWindow(3, time.Minute),
+		ratelimit.TokenBucket(2, time.Minute),
+		ratelimit.SlidingWindow(5, time.Minute),
+	))
+	test_.AllowRoutes(r)
+
+	serve := func(method, url, user, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if user != "" {
+			req.Header.Set("Authorization", user)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 3; i++ {
+		w := serve("GET", "/api/rate_test/1", "", "")
+		if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "3" || w.Header().Get("RateLimit-Remaining") != strconv.Itoa(2-i) {
+			t.Fatalf("read %d should be allowed, got %d %v", i, w.Code, w.Header())
+		}
+	}
+	w := serve("GET", "/api/rate_test", "", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" || w.Header().Get("RateLimit-Policy") != "3;w=60" {
+		t.Fatalf("the read budget should be exhausted, got %d %v", w.Code, w.Header())
+	}
+	if w := serve("GET", "/api/rate_test/1", "7", ""); w.Code != http.StatusOK {
+		t.Fatalf("authenticated users should have their own budget, got %d", w.Code)
+	}
+	if w := serve("PATCH", "/api/rate_test/1", "", `{"name":"patched"}`); w.Code != http.StatusOK && w.Code != http.StatusNoContent {
+		t.Fatalf("writes should have their own budget, got %d %s", w.Code, w.Body.String())
+	}
+
+	//batches cost their number of items
+	if w := serve("POST", "/api/rate_test", "7", `[{"id":4},{"id":5},{"id":6}]`); w.Code != http.StatusCreated {
+		t.Fatalf("a batch within the budget should be allowed, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/rate_test?ids=1,2,3", "7", ""); w.Code != http.StatusTooManyRequests || w.Header().Get("RateLimit-Remaining") != "2" {
+		t.Fatalf("a batch above the remaining budget should be rejected, got %d %v", w.Code, w.Header())
+	}
+	if w := serve("GET", "/api/rate_test?ids=1,2", "7", ""); w.Code != http.StatusOK {
+		t.Fatalf("a batch within the remaining budget should be allowed, got %d", w.Code)
+	}
+
+	//routes can be given their own budget
+	if w := serve("DELETE", "/api/rate_test/4", "7", ""); w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Limit") != "1" {
+		t.Fatalf("the delete budget should allow one request, got %d %v", w.Code, w.Header())
+	}
+	if w := serve("DELETE", "/api/rate_test/5", "7", ""); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("the delete budget should be exhausted, got %d", w.Code)
+	}
+	if w := serve("PUT", "/api/rate_test/5", "7", `{"id":5}`); w.Code == http.StatusTooManyRequests {
+		t.Fatal("the write budget should not be spent by the delete route")
+	}
+}
+
+func TestApiRouter_RateLimitSubresource(t *testing.T) {
+	getDB().AutoMigrate(&Resource{}, &SubResource{})
+	r := SetupRouter()
+
+	resourceRouter := NewApiRouter(*orm.NewORM(gormrepository.NewRepository[Resource](getDB())), route.DefaultApiRoutes(), configuration.RouteName("rate_resource"))
+	subResourceRouter := NewApiRouter(*orm.NewORM(gormrepository.NewRepository[SubResource](getDB())), route.DefaultApiRoutes())
+	subResourceRouter.SetRateLimiter(ratelimit.NewLimiter(ratelimit.NewMemoryStore(),
+		ratelimit.SlidingWindow(1, time.Minute),
+		ratelimit.SlidingWindow(1, time.Minute),
+		ratelimit.SlidingWindow(1, time.Minute),
+	))
+	resourceRouter.AddSubresource(subResourceRouter)
+	resourceRouter.AllowRoutes(r)
+
+	for i, expected := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/rate_resource/1/sub_resource", nil)
+		r.ServeHTTP(w, req)
+		if w.Code != expected {
+			t.Fatalf("read %d of the subresource should answer %d, got %d", i, expected, w.Code)
+		}
+	}
+}
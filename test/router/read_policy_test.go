@@ -0,0 +1,108 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/format"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+	"github.com/philiphil/restman/serializer"
+)
+
+type PolicedTest struct {
+	entity.BaseEntity
+	Private bool `json:"private"`
+}
+
+func (e PolicedTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+
+func (t PolicedTest) ToEntity() PolicedTest {
+	return t
+}
+
+func (t PolicedTest) FromEntity(entity PolicedTest) any {
+	return entity
+}
+
+// GetReadPolicy lets admins read everything, and everyone else the public entities only
+func (e PolicedTest) GetReadPolicy() security.ReadPolicyFunction {
+	return func(user security.User) ([]orm.Condition, error) {
+		if security.HasRole(user, "ROLE_ADMIN") {
+			return nil, nil
+		}
+		return []orm.Condition{orm.Where("Private", false)}, nil
+	}
+}
+
+func TestApiRouter_ReadPolicy(t *testing.T) {
+	getDB().AutoMigrate(&PolicedTest{})
+	getDB().Exec("DELETE FROM policed_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[PolicedTest](getDB()))
+	routes := route.DefaultApiRoutes()
+	routes[route.BatchGet] = route.Route{RouteType: route.BatchGet}
+	test_ := NewApiRouter(*repo, routes)
+	test_.AddFirewall(RoleFirewall{})
+	test_.AllowRoutes(r)
+
+	for i := 1; i <= 10; i++ {
+		repo.Create(&PolicedTest{BaseEntity: entity.BaseEntity{Id: entity.ID(i)}, Private: i > 4})
+	}
+
+	serve := func(url string, roles string, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("Authorization", "1")
+		if roles != "" {
+			req.Header.Set("X-Roles", roles)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/api/policed_test", "", "")
+	if w.Code != http.StatusOK || strings.Count(w.Body.String(), `"private": false`) != 4 || strings.Contains(w.Body.String(), `"private": true`) {
+		t.Fatalf("private entities should not be listed, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = serve("/api/policed_test?pagination=true&itemsPerPage=3&page=1", "", "application/ld+json")
+	collection := map[string]any{}
+	serializer.NewSerializer(format.JSONLD).Deserialize(w.Body.String(), &collection)
+	if last := collection["hydra:view"].(map[string]any)["hydra:last"]; last != "/api/policed_test?page=2" {
+		t.Fatalf("pages should be counted on readable entities only, got %v", last)
+	}
+
+	if w := serve("/api/policed_test/5", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("a private entity should not be found, got %d", w.Code)
+	}
+	if w := serve("/api/policed_test?ids=1,5", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("a private entity should not be found in batch, got %d", w.Code)
+	}
+
+	w = serve("/api/policed_test", "ROLE_ADMIN", "")
+	if w.Code != http.StatusOK || strings.Count(w.Body.String(), `"private": true`) != 6 {
+		t.Fatalf("admins should read everything, got %d %s", w.Code, w.Body.String())
+	}
+
+	test_.SetReadPolicy(func(user security.User) ([]orm.Condition, error) {
+		return []orm.Condition{orm.WhereIn("id", []int{1, 2, 5})}, nil
+	})
+	w = serve("/api/policed_test", "", "")
+	if w.Code != http.StatusOK || strings.Count(w.Body.String(), `"private": false`) != 2 || strings.Contains(w.Body.String(), `"private": true`) {
+		t.Fatalf("router and entity policies should both apply, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,318 @@
+package router_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/cache"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+type CachedTest struct {
+	entity.BaseEntity
+}
+
+func (e CachedTest) GetId() entity.ID {
+	return e.Id
+}
+
+func (e CachedTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+func (t CachedTest) ToEntity() CachedTest {
+	return t
+}
+
+func (t CachedTest) FromEntity(entity CachedTest) any {
+	return entity
+}
+
+type PrivateCachedTest struct {
+	entity.BaseEntity
+}
+
+func (e PrivateCachedTest) GetId() entity.ID {
+	return e.Id
+}
+
+func (e PrivateCachedTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+func (t PrivateCachedTest) ToEntity() PrivateCachedTest {
+	return t
+}
+
+func (t PrivateCachedTest) FromEntity(entity PrivateCachedTest) any {
+	return entity
+}
+
+func (e PrivateCachedTest) GetReadingRights() security.AuthorizationFunction {
+	return func(user security.User, object entity.Entity) bool {
+		return user != nil && user.GetIdentifier() == entity.ID(1)
+	}
+}
+
+func TestApiRouter_ResponseCache(t *testing.T) {
+	getDB().AutoMigrate(&CachedTest{})
+	getDB().Exec("DELETE FROM cached_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[CachedTest](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes())
+	test_.SetResponseCache(cache.NewMemoryResponseCache(0))
+	test_.AllowRoutes(r)
+
+	object := CachedTest{entity.BaseEntity{Id: 1, Name: "first"}}
+	repo.Create(&object)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/cached_test/1", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "first") {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/cached_test", nil)
+	r.ServeHTTP(w, req)
+
+	//bypass the router, the cached response must still be served
+	object.Name = "second"
+	repo.Update(&object)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/cached_test/1", nil)
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "first") {
+		t.Fatalf("expected the cached response, got %s", w.Body.String())
+	}
+
+	//a write through the router evicts the item and the collections
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PATCH", "/api/cached_test/1", bytes.NewReader([]byte(`{"name":"third"}`)))
+	req.Header.Add("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("patch failed %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/cached_test/1", nil)
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "third") {
+		t.Fatalf("expected a fresh response, got %s", w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/cached_test", nil)
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "third") {
+		t.Fatalf("expected a fresh collection, got %s", w.Body.String())
+	}
+
+	//format is part of the key
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/cached_test/1", nil)
+	req.Header.Add("Accept", "application/xml")
+	r.ServeHTTP(w, req)
+	if !strings.HasPrefix(w.Body.String(), "<") {
+		t.Fatalf("expected xml, got %s", w.Body.String())
+	}
+}
+
+func TestApiRouter_ResponseCache_Private(t *testing.T) {
+	getDB().AutoMigrate(&PrivateCachedTest{})
+	getDB().Exec("DELETE FROM private_cached_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[PrivateCachedTest](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes())
+	test_.SetResponseCache(cache.NewMemoryResponseCache(0))
+	test_.AddFirewall(TestFirewall{})
+	test_.AllowRoutes(r)
+
+	repo.Create(&PrivateCachedTest{entity.BaseEntity{Id: 1}})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/private_cached_test/1", nil)
+	req.Header.Add("Authorization", "1")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	//the response cached for user 1 must not be served to someone else
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/private_cached_test/1", nil)
+	req.Header.Add("Authorization", "2")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/private_cached_test/1", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
+
+func TestApiRouter_ResponseCache_Broadcast(t *testing.T) {
+	getDB().AutoMigrate(&CachedTest{})
+	getDB().Exec("DELETE FROM cached_tests")
+	repo := orm.NewORM(gormrepository.NewRepository[CachedTest](getDB()))
+	repo.Create(&CachedTest{entity.BaseEntity{Id: 1, Name: "first"}})
+
+	//two nodes sharing a database, each with its own in-memory cache
+	bus := cache.NewInProcessInvalidationBus()
+	nodes := make([]http.Handler, 2)
+	for i := range nodes {
+		r := SetupRouter()
+		node := NewApiRouter(*repo, route.DefaultApiRoutes())
+		responseCache, err := cache.NewBroadcastResponseCache(cache.NewMemoryResponseCache(0), bus)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		node.SetResponseCache(responseCache)
+		node.AllowRoutes(r)
+		nodes[i] = r
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/cached_test/1", nil)
+	nodes[1].ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "first") {
+		t.Fatalf("unexpected response %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PATCH", "/api/cached_test/1", bytes.NewReader([]byte(`{"name":"second"}`)))
+	req.Header.Add("Content-Type", "application/json")
+	nodes[0].ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("patch failed %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/cached_test/1", nil)
+	nodes[1].ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "second") {
+		t.Fatalf("node 1 should have applied the eviction, got %s", w.Body.String())
+	}
+}
+
+// countingFirewall counts the authentications
+type countingFirewall struct {
+	RoleFirewall
+	calls *int
+}
+
+func (f countingFirewall) GetUser(c *gin.Context) (security.User, error) {
+	*f.calls++
+	return f.RoleFirewall.GetUser(c)
+}
+
+// taggedResponseCache records the invalidated tags
+type taggedResponseCache struct {
+	cache.ResponseCache
+	invalidated []string
+}
+
+func (c *taggedResponseCache) Invalidate(tags ...string) error {
+	c.invalidated = append(c.invalidated, tags...)
+	return c.ResponseCache.Invalidate(tags...)
+}
+
+func TestApiRouter_ResponseCache_SingleAuthentication(t *testing.T) {
+	getDB().AutoMigrate(&PrivateCachedTest{})
+	getDB().Exec("DELETE FROM private_cached_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[PrivateCachedTest](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes())
+	calls := 0
+	test_.AddFirewall(countingFirewall{calls: &calls})
+	test_.SetResponseCache(cache.NewMemoryResponseCache(0))
+	test_.AllowRoutes(r)
+	repo.Create(&PrivateCachedTest{entity.BaseEntity{Id: 1, Name: "first"}})
+
+	for i := range 2 {
+		calls = 0
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/private_cached_test/1", nil)
+		req.Header.Set("Authorization", "1")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+		}
+		if calls != 1 {
+			t.Errorf("request %d authenticated %d times", i, calls)
+		}
+	}
+}
+
+func TestApiRouter_ResponseCache_Purge(t *testing.T) {
+	getDB().AutoMigrate(&TrashTest{})
+	getDB().Exec("DELETE FROM trash_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[TrashTest](getDB()))
+	test_ := NewApiRouter(*repo, route.SoftDeleteOperations())
+	responseCache := &taggedResponseCache{ResponseCache: cache.NewMemoryResponseCache(0)}
+	test_.SetResponseCache(responseCache)
+	test_.AllowRoutes(r)
+	object := TrashTest{entity.BaseEntity{Id: 1, Name: "purged"}}
+	repo.Create(&object)
+	repo.Delete(&object)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/api/trash_test/trash/1", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("purge failed %d %s", w.Code, w.Body.String())
+	}
+	if !slices.Contains(responseCache.invalidated, cache.ItemTag("TrashTest", "1")) || !slices.Contains(responseCache.invalidated, cache.CollectionTag("TrashTest")) {
+		t.Errorf("expected the purged item and the collections to be evicted, got %v", responseCache.invalidated)
+	}
+}
+
+func TestApiRouter_ResponseCacheAuthenticationRequired(t *testing.T) {
+	getDB().AutoMigrate(&CachedTest{})
+	getDB().Exec("DELETE FROM cached_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[CachedTest](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes(), configuration.RouteName("cached_required"), configuration.AuthenticationRequired(true))
+	test_.AddFirewall(RoleFirewall{})
+	test_.SetResponseCache(cache.NewMemoryResponseCache(0))
+	test_.AllowRoutes(r)
+	repo.Create(&CachedTest{entity.BaseEntity{Id: 1, Name: "first"}})
+
+	for _, step := range []struct {
+		user string
+		code int
+	}{
+		{"", http.StatusUnauthorized},
+		{"1", http.StatusOK},
+		{"", http.StatusUnauthorized},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/cached_required/1", nil)
+		if step.user != "" {
+			req.Header.Set("Authorization", step.user)
+		}
+		r.ServeHTTP(w, req)
+		if w.Code != step.code {
+			t.Fatalf("expected %d for user %q, got %d", step.code, step.user, w.Code)
+		}
+	}
+}
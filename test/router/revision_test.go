@@ -0,0 +1,155 @@
+package router_test
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/revision"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+func TestApiRouter_Revisions(t *testing.T) {
+	getDB().AutoMigrate(&VotedTest{}, &revision.Revision{})
+	getDB().Exec("DELETE FROM voted_tests")
+	getDB().Exec("DELETE FROM revisions")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[VotedTest](getDB()))
+	routes := route.DefaultApiRoutes()
+	maps.Copy(routes, route.RevisionOperations())
+	routes[route.Revert] = route.NewRoute(route.Revert, configuration.Security("ROLE_EDITOR"))
+	test_ := NewApiRouter(*repo, routes, configuration.RouteName("revision_test"))
+	test_.AddFirewall(RoleFirewall{})
+	test_.SetRevisionStore(revision.NewORMStore(orm.NewORM(gormrepository.NewRepository[revision.Revision](getDB()))))
+	test_.AllowRoutes(r)
+
+	serve := func(method, url, roles, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "7")
+		req.Header.Set("X-Roles", roles)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("POST", "/api/revision_test", "", `{"id":1,"name":"first"}`); w.Code != http.StatusCreated {
+		t.Fatalf("post failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("PATCH", "/api/revision_test/1", "", `{"name":"second"}`); w.Code != http.StatusOK {
+		t.Fatalf("patch failed %d %s", w.Code, w.Body.String())
+	}
+
+	w := serve("GET", "/api/revision_test/1/revisions", "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("listing revisions failed %d %s", w.Code, w.Body.String())
+	}
+	var revisions []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &revisions); err != nil || len(revisions) != 2 {
+		t.Fatalf("every write should be a revision, got %s", w.Body.String())
+	}
+	if revisions[0]["revision"] != 1.0 || revisions[0]["operation"] != "create" || revisions[1]["operation"] != "update" || revisions[1]["user"] != "7" {
+		t.Fatalf("unexpected revisions %s", w.Body.String())
+	}
+
+	w = serve("GET", "/api/revision_test/1/revisions/1", "", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"first"`) {
+		t.Fatalf("the first revision should be returned, got %d %s", w.Code, w.Body.String())
+	}
+	if w = serve("GET", "/api/revision_test/1/revisions/9", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown revisions should not be found, got %d", w.Code)
+	}
+	if w = serve("GET", "/api/revision_test/1/revisions/first", "", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("revisions are numbered, got %d", w.Code)
+	}
+	if w = serve("GET", "/api/revision_test/2/revisions", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("revisions of unknown entities should not be found, got %d", w.Code)
+	}
+
+	if w = serve("POST", "/api/revision_test/1/revisions/1/revert", "", ""); w.Code != http.StatusForbidden {
+		t.Fatalf("reverts should be secured like any route, got %d", w.Code)
+	}
+	w = serve("POST", "/api/revision_test/1/revisions/1/revert", "ROLE_EDITOR", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"first"`) {
+		t.Fatalf("revert failed %d %s", w.Code, w.Body.String())
+	}
+	reverted, err := repo.GetByID(1)
+	if err != nil || reverted.Name != "first" {
+		t.Fatalf("the entity should be reverted, got %+v %v", reverted, err)
+	}
+
+	if w = serve("DELETE", "/api/revision_test/1", "", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete failed %d", w.Code)
+	}
+	stored, err := revision.NewORMStore(orm.NewORM(gormrepository.NewRepository[revision.Revision](getDB()))).List("VotedTest", "1")
+	if err != nil || len(stored) != 4 || stored[2].Operation != "revert" || stored[3].Operation != "delete" || stored[3].Number != 4 {
+		t.Fatalf("the revert and the deletion should be revisions, got %+v %v", stored, err)
+	}
+}
+
+func TestApiRouter_RevertFieldSecurity(t *testing.T) {
+	getDB().AutoMigrate(&FieldSecurityTest{}, &revision.Revision{})
+	getDB().Exec("DELETE FROM field_security_tests")
+	getDB().Exec("DELETE FROM revisions")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[FieldSecurityTest](getDB()))
+	routes := route.DefaultApiRoutes()
+	maps.Copy(routes, route.RevisionOperations())
+	test_ := NewApiRouter(*repo, routes,
+		configuration.RouteName("revert_security_test"),
+		configuration.InputSerializationGroups("write"),
+		configuration.OutputSerializationGroups("read"),
+	)
+	test_.AddFirewall(RoleFirewall{})
+	test_.SetGroupResolver(func(c *gin.Context, context GroupContext) []string {
+		if context.Input && security.HasRole(context.User, "ROLE_ADMIN") {
+			return append(context.Groups, "admin_write")
+		}
+		return context.Groups
+	})
+	test_.SetRevisionStore(revision.NewORMStore(orm.NewORM(gormrepository.NewRepository[revision.Revision](getDB()))))
+	test_.AllowRoutes(r)
+
+	serve := func(method, url, roles, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "7")
+		req.Header.Set("X-Roles", roles)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	repo.Create(&FieldSecurityTest{BaseEntity: entity.BaseEntity{Id: 1}, Title: "t", Role: "user"})
+	if w := serve("PATCH", "/api/revert_security_test/1", "ROLE_ADMIN", `{"title":"first"}`); w.Code != http.StatusOK {
+		t.Fatalf("patch failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("PATCH", "/api/revert_security_test/1", "ROLE_ADMIN", `{"title":"second","role":"admin"}`); w.Code != http.StatusOK {
+		t.Fatalf("patch failed %d %s", w.Code, w.Body.String())
+	}
+
+	if w := serve("POST", "/api/revert_security_test/1/revisions/1/revert", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("revert failed %d %s", w.Code, w.Body.String())
+	}
+	if reverted, _ := repo.GetByID(1); reverted.Title != "first" || reverted.Role != "admin" {
+		t.Fatalf("only the fields the user may write should be reverted, got %+v", reverted)
+	}
+	if w := serve("POST", "/api/revert_security_test/1/revisions/1/revert", "ROLE_ADMIN", ""); w.Code != http.StatusOK {
+		t.Fatalf("revert failed %d %s", w.Code, w.Body.String())
+	}
+	if reverted, _ := repo.GetByID(1); reverted.Role != "user" {
+		t.Fatalf("admins should revert the role, got %+v", reverted)
+	}
+}
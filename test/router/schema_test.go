@@ -0,0 +1,87 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/jsonschema"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+)
+
+func TestSchemas(t *testing.T) {
+	test_ := NewApiRouter(
+		*orm.NewORM(gormrepository.NewRepository[FieldSecurityTest](getDB())),
+		route.DefaultApiRoutes(),
+		configuration.InputSerializationGroups("write"),
+		configuration.OutputSerializationGroups("read"),
+	)
+	schemas := NewSchemas(test_)
+	r := SetupRouter()
+	schemas.AllowRoutes(r)
+
+	get := func(url string) (int, *jsonschema.Schema) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		var schema jsonschema.Schema
+		if w.Code == http.StatusOK {
+			if w.Header().Get("Content-Type") != "application/schema+json" {
+				t.Errorf("unexpected content type %s", w.Header().Get("Content-Type"))
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &schema); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w.Code, &schema
+	}
+
+	code, schema := get("/api/schemas/field_security_test")
+	if code != http.StatusOK || schema.Schema != jsonschema.Draft || schema.Ref != "#/$defs/FieldSecurityTest-read" || schema.Title != "FieldSecurityTest" {
+		t.Fatalf("unexpected schema %d %+v", code, schema)
+	}
+	read := schema.Defs["FieldSecurityTest-read"]
+	if _, ok := read.Properties["title"]; !ok {
+		t.Error("expected the read field")
+	}
+	if _, ok := read.Properties["email"]; ok {
+		t.Error("expected the admin field to be excluded")
+	}
+
+	_, schema = get("/api/schemas/field_security_test?groups=read,admin")
+	if _, ok := schema.Defs["FieldSecurityTest-admin_read"].Properties["email"]; !ok {
+		t.Errorf("expected the admin field with the admin group, got %v", schema.Defs)
+	}
+
+	_, schema = get("/api/schemas/field_security_test?direction=input")
+	input := schema.Defs["FieldSecurityTestInput-write"]
+	if input == nil || input.Required != nil {
+		t.Errorf("unexpected input schema %+v", input)
+	}
+	if _, ok := input.Properties["role"]; ok {
+		t.Error("expected the admin_write field to be excluded")
+	}
+
+	if code, _ = get("/api/schemas/field_security_test?direction=sideways"); code != http.StatusBadRequest {
+		t.Errorf("expected bad request, got %d", code)
+	}
+	if code, _ = get("/api/schemas/unknown"); code != http.StatusNotFound {
+		t.Errorf("expected not found, got %d", code)
+	}
+
+	dir := t.TempDir()
+	if err := schemas.Export(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"field_security_test.json", "field_security_test.input.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be exported: %v", name, err)
+		}
+	}
+}
@@ -40,13 +40,13 @@ func (t ProtectedEntity) FromEntity(entity ProtectedEntity) any {
 }
 func (e ProtectedEntity) GetReadingRights() security.AuthorizationFunction {
 	return func(i1 security.User, i2 entity.Entity) bool {
-		return i1.GetId() == 1
+		return i1.GetIdentifier() == entity.ID(1)
 	}
 }
 
 func (e ProtectedEntity) GetWritingRights() security.AuthorizationFunction {
 	return func(i1 security.User, i2 entity.Entity) bool {
-		return i1.GetId() == 1
+		return i1.GetIdentifier() == entity.ID(1)
 	}
 }
 
@@ -0,0 +1,107 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+func TestApiRouter_SessionFirewall(t *testing.T) {
+	getDB().AutoMigrate(&CredentialUser{}, &VotedTest{})
+	getDB().Exec("DELETE FROM credential_users")
+	getDB().Exec("DELETE FROM voted_tests")
+	r := SetupRouter()
+
+	users := orm.NewORM(gormrepository.NewRepository[CredentialUser](getDB()))
+	hash, _ := security.BcryptHasher{Cost: 4}.Hash("s3cret")
+	users.Create(&CredentialUser{BaseEntity: entity.BaseEntity{Id: 1}, Username: "alice", PasswordHash: hash})
+
+	sessions := security.NewSessionFirewall(security.NewMemorySessionStore(), security.NewORMUserProvider(users, "username"))
+	sessions.Insecure = true
+	AllowSessionRoutes(r, sessions, "/api")
+
+	repo := orm.NewORM(gormrepository.NewRepository[VotedTest](getDB()))
+	routes := route.DefaultApiRoutes()
+	routes[route.GetList] = route.NewRoute(route.GetList, configuration.Security("IS_AUTHENTICATED"))
+	test_ := NewApiRouter(*repo, routes, configuration.RouteName("session_test"))
+	test_.AddFirewall(sessions)
+	test_.AllowRoutes(r)
+
+	var cookies []*http.Cookie
+	serve := func(method, url, body, csrf string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		if csrf != "" {
+			req.Header.Set(security.DefaultCSRFHeader, csrf)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("POST", "/api/login", `{"username":"alice","password":"wrong"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("a wrong password should be rejected, got %d", w.Code)
+	}
+	if w := serve("GET", "/api/session_test", "", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous users should be rejected, got %d", w.Code)
+	}
+
+	w := serve("POST", "/api/login", `{"username":"alice","password":"s3cret"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login failed %d %s", w.Code, w.Body.String())
+	}
+	var login struct {
+		CSRFToken string `json:"csrfToken"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &login)
+	cookies = w.Result().Cookies()
+	csrfCookie := ""
+	for _, cookie := range cookies {
+		if cookie.Name == security.DefaultSessionCookie && !cookie.HttpOnly {
+			t.Fatal("the session cookie must not be readable by scripts")
+		}
+		if cookie.Name == security.DefaultCSRFCookie {
+			csrfCookie = cookie.Value
+		}
+	}
+	if login.CSRFToken == "" || csrfCookie != login.CSRFToken {
+		t.Fatalf("the CSRF token should be answered and set in a cookie, got %q and %q", login.CSRFToken, csrfCookie)
+	}
+
+	if w := serve("GET", "/api/session_test", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("the session should authenticate reads, got %d", w.Code)
+	}
+	//the route has no security expression, writes are still protected against CSRF
+	if w := serve("POST", "/api/session_test", `{"id":1}`, ""); w.Code != http.StatusForbidden {
+		t.Fatalf("a write without CSRF token should be forbidden, got %d", w.Code)
+	}
+	if w := serve("POST", "/api/session_test", `{"id":1}`, "forged"); w.Code != http.StatusForbidden {
+		t.Fatalf("a write with a wrong CSRF token should be forbidden, got %d", w.Code)
+	}
+	if w := serve("POST", "/api/session_test", `{"id":1}`, login.CSRFToken); w.Code != http.StatusCreated {
+		t.Fatalf("a write with the CSRF token should succeed, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w := serve("POST", "/api/logout", "", ""); w.Code != http.StatusForbidden {
+		t.Fatalf("logout requires the CSRF token, got %d", w.Code)
+	}
+	if w := serve("POST", "/api/logout", "", login.CSRFToken); w.Code != http.StatusNoContent {
+		t.Fatalf("logout failed %d", w.Code)
+	}
+	if w := serve("GET", "/api/session_test", "", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("the closed session should no longer authenticate, got %d", w.Code)
+	}
+}
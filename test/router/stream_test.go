@@ -0,0 +1,145 @@
+package router_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+)
+
+type streamedEvent struct {
+	id, event, data string
+}
+
+// subscribe opens a stream and returns its events
+func subscribe(t *testing.T, ctx context.Context, url string, headers map[string]string) <-chan streamedEvent {
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected stream response %d %v", resp.StatusCode, resp.Header)
+	}
+	events := make(chan streamedEvent, 16)
+	go func() {
+		defer resp.Body.Close()
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var current streamedEvent
+		for scanner.Scan() {
+			key, value, _ := strings.Cut(scanner.Text(), ": ")
+			switch key {
+			case "id":
+				current.id = value
+			case "event":
+				current.event = value
+			case "data":
+				current.data = value
+			case "":
+				if current.event != "" {
+					events <- current
+				}
+				current = streamedEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+func next(t *testing.T, events <-chan streamedEvent) streamedEvent {
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+	}
+	return streamedEvent{}
+}
+
+func TestApiRouter_Stream(t *testing.T) {
+	getDB().AutoMigrate(&PolicedTest{})
+	getDB().Exec("DELETE FROM policed_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[PolicedTest](getDB()))
+	routes := route.DefaultApiRoutes()
+	routes[route.Stream] = route.NewRoute(route.Stream)
+	routes[route.ItemStream] = route.NewRoute(route.ItemStream)
+	test_ := NewApiRouter(*repo, routes, configuration.RouteName("stream_test"))
+	test_.AddFirewall(RoleFirewall{})
+	test_.EnableStreaming(10)
+	test_.AllowRoutes(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	write := func(method, url, body string) {
+		req, _ := http.NewRequest(method, server.URL+url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "1")
+		req.Header.Set("X-Roles", "ROLE_ADMIN")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil || resp.StatusCode >= 300 {
+			t.Fatalf("%s %s failed %v %v", method, url, resp, err)
+		}
+		resp.Body.Close()
+	}
+
+	admin := subscribe(t, ctx, server.URL+"/api/stream_test/stream", map[string]string{"Authorization": "1", "X-Roles": "ROLE_ADMIN"})
+	user := subscribe(t, ctx, server.URL+"/api/stream_test/stream", map[string]string{"Authorization": "2"})
+	write("POST", "/api/stream_test", `{"id":1,"name":"public"}`)
+	write("POST", "/api/stream_test", `{"id":2,"name":"secret","private":true}`)
+
+	first := next(t, admin)
+	if first.event != "create" || !strings.Contains(first.data, `"public"`) || next(t, admin).event != "create" {
+		t.Fatalf("unexpected events %+v", first)
+	}
+	if e := next(t, user); !strings.Contains(e.data, `"public"`) {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	item := subscribe(t, ctx, server.URL+"/api/stream_test/1/stream", map[string]string{"Authorization": "2"})
+	write("PATCH", "/api/stream_test/2", `{"name":"still secret"}`)
+	write("PATCH", "/api/stream_test/1", `{"name":"renamed"}`)
+	write("DELETE", "/api/stream_test/1", "")
+	if e := next(t, user); e.event != "update" || !strings.Contains(e.data, `"renamed"`) {
+		t.Fatalf("users should only receive the changes they may read, got %+v", e)
+	}
+	if e := next(t, item); e.event != "update" || !strings.Contains(e.data, `"renamed"`) {
+		t.Fatalf("item streams should only receive the changes of their entity, got %+v", e)
+	}
+	if e := next(t, item); e.event != "delete" || !strings.Contains(e.data, `"renamed"`) {
+		t.Fatalf("deleted entities should be sent as they were, got %+v", e)
+	}
+
+	resumed := subscribe(t, ctx, server.URL+"/api/stream_test/stream", map[string]string{"Authorization": "1", "X-Roles": "ROLE_ADMIN", "Last-Event-ID": first.id})
+	var replayed []string
+	for range 4 {
+		replayed = append(replayed, next(t, resumed).event)
+	}
+	if strings.Join(replayed, ",") != "create,update,update,delete" {
+		t.Fatalf("the missed events should be replayed, got %v", replayed)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/stream_test/2/stream", nil)
+	req.Header.Set("Authorization", "2")
+	r.ServeHTTP(w, req)
+	if w.Code == http.StatusOK {
+		t.Fatal("entities that cannot be read cannot be streamed")
+	}
+}
@@ -154,3 +154,44 @@ func TestApiRouter_NestedSubResources(t *testing.T) {
 		t.Error("Expected nested subresource route to be registered")
 	}
 }
+
+// legacyRegistrar only implements SubresourceRegistrar
+type legacyRegistrar struct {
+	parentRoute *string
+}
+
+func (l legacyRegistrar) RegisterSubroutes(router *gin.Engine, parentRoute string) {
+	*l.parentRoute = parentRoute
+}
+
+func (l legacyRegistrar) GetSubresourceName() string {
+	return "legacy"
+}
+
+func TestApiRouter_RegisterSubroutesCollectionRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	subResourceRouter := NewApiRouter(
+		*orm.NewORM(gormrepository.NewRepository[SubResource](getDB())),
+		route.DefaultApiRoutes(),
+	)
+	subResourceRouter.RegisterSubroutes(router, "/api/resource")
+	found := false
+	for _, r := range router.Routes() {
+		found = found || r.Path == "/api/resource/:id/sub_resource/:id" && r.Method == "GET"
+	}
+	if !found {
+		t.Error("RegisterSubroutes should append the id parameter to the collection route of the parent")
+	}
+
+	parentRoute := ""
+	resourceRouter := NewApiRouter(
+		*orm.NewORM(gormrepository.NewRepository[Resource](getDB())),
+		route.DefaultApiRoutes(),
+	)
+	resourceRouter.AddSubresource(legacyRegistrar{parentRoute: &parentRoute})
+	resourceRouter.AllowRoutes(gin.New())
+	if parentRoute != "/api/resource" {
+		t.Errorf("registrars should receive the collection route of their parent, got %s", parentRoute)
+	}
+}
@@ -0,0 +1,98 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+type TenantTest struct {
+	entity.BaseEntity
+	TenantId string `json:"tenant_id"`
+}
+
+func (e TenantTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+
+func (e TenantTest) GetTenantId() string {
+	return e.TenantId
+}
+
+func (e TenantTest) SetTenantId(tenant string) entity.Entity {
+	e.TenantId = tenant
+	return e
+}
+
+func (t TenantTest) ToEntity() TenantTest {
+	return t
+}
+
+func (t TenantTest) FromEntity(entity TenantTest) any {
+	return entity
+}
+
+func TestApiRouter_Tenancy(t *testing.T) {
+	getDB().AutoMigrate(&TenantTest{})
+	getDB().Exec("DELETE FROM tenant_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[TenantTest](getDB()))
+	test_ := NewApiRouter(*repo, route.DefaultApiRoutes())
+	test_.SetTenancy(security.HeaderTenantResolver{}, "TenantId")
+	test_.AllowRoutes(r)
+
+	serve := func(method string, url string, tenant string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if tenant != "" {
+			req.Header.Set(security.DefaultTenantHeader, tenant)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("POST", "/api/tenant_test", "acme", `{"id":1,"name":"a","tenant_id":"globex"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("POST", "/api/tenant_test", "globex", `{"id":2,"name":"b"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create failed %d %s", w.Code, w.Body.String())
+	}
+	object, err := repo.GetByID(1)
+	if err != nil || object.TenantId != "acme" {
+		t.Fatalf("the tenant of the request should be stamped, got %+v %v", object, err)
+	}
+
+	w := serve("GET", "/api/tenant_test", "acme", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"a"`) || strings.Contains(w.Body.String(), `"b"`) {
+		t.Fatalf("unexpected list %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/tenant_test/2", "acme", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("another tenant's entity should not be found, got %d", w.Code)
+	}
+	if w := serve("GET", "/api/tenant_test/2", "globex", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if w := serve("PUT", "/api/tenant_test/2", "acme", `{"name":"stolen"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("cross tenant write should be forbidden, got %d", w.Code)
+	}
+	if w := serve("DELETE", "/api/tenant_test/2", "acme", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("cross tenant delete should not be found, got %d", w.Code)
+	}
+	if object, _ := repo.GetByID(2); object.Name != "b" {
+		t.Fatalf("another tenant's entity should be untouched, got %+v", object)
+	}
+	if w := serve("GET", "/api/tenant_test", "", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("a request without tenant should be rejected, got %d", w.Code)
+	}
+}
@@ -0,0 +1,213 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+)
+
+type TrashTest struct {
+	entity.BaseEntity
+}
+
+func (e TrashTest) GetId() entity.ID {
+	return e.Id
+}
+
+func (e TrashTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+func (t TrashTest) ToEntity() TrashTest {
+	return t
+}
+
+func (t TrashTest) FromEntity(entity TrashTest) any {
+	return entity
+}
+
+// GuardedTrashTest can be restored by user 1 only and never purged
+type GuardedTrashTest struct {
+	entity.BaseEntity
+}
+
+func (e GuardedTrashTest) GetId() entity.ID {
+	return e.Id
+}
+
+func (e GuardedTrashTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+func (t GuardedTrashTest) ToEntity() GuardedTrashTest {
+	return t
+}
+
+func (t GuardedTrashTest) FromEntity(entity GuardedTrashTest) any {
+	return entity
+}
+
+func (e GuardedTrashTest) GetRestoringRights() security.AuthorizationFunction {
+	return func(user security.User, object entity.Entity) bool {
+		return user != nil && user.GetIdentifier() == entity.ID(1)
+	}
+}
+
+func (e GuardedTrashTest) GetPurgingRights() security.AuthorizationFunction {
+	return func(user security.User, object entity.Entity) bool {
+		return false
+	}
+}
+
+// HardDeletedTest has no gorm.DeletedAt field
+type HardDeletedTest struct {
+	Id   entity.ID `json:"id"`
+	Name string    `json:"name"`
+}
+
+func (e HardDeletedTest) GetIdentifier() entity.Identifier {
+	return e.Id
+}
+
+func (e HardDeletedTest) SetId(id any) entity.Entity {
+	e.Id = entity.CastId(id)
+	return e
+}
+func (t HardDeletedTest) ToEntity() HardDeletedTest {
+	return t
+}
+
+func (t HardDeletedTest) FromEntity(entity HardDeletedTest) any {
+	return entity
+}
+
+func TestApiRouter_Trash(t *testing.T) {
+	getDB().AutoMigrate(&TrashTest{})
+	getDB().Exec("DELETE FROM trash_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[TrashTest](getDB()))
+	routes := route.DefaultApiRoutes()
+	for k, v := range route.SoftDeleteOperations() {
+		routes[k] = v
+	}
+	test_ := NewApiRouter(*repo, routes)
+	test_.AllowRoutes(r)
+
+	repo.Create(&TrashTest{entity.BaseEntity{Id: 1}}, &TrashTest{entity.BaseEntity{Id: 2}}, &TrashTest{entity.BaseEntity{Id: 3}})
+
+	serve := func(method string, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("DELETE", "/api/trash_test/1"); w.Code != http.StatusNoContent {
+		t.Fatalf("delete failed %d", w.Code)
+	}
+	w := serve("GET", "/api/trash_test/trash")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id": 1`) || strings.Contains(w.Body.String(), `"id": 2`) {
+		t.Fatalf("unexpected trash %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/trash_test/1"); w.Code != http.StatusNotFound {
+		t.Fatalf("trashed entity should not be found, got %d", w.Code)
+	}
+
+	if w := serve("POST", "/api/trash_test/trash/1/restore"); w.Code != http.StatusOK {
+		t.Fatalf("restore failed %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/api/trash_test/1"); w.Code != http.StatusOK {
+		t.Fatalf("restored entity should be found, got %d", w.Code)
+	}
+	if w := serve("POST", "/api/trash_test/trash/1/restore"); w.Code != http.StatusNotFound {
+		t.Fatalf("restoring a live entity should fail, got %d", w.Code)
+	}
+
+	serve("DELETE", "/api/trash_test/2")
+	serve("DELETE", "/api/trash_test/3")
+	if w := serve("POST", "/api/trash_test/trash/restore?ids=2,3"); w.Code != http.StatusOK {
+		t.Fatalf("batch restore failed %d %s", w.Code, w.Body.String())
+	}
+	if count, _ := repo.Count(); count != 3 {
+		t.Fatalf("expected 3 live entities, got %d", count)
+	}
+
+	serve("DELETE", "/api/trash_test/2")
+	serve("DELETE", "/api/trash_test/3")
+	if w := serve("DELETE", "/api/trash_test/trash/2"); w.Code != http.StatusNoContent {
+		t.Fatalf("purge failed %d", w.Code)
+	}
+	if w := serve("DELETE", "/api/trash_test/trash?ids=3"); w.Code != http.StatusNoContent {
+		t.Fatalf("batch purge failed %d", w.Code)
+	}
+	if count, _ := repo.CountDeleted(); count != 0 {
+		t.Fatalf("trash should be empty, got %d", count)
+	}
+	if w := serve("POST", "/api/trash_test/trash/2/restore"); w.Code != http.StatusNotFound {
+		t.Fatalf("purged entity cannot be restored, got %d", w.Code)
+	}
+}
+
+func TestApiRouter_Trash_Security(t *testing.T) {
+	getDB().AutoMigrate(&GuardedTrashTest{})
+	getDB().Exec("DELETE FROM guarded_trash_tests")
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[GuardedTrashTest](getDB()))
+	test_ := NewApiRouter(*repo, route.SoftDeleteOperations())
+	test_.AddFirewall(TestFirewall{})
+	test_.AllowRoutes(r)
+
+	object := GuardedTrashTest{entity.BaseEntity{Id: 1}}
+	repo.Create(&object)
+	repo.Delete(&object)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/guarded_trash_test/trash/1/restore", nil)
+	req.Header.Add("Authorization", "2")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/guarded_trash_test/trash/1", nil)
+	req.Header.Add("Authorization", "1")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/guarded_trash_test/trash/1/restore", nil)
+	req.Header.Add("Authorization", "1")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestApiRouter_Trash_Unsupported(t *testing.T) {
+	getDB().AutoMigrate(&HardDeletedTest{})
+	r := SetupRouter()
+
+	repo := orm.NewORM(gormrepository.NewRepository[HardDeletedTest](getDB()))
+	test_ := NewApiRouter(*repo, route.SoftDeleteOperations())
+	test_.AllowRoutes(r)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/hard_deleted_test/trash", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d", w.Code)
+	}
+}
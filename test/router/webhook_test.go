@@ -0,0 +1,80 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/webhook"
+)
+
+func TestApiRouter_Webhooks(t *testing.T) {
+	getDB().AutoMigrate(&VotedTest{}, &webhook.Subscription{}, &webhook.Delivery{})
+	getDB().Exec("DELETE FROM voted_tests")
+	getDB().Exec("DELETE FROM webhook_subscriptions")
+	getDB().Exec("DELETE FROM webhook_deliveries")
+	r := SetupRouter()
+
+	var received []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received = append(received, req.Header.Get(webhook.EventHeader))
+	}))
+	defer receiver.Close()
+
+	subscriptions := orm.NewORM(gormrepository.NewRepository[webhook.Subscription](getDB()))
+	deliveries := orm.NewORM(gormrepository.NewRepository[webhook.Delivery](getDB()))
+	manager := webhook.NewManager(subscriptions, deliveries)
+	manager.AllowAddress = func(netip.Addr) bool { return true }
+	NewApiRouter(*subscriptions, route.DefaultApiRoutes(), append(webhook.SubscriptionConfiguration(), configuration.RouteName("webhooks"))...).AllowRoutes(r)
+	NewApiRouter(*deliveries, webhook.DeliveryRoutes(), append(webhook.DeliveryConfiguration(), configuration.RouteName("webhook_deliveries"))...).AllowRoutes(r)
+	books := NewApiRouter(*orm.NewORM(gormrepository.NewRepository[VotedTest](getDB())), route.DefaultApiRoutes(), configuration.RouteName("webhook_test"))
+	books.SetEventBus(manager)
+	books.AllowRoutes(r)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/api/webhooks", `{"url":"`+receiver.URL+`","events":"VotedTest.create","secret":"s3cr3t"}`)
+	if w.Code != http.StatusCreated || strings.Contains(w.Body.String(), "s3cr3t") {
+		t.Fatalf("subscriptions should be created without reading their secret back, got %d %s", w.Code, w.Body.String())
+	}
+	if w = serve("POST", "/api/webhook_test", `{"id":1,"name":"first"}`); w.Code != http.StatusCreated {
+		t.Fatalf("post failed %d %s", w.Code, w.Body.String())
+	}
+	serve("PATCH", "/api/webhook_test/1", `{"name":"second"}`)
+
+	if delivered, err := manager.DeliverPending(t.Context()); delivered != 1 || err != nil {
+		t.Fatalf("the creation should be delivered, got %d %v", delivered, err)
+	}
+	if len(received) != 1 || received[0] != "VotedTest.create" {
+		t.Fatalf("unexpected deliveries %v", received)
+	}
+
+	w = serve("GET", "/api/webhook_deliveries", "")
+	var log []webhook.Delivery
+	if err := json.Unmarshal(w.Body.Bytes(), &log); err != nil || len(log) != 1 || log[0].Status != webhook.Delivered || log[0].ResponseStatus != http.StatusOK {
+		t.Fatalf("the delivery log should be served, got %s", w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), `"body"`) {
+		t.Fatalf("the posted bodies should not be exposed, got %s", w.Body.String())
+	}
+	if w = serve("GET", "/api/webhook_deliveries/"+log[0].Id.String(), ""); w.Code != http.StatusOK || strings.Contains(w.Body.String(), `"body"`) {
+		t.Fatalf("unexpected delivery %d %s", w.Code, w.Body.String())
+	}
+	if w = serve("DELETE", "/api/webhook_deliveries/"+log[0].Id.String(), ""); w.Code != http.StatusNotFound && w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("the delivery log should be read only, got %d", w.Code)
+	}
+}
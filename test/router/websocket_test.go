@@ -0,0 +1,210 @@
+package router_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/route"
+	. "github.com/philiphil/restman/router"
+	"github.com/philiphil/restman/security"
+	"golang.org/x/net/websocket"
+)
+
+// dial opens a WebSocket as the given user
+func dial(server *httptest.Server, user string) (*websocket.Conn, error) {
+	config, _ := websocket.NewConfig(strings.Replace(server.URL, "http", "ws", 1)+"/api/ws", server.URL)
+	if user != "" {
+		config.Header.Set("Authorization", user)
+	}
+	return websocket.DialConfig(config)
+}
+
+func receive(t *testing.T, conn *websocket.Conn) SocketMessage {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var message SocketMessage
+	if err := websocket.JSON.Receive(conn, &message); err != nil {
+		t.Fatalf("no message received %v", err)
+	}
+	return message
+}
+
+func TestWebSocket(t *testing.T) {
+	getDB().AutoMigrate(&PolicedTest{}, &VotedTest{})
+	getDB().Exec("DELETE FROM policed_tests")
+	getDB().Exec("DELETE FROM voted_tests")
+	r := SetupRouter()
+
+	routes := route.DefaultApiRoutes()
+	for routeType, r := range route.StreamOperations() {
+		routes[routeType] = r
+	}
+	policed := NewApiRouter(*orm.NewORM(gormrepository.NewRepository[PolicedTest](getDB())), routes, configuration.RouteName("socket_policed"))
+	policed.AddFirewall(RoleFirewall{})
+	policed.EnableStreaming(10)
+	policed.AllowRoutes(r)
+	voted := NewApiRouter(*orm.NewORM(gormrepository.NewRepository[VotedTest](getDB())), route.DefaultApiRoutes(), configuration.RouteName("socket_voted"))
+	voted.EnableStreaming(10)
+	voted.AllowRoutes(r)
+	ws := NewWebSocket(policed, voted)
+	ws.Firewalls = []security.Firewall{RoleFirewall{}}
+	r.GET("/api/ws", ws.Handle)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	write := func(method, url, body string) {
+		req, _ := http.NewRequest(method, server.URL+url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "1")
+		req.Header.Set("X-Roles", "ROLE_ADMIN")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil || resp.StatusCode >= 300 {
+			t.Fatalf("%s %s failed %v %v", method, url, resp, err)
+		}
+		resp.Body.Close()
+	}
+	write("POST", "/api/socket_policed", `{"id":2,"name":"secret","private":true}`)
+
+	if _, err := dial(server, ""); err == nil {
+		t.Fatal("the firewalls should authenticate the clients")
+	}
+	conn, err := dial(server, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, message := range []SocketMessage{
+		{Type: SocketSubscribe, Topic: "unknown"},
+		{Type: SocketSubscribe, Topic: "socket_voted"},
+		{Type: SocketSubscribe, Topic: "socket_policed", ID: "2"},
+	} {
+		websocket.JSON.Send(conn, message)
+		if reply := receive(t, conn); reply.Type != SocketError || reply.Code != http.StatusNotFound {
+			t.Fatalf("the subscription to %+v should be refused, got %+v", message, reply)
+		}
+	}
+
+	websocket.JSON.Send(conn, SocketMessage{Type: SocketSubscribe, Topic: "socket_policed"})
+	if reply := receive(t, conn); reply.Type != SocketSubscribed {
+		t.Fatalf("unexpected reply %+v", reply)
+	}
+	write("POST", "/api/socket_policed", `{"id":3,"name":"hidden","private":true}`)
+	write("POST", "/api/socket_policed", `{"id":1,"name":"public"}`)
+	first := receive(t, conn)
+	if first.Type != SocketEvent || first.Topic != "socket_policed" || first.Operation != "create" || !strings.Contains(string(first.Data), `"public"`) {
+		t.Fatalf("subscribers should only receive the changes they may read, got %+v", first)
+	}
+
+	websocket.JSON.Send(conn, SocketMessage{Type: SocketSubscribe, Topic: "socket_policed", ID: "1"})
+	receive(t, conn)
+	write("PATCH", "/api/socket_policed/1", `{"name":"renamed"}`)
+	events := map[string]bool{}
+	for range 2 {
+		e := receive(t, conn)
+		events[e.ID] = e.Type == SocketEvent && strings.Contains(string(e.Data), `"renamed"`)
+	}
+	if !events[""] || !events["1"] {
+		t.Fatalf("every subscription should receive the change, got %v", events)
+	}
+
+	websocket.JSON.Send(conn, SocketMessage{Type: SocketUnsubscribe, Topic: "socket_policed", ID: "1"})
+	websocket.JSON.Send(conn, SocketMessage{Type: SocketPing})
+	if reply := receive(t, conn); reply.Type != SocketPong {
+		t.Fatalf("pings should be answered, got %+v", reply)
+	}
+
+	resumed, err := dial(server, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Close()
+	websocket.JSON.Send(resumed, SocketMessage{Type: SocketSubscribe, Topic: "socket_policed", LastEventID: first.EventID})
+	receive(t, resumed)
+	if e := receive(t, resumed); e.Type != SocketEvent || e.Operation != "update" {
+		t.Fatalf("the missed changes should be replayed, got %+v", e)
+	}
+}
+
+func TestWebSocketBasicFirewall(t *testing.T) {
+	getDB().AutoMigrate(&PolicedTest{})
+	r := SetupRouter()
+	routes := route.DefaultApiRoutes()
+	for routeType, r := range route.StreamOperations() {
+		routes[routeType] = r
+	}
+	policed := NewApiRouter(*orm.NewORM(gormrepository.NewRepository[PolicedTest](getDB())), routes, configuration.RouteName("socket_basic"))
+	policed.AddFirewall(security.NewBasicFirewall(security.UserProviderFunc(func(c *gin.Context, username string) (security.User, error) {
+		t.Error("malformed credentials should not be looked up")
+		return nil, errors.ErrNotFound
+	}), "restman"))
+	policed.EnableStreaming(10)
+	policed.AllowRoutes(r)
+	r.GET("/api/ws", NewWebSocket(policed).Handle)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	//credentials without a password are refused before any password hashing, which -race makes slow
+	conn, err := dial(server, "Basic "+base64.StdEncoding.EncodeToString([]byte("bob")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	websocket.JSON.Send(conn, SocketMessage{Type: SocketSubscribe, Topic: "socket_basic"})
+	if reply := receive(t, conn); reply.Type != SocketError || reply.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong credentials should refuse the subscription, got %+v", reply)
+	}
+}
+
+// atomicCountingFirewall counts its calls, which happen on the goroutine of the connection
+type atomicCountingFirewall struct {
+	Calls *atomic.Int32
+}
+
+func (f atomicCountingFirewall) GetUser(c *gin.Context) (security.User, error) {
+	f.Calls.Add(1)
+	return TestFirewall{}.GetUser(c)
+}
+
+func TestWebSocketFirewallPerConnection(t *testing.T) {
+	getDB().AutoMigrate(&VotedTest{})
+	r := SetupRouter()
+	routes := route.DefaultApiRoutes()
+	for routeType, r := range route.StreamOperations() {
+		routes[routeType] = r
+	}
+	var calls atomic.Int32
+	voted := NewApiRouter(*orm.NewORM(gormrepository.NewRepository[VotedTest](getDB())), routes, configuration.RouteName("socket_counted"))
+	voted.AddFirewall(atomicCountingFirewall{Calls: &calls})
+	voted.EnableStreaming(10)
+	voted.AllowRoutes(r)
+	r.GET("/api/ws", NewWebSocket(voted).Handle)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	conn, err := dial(server, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _, id := range []string{"first", "second"} {
+		websocket.JSON.Send(conn, SocketMessage{Type: SocketSubscribe, Topic: "socket_counted", ID: id})
+		receive(t, conn)
+	}
+	websocket.JSON.Send(conn, SocketMessage{Type: SocketSubscribe, Topic: "socket_counted"})
+	if reply := receive(t, conn); reply.Type != SocketSubscribed {
+		t.Fatalf("unexpected reply %+v", reply)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("the firewalls should run once per connection, got %d calls", calls.Load())
+	}
+}
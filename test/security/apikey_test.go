@@ -0,0 +1,78 @@
+package security_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+	. "github.com/philiphil/restman/security"
+)
+
+func apiKeyContext(url string, header string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", url, nil)
+	if header != "" {
+		c.Request.Header.Set(DefaultAPIKeyHeader, header)
+	}
+	return c
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, hash, err := GenerateAPIKey("rm_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, "rm_test_") || hash != HashAPIKey(key) || strings.Contains(hash, key) {
+		t.Fatalf("unexpected key %q or hash %q", key, hash)
+	}
+	other, _, _ := GenerateAPIKey("rm_test_")
+	if other == key {
+		t.Fatal("generated keys must be random")
+	}
+}
+
+func TestAPIKeyFirewall(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	user := entity.BaseEntity{Id: 7}
+	keys := StaticAPIKeys{
+		{Hash: HashAPIKey("reader"), User: user, Scopes: []string{"books:read"}},
+		{Hash: HashAPIKey("writer"), User: user, Scopes: []string{"books:read", "books:write"}, ExpiresAt: now.Add(time.Hour)},
+		{Hash: HashAPIKey("expired"), User: user, Scopes: []string{"books:read"}, ExpiresAt: now},
+	}
+	firewall := NewAPIKeyFirewall(keys, "books:read")
+	firewall.Now = func() time.Time { return now }
+
+	c := apiKeyContext("/", "writer")
+	authenticated, err := firewall.GetUser(c)
+	if err != nil || authenticated.GetIdentifier().String() != "7" {
+		t.Fatalf("expected user 7, got %v %v", authenticated, err)
+	}
+	if key, ok := CurrentAPIKey(c); !ok || !key.HasScope("books:write") {
+		t.Fatal("the authenticated key should be kept in the context")
+	}
+
+	cases := map[string]error{"expired": errors.ErrUnauthorized, "unknown": errors.ErrUnauthorized}
+	for presented, expected := range cases {
+		if _, err := firewall.GetUser(apiKeyContext("/", presented)); err != expected {
+			t.Errorf("%s: expected %v, got %v", presented, expected, err)
+		}
+	}
+
+	firewall.Scopes = []string{"books:write"}
+	if _, err := firewall.GetUser(apiKeyContext("/", "reader")); err != errors.ErrForbidden {
+		t.Fatalf("a key lacking the scope should be forbidden, got %v", err)
+	}
+
+	_, err = firewall.GetUser(apiKeyContext("/?api_key=writer", ""))
+	if err != errors.ErrNoCredentials || err.(errors.ApiError).Blocking {
+		t.Fatalf("the query parameter is disabled by default, got %v", err)
+	}
+	firewall.QueryParameter = "api_key"
+	if _, err := firewall.GetUser(apiKeyContext("/?api_key=writer", "")); err != nil {
+		t.Fatalf("the key should be read from the query parameter, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package security_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+	. "github.com/philiphil/restman/security"
+)
+
+type passwordUser struct {
+	entity.BaseEntity
+	PasswordHash string
+}
+
+func (u passwordUser) GetPasswordHash() string {
+	return u.PasswordHash
+}
+
+func basicContext(username, password string) (*gin.Context, *httptest.ResponseRecorder) {
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	if username != "" {
+		c.Request.SetBasicAuth(username, password)
+	}
+	return c, recorder
+}
+
+func TestPasswordHashers(t *testing.T) {
+	hasher := NewArgon2idHasher()
+	for _, h := range []PasswordHasher{BcryptHasher{Cost: 4}, hasher} {
+		hash, err := h.Hash("correct horse")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !h.Verify(hash, "correct horse") || !VerifyPassword(hash, "correct horse") {
+			t.Fatalf("%T should verify its own hash", h)
+		}
+		if h.Verify(hash, "battery staple") || VerifyPassword(hash, "battery staple") {
+			t.Fatalf("%T accepted a wrong password", h)
+		}
+	}
+	if VerifyPassword("correct horse", "correct horse") {
+		t.Fatal("plain text passwords must never match")
+	}
+	if hasher.Verify("$argon2id$v=19$m=1,t=0,p=1$AAAA$AAAA", "") {
+		t.Fatal("invalid argon2id parameters must not match")
+	}
+}
+
+func TestBasicFirewall(t *testing.T) {
+	bcryptHash, _ := BcryptHasher{Cost: 4}.Hash("s3cret")
+	argonHash, _ := NewArgon2idHasher().Hash("s3cret")
+	users := map[string]passwordUser{
+		"alice": {BaseEntity: entity.BaseEntity{Id: 1}, PasswordHash: bcryptHash},
+		"bob":   {BaseEntity: entity.BaseEntity{Id: 2}, PasswordHash: argonHash},
+	}
+	firewall := NewBasicFirewall(UserProviderFunc(func(c *gin.Context, username string) (User, error) {
+		user, ok := users[username]
+		if !ok {
+			return nil, errors.ErrUnauthorized
+		}
+		return user, nil
+	}), "books")
+
+	for name, id := range map[string]string{"alice": "1", "bob": "2"} {
+		c, _ := basicContext(name, "s3cret")
+		user, err := firewall.GetUser(c)
+		if err != nil || user.GetIdentifier().String() != id {
+			t.Fatalf("%s should be authenticated, got %v %v", name, user, err)
+		}
+	}
+
+	for _, credentials := range [][2]string{{"alice", "wrong"}, {"mallory", "s3cret"}} {
+		c, recorder := basicContext(credentials[0], credentials[1])
+		if _, err := firewall.GetUser(c); err != errors.ErrUnauthorized {
+			t.Fatalf("%s should be rejected, got %v", credentials[0], err)
+		}
+		if challenge := recorder.Header().Get("WWW-Authenticate"); !strings.HasPrefix(challenge, `Basic realm="books"`) {
+			t.Fatalf("expected a Basic challenge, got %q", challenge)
+		}
+	}
+
+	c, recorder := basicContext("", "")
+	_, err := firewall.GetUser(c)
+	if err != errors.ErrNoCredentials || err.(errors.ApiError).Blocking {
+		t.Fatalf("expected a non-blocking error without credentials, got %v", err)
+	}
+	if recorder.Header().Get("WWW-Authenticate") != "" {
+		t.Fatal("no challenge should be sent while other firewalls may authenticate the request")
+	}
+}
@@ -0,0 +1,30 @@
+package security_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/orm/entity"
+	. "github.com/philiphil/restman/security"
+)
+
+func TestCurrentUser(t *testing.T) {
+	if _, ok := CurrentUser(context.Background()); ok {
+		t.Fatal("a context without user should be anonymous")
+	}
+	user := entity.BaseEntity{Id: 3}
+	if found, ok := CurrentUser(WithCurrentUser(context.Background(), user)); !ok || found.GetIdentifier().String() != "3" {
+		t.Fatalf("expected the user of the context, got %v", found)
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if _, ok := CurrentUser(c); ok {
+		t.Fatal("a gin context without user should be anonymous")
+	}
+	c.Set(CurrentUserContextKey, user)
+	if found, ok := CurrentUser(c); !ok || found.GetIdentifier().String() != "3" {
+		t.Fatalf("expected the user of the gin context, got %v", found)
+	}
+}
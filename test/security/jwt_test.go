@@ -0,0 +1,287 @@
+package security_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+	. "github.com/philiphil/restman/security"
+)
+
+func jwtUserProvider(c *gin.Context, claims JWTClaims) (User, error) {
+	return entity.BaseEntity{}.SetId(claims.Subject()), nil
+}
+
+func bearerContext(token string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	if token != "" {
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c
+}
+
+func validClaims() JWTClaims {
+	return JWTClaims{
+		"sub": "42",
+		"iss": "https://auth.example.com",
+		"aud": []string{"api"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func sign(t *testing.T, claims JWTClaims, key JWTKey) string {
+	t.Helper()
+	token, err := SignJWT(claims, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJWTFirewallAlgorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingKeys := []JWTKey{
+		{Algorithm: "HS256", Key: []byte("secret")},
+		{Algorithm: "HS512", Key: []byte("secret")},
+		{Algorithm: "RS256", Key: rsaKey},
+		{Algorithm: "PS384", Key: rsaKey},
+		{Algorithm: "EdDSA", Key: edKey},
+	}
+	firewall := NewJWTFirewall(StaticKeySet{
+		{Key: []byte("secret")},
+		{Key: &rsaKey.PublicKey},
+		{Key: edKey.Public()},
+	}, jwtUserProvider)
+
+	for _, key := range signingKeys {
+		user, err := firewall.GetUser(bearerContext(sign(t, validClaims(), key)))
+		if err != nil {
+			t.Fatalf("%s: %v", key.Algorithm, err)
+		}
+		if user.GetIdentifier().String() != "42" {
+			t.Fatalf("%s: expected user 42, got %s", key.Algorithm, user.GetIdentifier())
+		}
+	}
+
+	forged := sign(t, validClaims(), JWTKey{Algorithm: "HS256", Key: []byte("other")})
+	if _, err := firewall.GetUser(bearerContext(forged)); err != errors.ErrUnauthorized {
+		t.Fatalf("a token signed with another secret should be rejected, got %v", err)
+	}
+	firewall.Algorithms = []string{"EdDSA"}
+	if _, err := firewall.Verify(sign(t, validClaims(), signingKeys[0])); err == nil {
+		t.Fatal("HS256 should be rejected when only EdDSA is allowed")
+	}
+}
+
+func TestJWTFirewallRejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firewall := NewJWTFirewall(StaticKeySet{{Key: &rsaKey.PublicKey}}, jwtUserProvider)
+	//the public key must never be used as an HMAC secret
+	confused := sign(t, validClaims(), JWTKey{Algorithm: "HS256", Key: rsaKey.PublicKey.N.Bytes()})
+	if _, err := firewall.Verify(confused); err != ErrInvalidSignature {
+		t.Fatalf("expected an invalid signature, got %v", err)
+	}
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	payload, _ := json.Marshal(validClaims())
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+	if _, err := firewall.Verify(unsigned); err != ErrInvalidToken {
+		t.Fatalf("alg none should be rejected, got %v", err)
+	}
+}
+
+func TestJWTFirewallClaims(t *testing.T) {
+	key := JWTKey{Algorithm: "HS256", Key: []byte("secret")}
+	firewall := NewJWTFirewall(StaticKeySet{key}, jwtUserProvider)
+	firewall.Issuer = "https://auth.example.com"
+	firewall.Audience = []string{"api"}
+	firewall.Leeway = 30 * time.Second
+
+	cases := map[string]struct {
+		edit func(JWTClaims)
+		err  error
+	}{
+		"valid":            {func(JWTClaims) {}, nil},
+		"within leeway":    {func(c JWTClaims) { c["exp"] = time.Now().Add(-10 * time.Second).Unix() }, nil},
+		"expired":          {func(c JWTClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, ErrTokenExpired},
+		"no expiration":    {func(c JWTClaims) { delete(c, "exp") }, ErrInvalidClaims},
+		"not before":       {func(c JWTClaims) { c["nbf"] = time.Now().Add(time.Minute).Unix() }, ErrTokenNotYetValid},
+		"nbf within skew":  {func(c JWTClaims) { c["nbf"] = time.Now().Add(10 * time.Second).Unix() }, nil},
+		"issued in future": {func(c JWTClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() }, ErrTokenNotYetValid},
+		"wrong issuer":     {func(c JWTClaims) { c["iss"] = "https://evil.example.com" }, ErrInvalidClaims},
+		"wrong audience":   {func(c JWTClaims) { c["aud"] = "other" }, ErrInvalidClaims},
+		"audience string":  {func(c JWTClaims) { c["aud"] = "api" }, nil},
+	}
+	for name, tc := range cases {
+		claims := validClaims()
+		tc.edit(claims)
+		if _, err := firewall.Verify(sign(t, claims, key)); err != tc.err {
+			t.Errorf("%s: expected %v, got %v", name, tc.err, err)
+		}
+	}
+}
+
+func TestJWTFirewallIsChainable(t *testing.T) {
+	firewall := NewJWTFirewall(StaticKeySet{{Key: []byte("secret")}}, jwtUserProvider)
+	c := bearerContext("")
+	c.Request.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	for _, ctx := range []*gin.Context{bearerContext(""), c} {
+		_, err := firewall.GetUser(ctx)
+		if err != errors.ErrNoCredentials || err.(errors.ApiError).Blocking {
+			t.Fatalf("expected a non-blocking error without bearer token, got %v", err)
+		}
+	}
+	if _, err := firewall.GetUser(bearerContext("garbage")); err != errors.ErrUnauthorized {
+		t.Fatalf("a malformed token should be blocking, got %v", err)
+	}
+}
+
+func jwks(t *testing.T, kid string, key ed25519.PublicKey) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "OKP", "crv": "Ed25519", "kid": kid, "use": "sig", "x": base64.RawURLEncoding.EncodeToString(key)},
+		{"kty": "EC", "crv": "P-256", "kid": "ignored"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestJWKSFile(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks(t, "k1", public), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	firewall := NewJWTFirewall(NewJWKSFile(path), jwtUserProvider)
+	token := sign(t, validClaims(), JWTKey{ID: "k1", Algorithm: "EdDSA", Key: private})
+	if _, err := firewall.Verify(token); err != nil {
+		t.Fatal(err)
+	}
+	unknown := sign(t, validClaims(), JWTKey{ID: "k2", Algorithm: "EdDSA", Key: private})
+	if _, err := firewall.Verify(unknown); err != ErrUnknownKey {
+		t.Fatalf("expected an unknown key, got %v", err)
+	}
+}
+
+func TestJWKSURLRotation(t *testing.T) {
+	public1, private1, _ := ed25519.GenerateKey(rand.Reader)
+	public2, private2, _ := ed25519.GenerateKey(rand.Reader)
+	current := jwks(t, "k1", public1)
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write(current)
+	}))
+	defer server.Close()
+
+	keys := NewJWKSURL(server.URL, time.Hour)
+	keys.MinRefreshInterval = 0
+	firewall := NewJWTFirewall(keys, jwtUserProvider)
+
+	for i := 0; i < 3; i++ {
+		if _, err := firewall.Verify(sign(t, validClaims(), JWTKey{ID: "k1", Algorithm: "EdDSA", Key: private1})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("the key set should be cached, fetched %d times", fetches)
+	}
+
+	current = jwks(t, "k2", public2)
+	if _, err := firewall.Verify(sign(t, validClaims(), JWTKey{ID: "k2", Algorithm: "EdDSA", Key: private2})); err != nil {
+		t.Fatalf("an unknown kid should reload the key set, got %v", err)
+	}
+	if fetches != 2 {
+		t.Fatalf("expected a single reload, fetched %d times", fetches)
+	}
+}
+
+func TestJWKSFailedRefresh(t *testing.T) {
+	public, _, _ := ed25519.GenerateKey(rand.Reader)
+	var failure error = os.ErrNotExist
+	fetches := 0
+	keys := &JWKSKeySet{
+		Load: func() ([]byte, error) {
+			fetches++
+			if failure != nil {
+				return nil, failure
+			}
+			return jwks(t, "k1", public), nil
+		},
+		Lifetime:           time.Nanosecond,
+		MinRefreshInterval: 50 * time.Millisecond,
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := keys.Keys("k1"); err != os.ErrNotExist {
+			t.Fatalf("expected the error of the source, got %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("failed loads should wait for MinRefreshInterval, fetched %d times", fetches)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	failure = nil
+	if _, err := keys.Keys("k1"); err != nil || fetches != 2 {
+		t.Fatalf("expected a reload after MinRefreshInterval, got %v after %d fetches", err, fetches)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	failure = os.ErrNotExist
+	for i := 0; i < 3; i++ {
+		if _, err := keys.Keys("k1"); err != nil {
+			t.Fatalf("the expired keys should be served while the source fails, got %v", err)
+		}
+		if _, err := keys.Keys("unknown"); err != ErrUnknownKey {
+			t.Fatalf("expected an unknown key, got %v", err)
+		}
+	}
+	if fetches != 3 {
+		t.Fatalf("failed reloads should wait for MinRefreshInterval, fetched %d times", fetches)
+	}
+}
+
+func TestParseJWKSRSA(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	data, _ := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA", "kid": "rsa", "alg": "RS256",
+		"n": base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}}})
+	keys, err := ParseJWKS(data)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("expected one key, got %d %v", len(keys), err)
+	}
+	firewall := NewJWTFirewall(keys, jwtUserProvider)
+	if _, err := firewall.Verify(sign(t, validClaims(), JWTKey{ID: "rsa", Algorithm: "RS256", Key: rsaKey})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := firewall.Verify(sign(t, validClaims(), JWTKey{ID: "rsa", Algorithm: "PS256", Key: rsaKey})); err != ErrInvalidSignature {
+		t.Fatalf("the key is pinned to RS256, got %v", err)
+	}
+}
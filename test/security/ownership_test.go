@@ -0,0 +1,89 @@
+package security_test
+
+import (
+	"testing"
+
+	"github.com/philiphil/restman/orm/entity"
+	. "github.com/philiphil/restman/security"
+)
+
+type Document struct {
+	entity.BaseEntity
+	Author string `security:"owner"`
+}
+
+func (d Document) SetId(id any) entity.Entity {
+	d.Id = entity.CastId(id)
+	return d
+}
+
+type Folder struct {
+	entity.BaseEntity
+	Owner entity.ID
+}
+
+func (f Folder) SetId(id any) entity.Entity {
+	f.Id = entity.CastId(id)
+	return f
+}
+
+func (f Folder) GetOwnerId() entity.Identifier {
+	return f.Owner
+}
+
+func (f Folder) SetOwnerId(id entity.Identifier) entity.Entity {
+	f.Owner = entity.CastId(id)
+	return f
+}
+
+type Admin struct {
+	entity.BaseEntity
+}
+
+func (a Admin) GetRoles() []string {
+	return []string{"admin"}
+}
+
+func TestOwnership(t *testing.T) {
+	ownership := Ownership{Roles: []string{"admin"}}
+	owner := entity.BaseEntity{Id: 7}
+	other := entity.BaseEntity{Id: 8}
+
+	if ownership.FieldName(Document{}) != "Author" {
+		t.Fatal("the tagged field should hold the owner")
+	}
+	if !ownership.AllowsCreation(owner, Document{}) {
+		t.Error("entities without owner are being created")
+	}
+	if ownership.Allows(owner, Document{}) || !ownership.Allows(Admin{}, Document{}) {
+		t.Error("entities without owner are reserved to admins")
+	}
+	if ownership.Allows(nil, Document{}) || ownership.AllowsCreation(nil, Document{}) {
+		t.Error("anonymous users own nothing")
+	}
+
+	stamped, err := ownership.SetOwner(Document{}, owner)
+	if err != nil || stamped.(Document).Author != "7" {
+		t.Fatalf("unexpected owner %v %v", stamped, err)
+	}
+	if !ownership.Allows(owner, stamped) || ownership.Allows(other, stamped) {
+		t.Error("only the owner should be allowed")
+	}
+	if !ownership.Allows(Admin{}, stamped) || !HasRole(Admin{}, "admin") || HasRole(owner, "admin") {
+		t.Error("admins should bypass the ownership")
+	}
+	if value, err := ownership.OwnerValue(Document{}, owner); err != nil || value != "7" {
+		t.Errorf("unexpected owner value %v %v", value, err)
+	}
+
+	folder, err := ownership.SetOwner(Folder{}, owner)
+	if err != nil || ownership.Owner(folder) != "7" {
+		t.Fatalf("the accessors should hold the owner, got %v %v", folder, err)
+	}
+	if ownership.FieldName(Folder{}) != "" || !ownership.Supports(Folder{}) {
+		t.Error("accessors alone support the ownership without a field")
+	}
+	if (Ownership{Field: "Owner"}).FieldName(Folder{}) != "Owner" {
+		t.Error("an explicit field should be used")
+	}
+}
@@ -0,0 +1,77 @@
+package security_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	. "github.com/philiphil/restman/security"
+)
+
+func TestSessionStores(t *testing.T) {
+	cookieStore, err := NewCookieSessionStore([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := Session{Identifier: "alice", CSRFToken: "token", ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second)}
+
+	for _, store := range []SessionStore{cookieStore, NewMemorySessionStore()} {
+		value, err := store.Save(session)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := store.Load(value)
+		if err != nil || loaded.Identifier != "alice" || loaded.CSRFToken != "token" || !loaded.ExpiresAt.Equal(session.ExpiresAt) {
+			t.Fatalf("%T: expected the saved session, got %+v %v", store, loaded, err)
+		}
+		if _, err := store.Load(value[:len(value)-2] + "xx"); err == nil {
+			t.Fatalf("%T: a tampered value should be rejected", store)
+		}
+	}
+
+	other, _ := NewCookieSessionStore([]byte("other"))
+	value, _ := cookieStore.Save(session)
+	if _, err := other.Load(value); err == nil {
+		t.Fatal("a cookie encrypted with another secret should be rejected")
+	}
+	if !(Session{ExpiresAt: time.Now()}).Expired(time.Now().Add(time.Second)) {
+		t.Fatal("the session should be expired")
+	}
+}
+
+func TestRedisSessionStore(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := NewRedisSessionStore("localhost:6379", "", 0)
+	store.Client = client
+
+	session := Session{Identifier: "alice", CSRFToken: "token", ExpiresAt: time.Now().Add(time.Hour)}
+	data, _ := json.Marshal(session)
+	//the key is random and the expiration depends on the clock, only the stored session is compared
+	mock.CustomMatch(func(expected, actual []interface{}) error {
+		if len(actual) < 3 || !strings.HasPrefix(actual[1].(string), "restman:session:") || string(actual[2].([]byte)) != string(data) {
+			return fmt.Errorf("unexpected command %v", actual)
+		}
+		return nil
+	}).ExpectSet("", data, time.Hour).SetVal("OK")
+	id, err := store.Save(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectGet("restman:session:" + id).SetVal(string(data))
+	loaded, err := store.Load(id)
+	if err != nil || loaded.Identifier != "alice" {
+		t.Fatalf("expected alice, got %+v %v", loaded, err)
+	}
+	mock.ExpectGet("restman:session:unknown").RedisNil()
+	if _, err := store.Load("unknown"); err == nil {
+		t.Fatal("an unknown session should be rejected")
+	}
+	mock.ExpectDel("restman:session:" + id).SetVal(1)
+	if err := store.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+}
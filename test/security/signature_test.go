@@ -0,0 +1,160 @@
+package security_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redismock/v9"
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/router"
+	. "github.com/philiphil/restman/security"
+)
+
+func signedContext(t *testing.T, keyID string, secret string, body string) (*gin.Context, *http.Request) {
+	t.Helper()
+	req := httptest.NewRequest("PATCH", "/api/book/1?notify=true", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if err := SignRequest(req, keyID, []byte(secret)); err != nil {
+		t.Fatal(err)
+	}
+	return contextFor(req), req
+}
+
+func contextFor(req *http.Request) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = req
+	return c
+}
+
+func TestSignatureFirewall(t *testing.T) {
+	billing := entity.BaseEntity{Id: 1}
+	shipping := entity.BaseEntity{Id: 2}
+	firewall := NewSignatureFirewall(StaticSigningKeys{
+		{ID: "billing-2024", Secret: []byte("old"), User: billing, NotAfter: time.Now().Add(-time.Hour)},
+		{ID: "billing-2025", Secret: []byte("current"), User: billing},
+		{ID: "billing-2025", Secret: []byte("next"), User: billing},
+		{ID: "shipping", Secret: []byte("shipping"), User: shipping},
+	}, NewMemoryNonceStore())
+
+	//both active secrets of a rotating key are accepted
+	for _, secret := range []string{"current", "next"} {
+		c, _ := signedContext(t, "billing-2025", secret, `{"title":"signed"}`)
+		user, err := firewall.GetUser(c)
+		if err != nil || user.GetIdentifier().String() != "1" {
+			t.Fatalf("%s: expected billing, got %v %v", secret, user, err)
+		}
+		//firewalls run several times per request, the nonce must not be seen as replayed
+		if _, err := firewall.GetUser(c); err != nil {
+			t.Fatalf("%s: the verified request should stay authenticated, got %v", secret, err)
+		}
+	}
+
+	c, _ := signedContext(t, "billing-2024", "old", `{}`)
+	if _, err := firewall.GetUser(c); err != errors.ErrUnauthorized {
+		t.Fatalf("a retired key should be rejected, got %v", err)
+	}
+	c, _ = signedContext(t, "shipping", "current", `{}`)
+	if _, err := firewall.GetUser(c); err != errors.ErrUnauthorized {
+		t.Fatalf("a secret of another key should be rejected, got %v", err)
+	}
+
+	_, req := signedContext(t, "", "shipping", `{"title":"signed"}`)
+	req.Body = httptest.NewRequest("PATCH", "/", strings.NewReader(`{"title":"tampered"}`)).Body
+	if _, err := firewall.GetUser(contextFor(req)); err != errors.ErrUnauthorized {
+		t.Fatalf("a tampered body should be rejected, got %v", err)
+	}
+	_, req = signedContext(t, "", "shipping", `{}`)
+	req.URL.RawQuery = "notify=false"
+	if _, err := firewall.GetUser(contextFor(req)); err != errors.ErrUnauthorized {
+		t.Fatalf("a tampered query should be rejected, got %v", err)
+	}
+}
+
+func TestSignatureFirewallReplayAndWindow(t *testing.T) {
+	now := time.Now()
+	firewall := NewSignatureFirewall(StaticSigningKeys{{ID: "svc", Secret: []byte("secret"), User: entity.BaseEntity{Id: 1}}}, NewMemoryNonceStore())
+
+	_, req := signedContext(t, "svc", "secret", `{}`)
+	replayed := req.Clone(req.Context())
+	replayed.Body = httptest.NewRequest("PATCH", "/", strings.NewReader(`{}`)).Body
+	if _, err := firewall.GetUser(contextFor(req)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := firewall.GetUser(contextFor(replayed)); err != errors.ErrUnauthorized {
+		t.Fatalf("a replayed request should be rejected, got %v", err)
+	}
+	replayed.Header.Del(SignatureKeyHeader)
+	if _, err := firewall.GetUser(contextFor(replayed)); err != errors.ErrUnauthorized {
+		t.Fatalf("dropping the key header should not allow a replay, got %v", err)
+	}
+
+	for _, skew := range []time.Duration{-10 * time.Minute, 10 * time.Minute} {
+		timestamp := strconv.FormatInt(now.Add(skew).Unix(), 10)
+		req := httptest.NewRequest("GET", "/api/book", nil)
+		req.Header.Set(SignatureTimestampHeader, timestamp)
+		req.Header.Set(SignatureNonceHeader, "n"+timestamp)
+		req.Header.Set(SignatureKeyHeader, "svc")
+		req.Header.Set(SignatureHeader, RequestSignature([]byte("secret"), "GET", "/api/book", timestamp, "n"+timestamp, nil))
+		if _, err := firewall.GetUser(contextFor(req)); err != errors.ErrUnauthorized {
+			t.Fatalf("a timestamp %s away should be rejected, got %v", skew, err)
+		}
+	}
+
+	_, err := firewall.GetUser(contextFor(httptest.NewRequest("GET", "/", nil)))
+	if err != errors.ErrNoCredentials || err.(errors.ApiError).Blocking {
+		t.Fatalf("expected a non-blocking error without signature, got %v", err)
+	}
+}
+
+type signedBook struct {
+	Title string `json:"title"`
+}
+
+func TestSignatureFirewallKeepsBodyReadable(t *testing.T) {
+	firewall := NewSignatureFirewall(StaticSigningKeys{{Secret: []byte("secret"), User: entity.BaseEntity{Id: 1}}}, nil)
+	c, _ := signedContext(t, "", "secret", `{"title":"signed"}`)
+	if _, err := firewall.GetUser(c); err != nil {
+		t.Fatal(err)
+	}
+	var book signedBook
+	if err := router.UnserializeBodyAndMerge(c, &book); err != nil || book.Title != "signed" {
+		t.Fatalf("the body should be readable after verification, got %+v %v", book, err)
+	}
+}
+
+func TestSignatureFirewallMaxBodyBytes(t *testing.T) {
+	firewall := NewSignatureFirewall(StaticSigningKeys{{Secret: []byte("secret"), User: entity.BaseEntity{Id: 1}}}, nil)
+	firewall.MaxBodyBytes = 16
+	c, _ := signedContext(t, "", "secret", `{"title":"a signed book whose body is too large"}`)
+	if _, err := firewall.GetUser(c); err != errors.ErrTooLarge {
+		t.Fatalf("expected the body to be too large, got %v", err)
+	}
+	c, _ = signedContext(t, "", "secret", `{"title":"ok"}`)
+	if _, err := firewall.GetUser(c); err != nil {
+		t.Fatalf("bodies within the limit should be verified, got %v", err)
+	}
+}
+
+func TestRedisNonceStore(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := NewRedisNonceStore("localhost:6379", "", 0)
+	store.Client = client
+
+	mock.ExpectSetNX("restman:nonce:svc:abc", 1, time.Minute).SetVal(true)
+	mock.ExpectSetNX("restman:nonce:svc:abc", 1, time.Minute).SetVal(false)
+	if unused, err := store.Use("svc:abc", time.Minute); err != nil || !unused {
+		t.Fatalf("the first use should succeed, got %v %v", unused, err)
+	}
+	if unused, err := store.Use("svc:abc", time.Minute); err != nil || unused {
+		t.Fatalf("the second use should be refused, got %v %v", unused, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
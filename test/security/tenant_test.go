@@ -0,0 +1,54 @@
+package security_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/philiphil/restman/errors"
+	. "github.com/philiphil/restman/security"
+)
+
+func tenantContext(host string, header string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	c.Request.Host = host
+	if header != "" {
+		c.Request.Header.Set(DefaultTenantHeader, header)
+	}
+	return c
+}
+
+func TestHeaderTenantResolver(t *testing.T) {
+	tenant, err := HeaderTenantResolver{}.ResolveTenant(tenantContext("example.com", "acme"), nil)
+	if err != nil || tenant != "acme" {
+		t.Fatalf("expected acme, got %q %v", tenant, err)
+	}
+	if _, err := (HeaderTenantResolver{}).ResolveTenant(tenantContext("example.com", ""), nil); err != errors.ErrBadRequest {
+		t.Fatalf("a missing header should be rejected, got %v", err)
+	}
+}
+
+func TestSubdomainTenantResolver(t *testing.T) {
+	resolver := SubdomainTenantResolver{Domain: "example.com"}
+	tenant, err := resolver.ResolveTenant(tenantContext("Acme.example.com:8080", ""), nil)
+	if err != nil || tenant != "acme" {
+		t.Fatalf("expected acme, got %q %v", tenant, err)
+	}
+	for _, host := range []string{"example.com", "a.b.example.com", "acme.example.org"} {
+		if _, err := resolver.ResolveTenant(tenantContext(host, ""), nil); err == nil {
+			t.Errorf("%s should not resolve a tenant", host)
+		}
+	}
+}
+
+func TestTenantResolverChain(t *testing.T) {
+	chain := TenantResolverChain{SubdomainTenantResolver{Domain: "example.com"}, HeaderTenantResolver{}}
+	tenant, err := chain.ResolveTenant(tenantContext("example.com", "acme"), nil)
+	if err != nil || tenant != "acme" {
+		t.Fatalf("expected acme from the header, got %q %v", tenant, err)
+	}
+	if _, err := (UserTenantResolver{}).ResolveTenant(tenantContext("example.com", ""), nil); err != errors.ErrUnauthorized {
+		t.Fatalf("anonymous users have no tenant, got %v", err)
+	}
+}
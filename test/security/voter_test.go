@@ -0,0 +1,99 @@
+package security_test
+
+import (
+	"testing"
+
+	"github.com/philiphil/restman/orm/entity"
+	. "github.com/philiphil/restman/security"
+)
+
+type Editor struct {
+	entity.BaseEntity
+}
+
+func (e Editor) GetRoles() []string {
+	return []string{"ROLE_EDITOR"}
+}
+
+func (e Editor) GetAttributes() []string {
+	return []string{"book:publish"}
+}
+
+func constantVoter(vote Vote) Voter {
+	return VoterFunc(func(User, string, any) Vote { return vote })
+}
+
+func TestAccessDecisionManager_Strategies(t *testing.T) {
+	granted, denied, abstain := constantVoter(AccessGranted), constantVoter(AccessDenied), constantVoter(AccessAbstain)
+	cases := []struct {
+		strategy DecisionStrategy
+		voters   []Voter
+		expected bool
+	}{
+		{StrategyAffirmative, []Voter{denied, granted}, true},
+		{StrategyAffirmative, []Voter{denied, abstain}, false},
+		{StrategyConsensus, []Voter{granted, granted, denied}, true},
+		{StrategyConsensus, []Voter{granted, denied, denied}, false},
+		{StrategyConsensus, []Voter{granted, denied}, true},
+		{StrategyUnanimous, []Voter{granted, granted, abstain}, true},
+		{StrategyUnanimous, []Voter{granted, denied}, false},
+		{StrategyUnanimous, []Voter{abstain}, false},
+	}
+	for i, tc := range cases {
+		if got := NewAccessDecisionManager(tc.strategy, tc.voters...).Decide(nil, "ANY", nil); got != tc.expected {
+			t.Errorf("case %d: expected %v, got %v", i, tc.expected, got)
+		}
+	}
+
+	allowAbstain := NewAccessDecisionManager(StrategyUnanimous, abstain)
+	allowAbstain.AllowIfAllAbstain = true
+	if !allowAbstain.Decide(nil, "ANY", nil) {
+		t.Error("AllowIfAllAbstain should grant when every voter abstains")
+	}
+}
+
+func TestAccessDecisionManager_IsGranted(t *testing.T) {
+	manager := DefaultAccessDecisionManager()
+	manager.AddVoter(VoterFunc(func(user User, attribute string, subject any) Vote {
+		if attribute != "BOOK_EDIT" {
+			return AccessAbstain
+		}
+		if book, ok := subject.(string); ok && book == "draft" {
+			return AccessGranted
+		}
+		return AccessDenied
+	}))
+
+	cases := []struct {
+		user       User
+		expression string
+		subject    any
+		expected   bool
+	}{
+		{nil, "", nil, true},
+		{nil, "PUBLIC_ACCESS", nil, true},
+		{nil, "IS_AUTHENTICATED", nil, false},
+		{Editor{}, "IS_AUTHENTICATED", nil, true},
+		{Editor{}, "ROLE_ADMIN", nil, false},
+		{Editor{}, "ROLE_ADMIN or ROLE_EDITOR", nil, true},
+		{Editor{}, "ROLE_EDITOR and BOOK_EDIT", "draft", true},
+		{Editor{}, "ROLE_EDITOR && BOOK_EDIT", "published", false},
+		{Editor{}, "ROLE_ADMIN || (ROLE_EDITOR && !BOOK_EDIT)", "published", true},
+		{Editor{}, "book:publish and not ROLE_ADMIN", nil, true},
+		{entity.BaseEntity{}, "book:publish", nil, false},
+	}
+	for _, tc := range cases {
+		granted, err := manager.IsGranted(tc.user, tc.expression, tc.subject)
+		if err != nil || granted != tc.expected {
+			t.Errorf("%q on %v: expected %v, got %v %v", tc.expression, tc.subject, tc.expected, granted, err)
+		}
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	for _, expression := range []string{"ROLE_A and", "(ROLE_A", "ROLE_A ROLE_B", "ROLE_A & ROLE_B", "or ROLE_A", ")"} {
+		if _, err := ParseExpression(expression); err == nil {
+			t.Errorf("%q should not parse", expression)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/philiphil/restman/format"
 	. "github.com/philiphil/restman/serializer"
@@ -165,3 +166,42 @@ func TestSerializer_Deserialize10(t *testing.T) {
 		t.Error("Deserialization result does not match expected value")
 	}
 }
+
+// JSON serialization - fields with their own encoding are not filtered
+func TestSerializer_SerializeJSONTime(t *testing.T) {
+	type Dated struct {
+		At      time.Time  `json:"at" groups:"test"`
+		Until   *time.Time `json:"until" groups:"test"`
+		Ignored time.Time  `json:"ignored"`
+	}
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	serialized, err := NewSerializer(format.JSON).Serialize(Dated{At: at, Until: &at, Ignored: at}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(serialized, `"2026-01-02T03:04:05Z"`) != 2 || strings.Contains(serialized, "ignored") {
+		t.Fatalf("times should be serialized as such, got %s", serialized)
+	}
+}
+
+type celsius struct {
+	Degrees float64 `groups:"other"`
+}
+
+func (c celsius) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%.1fC", c.Degrees)), nil
+}
+
+// JSON serialization - structs implementing encoding.TextMarshaler keep their encoding
+func TestSerializer_SerializeJSONTextMarshaler(t *testing.T) {
+	type Reading struct {
+		Temperature celsius `json:"temperature" groups:"test"`
+	}
+	serialized, err := NewSerializer(format.JSON).Serialize(Reading{Temperature: celsius{Degrees: 21.5}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(serialized, `"temperature": "21.5C"`) {
+		t.Fatalf("the text encoding should be kept, got %s", serialized)
+	}
+}
@@ -0,0 +1,177 @@
+package webhook_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philiphil/restman/audit"
+	"github.com/philiphil/restman/event"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/orm/gormrepository"
+	"github.com/philiphil/restman/security"
+	. "github.com/philiphil/restman/webhook"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSubscription_Matches(t *testing.T) {
+	created := event.Event{Resource: "Book", Operation: audit.Create}
+	for events, expected := range map[string]bool{
+		"":                        true,
+		"*":                       true,
+		"Book":                    true,
+		"Author, Book.create":     true,
+		"Book.update,Book.delete": false,
+		"Author":                  false,
+	} {
+		if (Subscription{Events: events}).Matches(created) != expected {
+			t.Fatalf("unexpected match of %q", events)
+		}
+	}
+	if (Subscription{Disabled: true}).Matches(created) {
+		t.Fatal("disabled subscriptions should not match")
+	}
+}
+
+func TestManager(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:webhooks?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.AutoMigrate(&Subscription{}, &Delivery{})
+
+	failures := 0
+	var received []*http.Request
+	var bodies []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		received = append(received, req)
+		bodies = append(bodies, string(body))
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	subscriptions := orm.NewORM(gormrepository.NewRepository[Subscription](db))
+	subscriptions.Create(
+		&Subscription{URL: receiver.URL + "/hooks?source=books", Events: "Book.create", Secret: "secret"},
+		&Subscription{URL: receiver.URL, Events: "Author"},
+	)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	manager := NewManager(subscriptions, orm.NewORM(gormrepository.NewRepository[Delivery](db)))
+	manager.Now = func() time.Time { return now }
+	manager.MaxAttempts = 3
+	manager.AllowAddress = func(netip.Addr) bool { return true }
+
+	if err := manager.Publish(event.Event{ID: "e1", Resource: "Book", Operation: audit.Create, IDs: []string{"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if delivered, err := manager.DeliverPending(t.Context()); delivered != 1 || err != nil {
+		t.Fatalf("the matching subscription should be delivered, got %d %v", delivered, err)
+	}
+	req := received[0]
+	signature := security.RequestSignature([]byte("secret"), req.Method, req.URL.RequestURI(), req.Header.Get(security.SignatureTimestampHeader), req.Header.Get(security.SignatureNonceHeader), []byte(bodies[0]))
+	if req.Header.Get(security.SignatureHeader) != "sha256="+signature || req.Header.Get(EventHeader) != "Book.create" {
+		t.Fatalf("deliveries should be signed with the secret of their subscription, got %v", req.Header)
+	}
+	if !strings.Contains(bodies[0], `"id":"e1"`) || req.URL.RequestURI() != "/hooks?source=books" {
+		t.Fatalf("the event should be posted, got %s", bodies[0])
+	}
+
+	failures = 3
+	manager.Publish(event.Event{ID: "e2", Resource: "Book", Operation: audit.Create})
+	manager.DeliverPending(t.Context())
+	if delivered, _ := manager.DeliverPending(t.Context()); delivered != 0 || len(received) != 2 {
+		t.Fatalf("failed deliveries should wait for their backoff, got %d", len(received))
+	}
+	now = now.Add(time.Second)
+	manager.DeliverPending(t.Context())
+	now = now.Add(2 * time.Second)
+	manager.DeliverPending(t.Context())
+	if len(received) != 4 {
+		t.Fatalf("deliveries should be retried with an exponential backoff, got %d attempts", len(received))
+	}
+
+	dead, _ := manager.DeadLetters()
+	letters, err := dead.GetAll(nil)
+	if err != nil || len(letters) != 1 || letters[0].EventID != "e2" || letters[0].Attempts != 3 || letters[0].ResponseStatus != http.StatusServiceUnavailable {
+		t.Fatalf("exhausted deliveries should be dead letters, got %+v %v", letters, err)
+	}
+	if err := manager.Replay(letters[0].Id.String()); err != nil {
+		t.Fatal(err)
+	}
+	if delivered, _ := manager.DeliverPending(t.Context()); delivered != 1 {
+		t.Fatal("replayed deliveries should be delivered again")
+	}
+	if letters, _ = dead.GetAll(nil); len(letters) != 0 {
+		t.Fatalf("delivered letters should leave the dead letter list, got %+v", letters)
+	}
+
+	manager.BatchSize = 1
+	manager.Publish(event.Event{ID: "e3", Resource: "Book", Operation: audit.Create})
+	manager.Publish(event.Event{ID: "e4", Resource: "Book", Operation: audit.Create})
+	for i := 0; i < 2; i++ {
+		if delivered, _ := manager.DeliverPending(t.Context()); delivered != 1 {
+			t.Fatalf("a call should deliver up to BatchSize deliveries, got %d", delivered)
+		}
+	}
+	if delivered, _ := manager.DeliverPending(t.Context()); delivered != 0 || len(received) != 7 {
+		t.Fatalf("every delivery should be delivered once, got %d", len(received))
+	}
+}
+
+func TestManager_ForbiddenAddress(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:webhooks_forbidden?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.AutoMigrate(&Subscription{}, &Delivery{})
+
+	received := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received++
+	}))
+	defer receiver.Close()
+	//the host resolves to the loopback address, as a rebinding domain would
+	url := strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1)
+
+	subscriptions := orm.NewORM(gormrepository.NewRepository[Subscription](db))
+	subscriptions.Create(&Subscription{URL: url})
+	manager := NewManager(subscriptions, orm.NewORM(gormrepository.NewRepository[Delivery](db)))
+	manager.Publish(event.Event{ID: "e1", Resource: "Book", Operation: audit.Create})
+	if delivered, _ := manager.DeliverPending(t.Context()); delivered != 0 || received != 0 {
+		t.Fatalf("internal addresses should not be called, got %d deliveries", received)
+	}
+	pending, _ := manager.Deliveries.GetAll(nil)
+	if len(pending) != 1 || !strings.Contains(pending[0].LastError, ErrForbiddenAddress.Error()) {
+		t.Fatalf("the forbidden address should be logged, got %+v", pending)
+	}
+
+	for address, expected := range map[string]bool{
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"169.254.169.254": false,
+		"::1":             false,
+		"fe80::1":         false,
+		"::ffff:10.0.0.1": false,
+		"100.64.0.1":      false,
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+	} {
+		if PublicAddress(netip.MustParseAddr(address)) != expected {
+			t.Errorf("unexpected decision on %s", address)
+		}
+	}
+}
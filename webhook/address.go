@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is the failure of the deliveries to an address rejected by Manager.AllowAddress
+var ErrForbiddenAddress = errors.New("webhook: forbidden address")
+
+// reservedPrefixes are the ranges PublicAddress rejects on top of the loopback, private, link-local and multicast ones
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// PublicAddress reports whether addr may receive deliveries.
+// Loopback, private, link-local, multicast, unspecified and reserved addresses are rejected, so that subscriptions cannot reach internal services.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// newClient returns a client checking every connection against AllowAddress once the host is resolved,
+// redirects and DNS rebinding included. It does not use the proxy of the environment, the checked address would be the proxy's.
+func (m *Manager) newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second, Control: m.checkAddress}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
+
+// checkAddress is the Control of the dialer, it runs before every connection with the resolved address.
+func (m *Manager) checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w %s", ErrForbiddenAddress, host)
+	}
+	allow := m.AllowAddress
+	if allow == nil {
+		allow = PublicAddress
+	}
+	if !allow(addr) {
+		return fmt.Errorf("%w %s", ErrForbiddenAddress, addr)
+	}
+	return nil
+}
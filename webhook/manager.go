@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/philiphil/restman/errors"
+	"github.com/philiphil/restman/event"
+	"github.com/philiphil/restman/orm"
+	"github.com/philiphil/restman/security"
+)
+
+// Manager posts the events of the routers to the matching subscriptions, it is an event.Bus
+// Publish only logs the deliveries, they are posted by DeliverPending or Run and retried with an exponential backoff.
+// The client of NewManager only connects to the addresses allowed by AllowAddress, a Client of your own must do its own checks.
+type Manager struct {
+	Subscriptions *orm.ORM[Subscription]
+	Deliveries    *orm.ORM[Delivery]
+	Client        *http.Client
+	// AllowAddress decides which resolved addresses the deliveries may connect to, PublicAddress when nil
+	AllowAddress func(addr netip.Addr) bool
+	// MaxAttempts is the number of attempts before a delivery is Dead
+	MaxAttempts int
+	// Backoff is the delay before the first retry, it doubles at every attempt up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Interval   time.Duration
+	// BatchSize is the most deliveries a call to DeliverPending posts, the earliest due first; every due delivery when 0
+	BatchSize int
+	Now       func() time.Time
+}
+
+// NewManager creates a Manager trying every delivery up to 6 times, retried after 1s, 2s, 4s... up to an hour,
+// posting up to 100 deliveries per call to DeliverPending.
+// Its client refuses to connect to loopback, private and link-local addresses, see AllowAddress.
+//
+// Example:
+//
+//	webhooks := webhook.NewManager(subscriptions, orm.NewORM(gormrepository.NewRepository[webhook.Delivery](db)))
+//	bookRouter.SetEventBus(webhooks, "read")
+//	go webhooks.Run(ctx)
+func NewManager(subscriptions *orm.ORM[Subscription], deliveries *orm.ORM[Delivery]) *Manager {
+	m := &Manager{
+		Subscriptions: subscriptions,
+		Deliveries:    deliveries,
+		MaxAttempts:   6,
+		Backoff:       time.Second,
+		MaxBackoff:    time.Hour,
+		Interval:      time.Second,
+		BatchSize:     100,
+		Now:           time.Now,
+	}
+	m.Client = m.newClient()
+	return m
+}
+
+// Publish logs a pending delivery of the event for every matching subscription.
+func (m *Manager) Publish(e event.Event) error {
+	subscriptions, err := m.Subscriptions.GetAll(nil)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	now := m.Now().UTC()
+	var deliveries []*Delivery
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(e) {
+			continue
+		}
+		deliveries = append(deliveries, &Delivery{
+			SubscriptionID: subscription.Id.String(),
+			EventID:        e.ID,
+			Event:          e.Name(),
+			URL:            subscription.URL,
+			Status:         Pending,
+			Body:           string(body),
+			CreatedAt:      now,
+			NextAttemptAt:  now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return m.Deliveries.Create(deliveries...)
+}
+
+// DeliverPending posts up to BatchSize pending deliveries due now, the earliest due first, and returns how many succeeded.
+// Failed deliveries are rescheduled, or Dead once they exhausted their attempts.
+func (m *Manager) DeliverPending(ctx context.Context) (int, error) {
+	pending, err := m.Deliveries.Scoped(orm.Where("Status", Pending))
+	if err != nil {
+		return 0, err
+	}
+	order := map[string]string{"next_attempt_at": "asc"}
+	var deliveries []Delivery
+	if m.BatchSize > 0 {
+		deliveries, err = pending.GetPaginatedList(m.BatchSize, 0, order)
+	} else {
+		deliveries, err = pending.GetAll(order)
+	}
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	now := m.Now()
+	for i := range deliveries {
+		if deliveries[i].NextAttemptAt.After(now) {
+			//the next ones are due later
+			break
+		}
+		if m.deliver(ctx, &deliveries[i]) {
+			delivered++
+		}
+		if err := m.Deliveries.Update(&deliveries[i]); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// deliver posts the delivery and records the attempt on it.
+func (m *Manager) deliver(ctx context.Context, delivery *Delivery) bool {
+	delivery.Attempts++
+	delivery.ResponseStatus = 0
+	err := m.post(ctx, delivery)
+	now := m.Now().UTC()
+	if err == nil {
+		delivery.Status = Delivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return true
+	}
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= m.MaxAttempts {
+		delivery.Status = Dead
+		return false
+	}
+	backoff := m.Backoff << (delivery.Attempts - 1)
+	if backoff > m.MaxBackoff || backoff <= 0 {
+		backoff = m.MaxBackoff
+	}
+	delivery.NextAttemptAt = now.Add(backoff)
+	return false
+}
+
+func (m *Manager) post(ctx context.Context, delivery *Delivery) error {
+	subscription, err := m.Subscriptions.GetByID(delivery.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("subscription %s: %w", delivery.SubscriptionID, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader([]byte(delivery.Body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.Id.String())
+	if err = security.SignRequest(req, subscription.Id.String(), []byte(subscription.Secret)); err != nil {
+		return err
+	}
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	delivery.ResponseStatus = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// DeadLetters returns the deliveries which exhausted their attempts, for instance to serve them with an ApiRouter.
+func (m *Manager) DeadLetters() (*orm.ORM[Delivery], error) {
+	return m.Deliveries.Scoped(orm.Where("Status", Dead))
+}
+
+// Replay schedules a dead delivery again, with a fresh set of attempts.
+func (m *Manager) Replay(id string) error {
+	delivery, err := m.Deliveries.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if delivery.Status != Dead {
+		return errors.ErrBadRequest
+	}
+	delivery.Status = Pending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = m.Now().UTC()
+	return m.Deliveries.Update(delivery)
+}
+
+// Run delivers the pending deliveries every Interval until ctx is done.
+func (m *Manager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		_, _ = m.DeliverPending(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"strings"
+	"time"
+
+	"github.com/philiphil/restman/configuration"
+	"github.com/philiphil/restman/event"
+	"github.com/philiphil/restman/orm/entity"
+	"github.com/philiphil/restman/route"
+)
+
+// Headers of a webhook delivery, it is also signed with the headers of security.SignRequest
+const (
+	EventHeader    = "X-Webhook-Event"
+	DeliveryHeader = "X-Webhook-Delivery"
+)
+
+// Status of a Delivery
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Delivered Status = "delivered"
+	// Dead deliveries exhausted their attempts, they form the dead letter list until replayed
+	Dead Status = "dead"
+)
+
+// Subscription asks for the events of a resource to be posted to URL
+// It is a RestMan resource, see SubscriptionConfiguration.
+type Subscription struct {
+	Id  entity.ID `json:"id" orm:"primaryKey" groups:"read"`
+	URL string    `json:"url" groups:"read,write"`
+	// Events are the comma separated event names, such as "Book.create", or resources, such as "Book"; every event when empty
+	Events string `json:"events" groups:"read,write"`
+	// Secret signs the deliveries, it is never read back
+	Secret   string `json:"secret" groups:"write" sensitive:"true"`
+	Disabled bool   `json:"disabled" groups:"read,write"`
+}
+
+// SubscriptionConfiguration returns the serialization groups of an ApiRouter of subscriptions, so that secrets are write only.
+//
+// Example:
+//
+//	db.AutoMigrate(&webhook.Subscription{}, &webhook.Delivery{})
+//	subscriptions := orm.NewORM(gormrepository.NewRepository[webhook.Subscription](db))
+//	router.NewApiRouter(*subscriptions, route.DefaultApiRoutes(), webhook.SubscriptionConfiguration()...)
+func SubscriptionConfiguration() []configuration.Configuration {
+	return []configuration.Configuration{
+		configuration.InputSerializationGroups("write"),
+		configuration.OutputSerializationGroups("read"),
+	}
+}
+
+// Matches reports whether the subscription wants the event.
+func (s Subscription) Matches(e event.Event) bool {
+	if s.Disabled {
+		return false
+	}
+	if strings.TrimSpace(s.Events) == "" {
+		return true
+	}
+	for _, name := range strings.Split(s.Events, ",") {
+		name = strings.TrimSpace(name)
+		if name == "*" || name == e.Name() || name == e.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName keeps subscriptions apart from the tables of the application.
+func (s Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// GetIdentifier returns the id of the subscription.
+func (s Subscription) GetIdentifier() entity.Identifier {
+	return s.Id
+}
+
+// SetId sets the id of the subscription.
+func (s Subscription) SetId(id any) entity.Entity {
+	s.Id = entity.CastId(id)
+	return s
+}
+
+// ToEntity returns the subscription, it is its own database model.
+func (s Subscription) ToEntity() Subscription {
+	return s
+}
+
+// FromEntity returns the subscription, it is its own database model.
+func (s Subscription) FromEntity(subscription Subscription) any {
+	return subscription
+}
+
+// Delivery is an event to post to a subscription, it logs the attempts
+// The posted body is not part of the "read" group, see DeliveryConfiguration.
+type Delivery struct {
+	Id             entity.ID  `json:"id" orm:"primaryKey" groups:"read"`
+	SubscriptionID string     `json:"subscription_id" groups:"read"`
+	EventID        string     `json:"event_id" groups:"read"`
+	Event          string     `json:"event" groups:"read"`
+	URL            string     `json:"url" groups:"read"`
+	Status         Status     `json:"status" groups:"read"`
+	Attempts       int        `json:"attempts" groups:"read"`
+	ResponseStatus int        `json:"response_status,omitempty" groups:"read"`
+	LastError      string     `json:"last_error,omitempty" groups:"read"`
+	Body           string     `json:"body"`
+	CreatedAt      time.Time  `json:"created_at" groups:"read"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" bson:"next_attempt_at" groups:"read"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" groups:"read"`
+}
+
+// DeliveryRoutes returns the read only routes of an ApiRouter of deliveries, the delivery log or the dead letter list.
+func DeliveryRoutes() map[route.RouteType]route.Route {
+	return map[route.RouteType]route.Route{
+		route.Get:     route.NewRoute(route.Get),
+		route.GetList: route.NewRoute(route.GetList),
+	}
+}
+
+// DeliveryConfiguration returns the serialization groups of an ApiRouter of deliveries, so that the posted bodies are not exposed.
+//
+// Example:
+//
+//	router.NewApiRouter(*deliveries, webhook.DeliveryRoutes(), webhook.DeliveryConfiguration()...)
+func DeliveryConfiguration() []configuration.Configuration {
+	return []configuration.Configuration{
+		configuration.OutputSerializationGroups("read"),
+	}
+}
+
+// TableName keeps deliveries apart from the tables of the application.
+func (d Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// GetIdentifier returns the id of the delivery.
+func (d Delivery) GetIdentifier() entity.Identifier {
+	return d.Id
+}
+
+// SetId sets the id of the delivery.
+func (d Delivery) SetId(id any) entity.Entity {
+	d.Id = entity.CastId(id)
+	return d
+}
+
+// ToEntity returns the delivery, it is its own database model.
+func (d Delivery) ToEntity() Delivery {
+	return d
+}
+
+// FromEntity returns the delivery, it is its own database model.
+func (d Delivery) FromEntity(delivery Delivery) any {
+	return delivery
+}